@@ -68,6 +68,14 @@ func applyTaskEvent(ctx context.Context, db *pgxpool.Pool, payload []byte) error
 		return fmt.Errorf("decode event: %w", err)
 	}
 
+	if err := applyToUser(ctx, db, event); err != nil {
+		return err
+	}
+
+	return applyToAssignee(ctx, db, event)
+}
+
+func applyToUser(ctx context.Context, db *pgxpool.Pool, event service.TaskEvent) error {
 	switch event.Type {
 	case service.TaskEventCreated:
 		_, err := db.Exec(ctx, `
@@ -87,7 +95,16 @@ func applyTaskEvent(ctx context.Context, db *pgxpool.Pool, payload []byte) error
 			    updated_at = now()
 		`, event.UserID)
 		return err
-	case service.TaskEventDeleted:
+	case service.TaskEventReopened:
+		_, err := db.Exec(ctx, `
+			INSERT INTO task_analytics (user_id, tasks_created, tasks_completed, updated_at)
+			VALUES ($1, 0, 0, now())
+			ON CONFLICT (user_id) DO UPDATE
+			SET tasks_completed = GREATEST(task_analytics.tasks_completed - 1, 0),
+			    updated_at = now()
+		`, event.UserID)
+		return err
+	case service.TaskEventDeleted, service.TaskEventUpdated, service.TaskEventCommentAdded, service.TaskEventAssigned:
 		_, err := db.Exec(ctx, `
 			INSERT INTO task_analytics (user_id, tasks_created, tasks_completed, updated_at)
 			VALUES ($1, 0, 0, now())
@@ -99,3 +116,42 @@ func applyTaskEvent(ctx context.Context, db *pgxpool.Pool, payload []byte) error
 		return fmt.Errorf("unknown task event type: %s", event.Type)
 	}
 }
+
+// applyToAssignee keeps the breakdown of the user's analytics by the assignee
+// of the tasks involved.
+func applyToAssignee(ctx context.Context, db *pgxpool.Pool, event service.TaskEvent) error {
+	if event.AssigneeID == nil {
+		return nil
+	}
+
+	switch event.Type {
+	case service.TaskEventAssigned:
+		_, err := db.Exec(ctx, `
+			INSERT INTO task_assignee_analytics (user_id, assignee_id, tasks_assigned, tasks_completed, updated_at)
+			VALUES ($1, $2, 1, 0, now())
+			ON CONFLICT (user_id, assignee_id) DO UPDATE
+			SET tasks_assigned = task_assignee_analytics.tasks_assigned + 1,
+			    updated_at = now()
+		`, event.UserID, *event.AssigneeID)
+		return err
+	case service.TaskEventCompleted:
+		_, err := db.Exec(ctx, `
+			INSERT INTO task_assignee_analytics (user_id, assignee_id, tasks_assigned, tasks_completed, updated_at)
+			VALUES ($1, $2, 0, 1, now())
+			ON CONFLICT (user_id, assignee_id) DO UPDATE
+			SET tasks_completed = task_assignee_analytics.tasks_completed + 1,
+			    updated_at = now()
+		`, event.UserID, *event.AssigneeID)
+		return err
+	case service.TaskEventReopened:
+		_, err := db.Exec(ctx, `
+			UPDATE task_assignee_analytics
+			SET tasks_completed = GREATEST(tasks_completed - 1, 0),
+			    updated_at = now()
+			WHERE user_id = $1 AND assignee_id = $2
+		`, event.UserID, *event.AssigneeID)
+		return err
+	default:
+		return nil
+	}
+}
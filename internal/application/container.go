@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"taskflow/internal"
 	kafka2 "taskflow/internal/client/kafka"
 	"taskflow/internal/client/postgres"
@@ -9,8 +11,18 @@ import (
 	"taskflow/internal/http/handler"
 	"taskflow/internal/lib/logger/logger"
 	analyticsrepo "taskflow/internal/repository/analytics"
+	apikeyrepo "taskflow/internal/repository/apikey"
+	commentrepo "taskflow/internal/repository/comment"
+	invitationrepo "taskflow/internal/repository/invitation"
+	labelrepo "taskflow/internal/repository/label"
+	mfarepo "taskflow/internal/repository/mfa"
+	passwordresetrepo "taskflow/internal/repository/passwordreset"
+	projectrepo "taskflow/internal/repository/project"
+	refreshtokenrepo "taskflow/internal/repository/refreshtoken"
+	seriesrepo "taskflow/internal/repository/series"
 	"taskflow/internal/repository/task"
 	userrepo "taskflow/internal/repository/user"
+	workflowrepo "taskflow/internal/repository/workflow"
 	"taskflow/internal/service"
 	"time"
 
@@ -27,6 +39,10 @@ type Container struct {
 	Redis *redis.Client
 
 	TokenService  *service.TokenService
+	TokenDenylist service.TokenDenylist
+	LoginThrottle *service.LoginThrottle
+	Mailer        service.Mailer
+	mailFile      *os.File
 	Analytics     service.AnalyticsPublisher
 	AnalyticsRepo *analyticsrepo.Repository
 
@@ -34,13 +50,50 @@ type Container struct {
 	UserService *service.UserService
 	UserHandler *handler.UserHandler
 
-	AuthService *service.AuthService
-	AuthHandler *handler.AuthHandler
+	RefreshTokenRepo *refreshtokenrepo.RefreshTokenRepository
+	AuthService      *service.AuthService
+	AuthHandler      *handler.AuthHandler
+
+	EmailVerificationService *service.EmailVerificationService
+	EmailVerificationHandler *handler.EmailVerificationHandler
+
+	APIKeyRepo    *apikeyrepo.APIKeyRepository
+	APIKeyService *service.APIKeyService
+	APIKeyHandler *handler.APIKeyHandler
+
+	MFARepo    *mfarepo.MFARepository
+	MFAService *service.MFAService
+	MFAHandler *handler.MFAHandler
+
+	PasswordResetRepo    *passwordresetrepo.PasswordResetRepository
+	PasswordResetService *service.PasswordResetService
+	PasswordResetHandler *handler.PasswordResetHandler
 
 	TaskRepo    *task.TaskRepository
+	SeriesRepo  *seriesrepo.SeriesRepository
 	TaskService *service.TaskService
 	TaskHandler *handler.TaskHandler
 
+	ProjectRepo    *projectrepo.ProjectRepository
+	ProjectService *service.ProjectService
+	ProjectHandler *handler.ProjectHandler
+
+	InvitationRepo    *invitationrepo.InvitationRepository
+	InvitationService *service.InvitationService
+	InvitationHandler *handler.InvitationHandler
+
+	WorkflowRepo    *workflowrepo.WorkflowRepository
+	WorkflowService *service.WorkflowService
+	WorkflowHandler *handler.WorkflowHandler
+
+	LabelRepo    *labelrepo.LabelRepository
+	LabelService *service.LabelService
+	LabelHandler *handler.LabelHandler
+
+	CommentRepo    *commentrepo.CommentRepository
+	CommentService *service.CommentService
+	CommentHandler *handler.CommentHandler
+
 	TaskAnalyticsService *service.TaskAnalyticsService
 	AnalyticsHandler     *handler.AnalyticsHandler
 }
@@ -66,24 +119,108 @@ func (c *Container) Init(ctx context.Context) (*Container, error) {
 	}
 	c.Redis = rdb
 
-	c.TokenService = service.NewTokenService(
-		c.Config.AuthConfig.JWTSecret,
-		time.Duration(c.Config.AuthConfig.JWTExpirationHours)*time.Hour,
-	)
+	if err := c.initTokenService(); err != nil {
+		return c, err
+	}
+	if err := c.initMailer(); err != nil {
+		return c, err
+	}
+	c.TokenDenylist = service.NewRedisTokenDenylist(c.Redis)
+	c.initLoginThrottle()
 	c.Analytics = service.NewKafkaAnalyticsPublisher(kafka2.NewWriter(c.Config.KafkaConfig))
 
 	c.UserRepo = userrepo.NewUserRepository(c.Pool)
 	c.UserService = service.NewUserService(c.UserRepo)
 	c.UserHandler = handler.NewUserHandler(c.UserService)
-	c.AuthService = service.NewAuthService(c.UserService, c.TokenService)
+	c.EmailVerificationService = service.NewEmailVerificationService(
+		c.UserService,
+		c.TokenService,
+		c.Mailer,
+		c.Config.EmailVerificationConfig.VerifyURL,
+		time.Duration(c.Config.EmailVerificationConfig.TTLHours)*time.Hour,
+		c.LoginThrottle,
+	)
+	c.EmailVerificationHandler = handler.NewEmailVerificationHandler(c.EmailVerificationService)
+	c.APIKeyRepo = apikeyrepo.NewAPIKeyRepository(c.Pool)
+	c.APIKeyService = service.NewAPIKeyService(c.APIKeyRepo, c.TokenService)
+	c.APIKeyHandler = handler.NewAPIKeyHandler(c.APIKeyService)
+	c.MFARepo = mfarepo.NewMFARepository(c.Pool)
+	c.MFAService = service.NewMFAService(
+		c.MFARepo,
+		c.UserService,
+		c.TokenService,
+		c.Config.MFAConfig.Issuer,
+		time.Duration(c.Config.MFAConfig.PendingTTLMinutes)*time.Minute,
+		c.LoginThrottle,
+	)
+	c.RefreshTokenRepo = refreshtokenrepo.NewRefreshTokenRepository(c.Pool)
+	c.AuthService = service.NewAuthService(
+		c.UserService,
+		c.TokenService,
+		c.RefreshTokenRepo,
+		time.Duration(c.Config.AuthConfig.RefreshTokenTTLHours)*time.Hour,
+		c.TokenDenylist,
+		c.EmailVerificationService,
+		c.MFAService,
+		c.LoginThrottle,
+	)
 	c.AuthHandler = handler.NewAuthHandler(c.AuthService, c.UserService)
+	c.MFAHandler = handler.NewMFAHandler(c.MFAService)
+	c.PasswordResetRepo = passwordresetrepo.NewPasswordResetRepository(c.Pool)
+	c.PasswordResetService = service.NewPasswordResetService(
+		c.PasswordResetRepo,
+		c.AuthService,
+		c.Mailer,
+		c.Config.PasswordResetConfig.ResetURL,
+		time.Duration(c.Config.PasswordResetConfig.TTLMinutes)*time.Minute,
+		c.Logger,
+	)
+	c.PasswordResetHandler = handler.NewPasswordResetHandler(c.PasswordResetService)
 	if err := c.UserService.EnsureDevUser(ctx); err != nil {
 		return c, err
 	}
 
 	c.TaskRepo = task.NewTaskRepository(c.Pool)
-	c.TaskService = service.NewTaskService(c.TaskRepo, service.NewRedisTaskCache(c.Redis))
+	c.LabelRepo = labelrepo.NewLabelRepository(c.Pool)
+	c.SeriesRepo = seriesrepo.NewSeriesRepository(c.Pool)
+	c.WorkflowRepo = workflowrepo.NewWorkflowRepository(c.Pool)
+	c.ProjectRepo = projectrepo.NewProjectRepository(c.Pool)
+	c.TaskService = service.NewTaskService(
+		c.TaskRepo,
+		service.NewRedisTaskCache(c.Redis),
+		c.LabelRepo,
+		c.SeriesRepo,
+		c.WorkflowRepo,
+		c.ProjectRepo,
+		c.Analytics,
+		c.Logger,
+	)
 	c.TaskHandler = handler.NewTaskHandler(c.TaskService, c.Analytics)
+	c.ProjectService = service.NewProjectService(
+		c.ProjectRepo,
+		c.UserRepo,
+		c.TaskService,
+		c.Config.EmailVerificationConfig.Required,
+	)
+	c.ProjectHandler = handler.NewProjectHandler(c.ProjectService)
+	c.InvitationRepo = invitationrepo.NewInvitationRepository(c.Pool)
+	c.InvitationService = service.NewInvitationService(
+		c.InvitationRepo,
+		c.ProjectService,
+		c.AuthService,
+		c.TokenService,
+		c.Mailer,
+		c.Config.InvitationConfig.AcceptURL,
+		time.Duration(c.Config.InvitationConfig.TTLHours)*time.Hour,
+	)
+	c.InvitationHandler = handler.NewInvitationHandler(c.InvitationService)
+	c.WorkflowService = service.NewWorkflowService(c.WorkflowRepo)
+	c.WorkflowHandler = handler.NewWorkflowHandler(c.WorkflowService)
+	c.LabelService = service.NewLabelService(c.LabelRepo, c.TaskService)
+	c.LabelHandler = handler.NewLabelHandler(c.LabelService)
+	c.CommentRepo = commentrepo.NewCommentRepository(c.Pool)
+	c.CommentService = service.NewCommentService(c.CommentRepo, c.TaskService)
+	c.CommentHandler = handler.NewCommentHandler(c.CommentService, c.Analytics)
 	c.AnalyticsRepo = analyticsrepo.NewRepository(c.Pool)
 	c.TaskAnalyticsService = service.NewTaskAnalyticsService(c.AnalyticsRepo)
 	c.AnalyticsHandler = handler.NewAnalyticsHandler(c.TaskAnalyticsService)
@@ -91,7 +228,92 @@ func (c *Container) Init(ctx context.Context) (*Container, error) {
 	return c, nil
 }
 
+// initMailer picks the mailer configured by MAIL_DRIVER. The log mailer
+// appends to MAIL_FILE when it is set.
+func (c *Container) initTokenService() error {
+	cfg := c.Config.AuthConfig
+
+	var (
+		keyring *service.Keyring
+		err     error
+	)
+	if cfg.KeyringFile != "" {
+		keyring, err = service.LoadKeyring(cfg.KeyringFile)
+	} else {
+		var key service.SigningKey
+		key, err = service.NewHMACKey(service.DefaultKeyID, cfg.JWTSecret)
+		if err == nil {
+			keyring, err = service.NewKeyring(service.DefaultKeyID, key)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	c.TokenService = service.NewKeyringTokenService(
+		keyring,
+		time.Duration(cfg.AccessTokenTTLMinutes)*time.Minute,
+		service.TokenValidation{
+			Issuer:   cfg.Issuer,
+			Audience: cfg.Audience,
+			Leeway:   time.Duration(cfg.LeewaySeconds) * time.Second,
+		},
+	)
+
+	return nil
+}
+
+func (c *Container) initMailer() error {
+	cfg := c.Config.MailConfig
+	switch cfg.Driver {
+	case "smtp":
+		c.Mailer = service.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case "log", "":
+		if cfg.File == "" {
+			c.Mailer = service.NewLogMailer(os.Stdout)
+			return nil
+		}
+
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open mail file: %w", err)
+		}
+		c.mailFile = file
+		c.Mailer = service.NewLogMailer(file)
+	default:
+		return fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+
+	return nil
+}
+
+func (c *Container) initLoginThrottle() {
+	cfg := c.Config.LoginThrottleConfig
+	baseDelay := time.Duration(cfg.BaseDelaySeconds) * time.Second
+	lockout := time.Duration(cfg.LockoutMinutes) * time.Minute
+
+	c.LoginThrottle = service.NewLoginThrottle(
+		service.NewRedisLoginAttempts(c.Redis),
+		service.LoginBackoff{
+			FreeAttempts:    cfg.FreeAttempts,
+			LockoutAttempts: cfg.LockoutAttempts,
+			BaseDelay:       baseDelay,
+			Lockout:         lockout,
+		},
+		service.LoginBackoff{
+			FreeAttempts:    cfg.IPFreeAttempts,
+			LockoutAttempts: cfg.IPLockoutAttempts,
+			BaseDelay:       baseDelay,
+			Lockout:         lockout,
+		},
+		c.Config.MFAConfig.MaxAttempts,
+	)
+}
+
 func (c *Container) Close() error {
+	if c.PasswordResetService != nil {
+		c.PasswordResetService.Wait()
+	}
 	if c.Pool != nil {
 		c.Pool.Close()
 	}
@@ -102,5 +324,8 @@ func (c *Container) Close() error {
 	if c.Analytics != nil {
 		_ = c.Analytics.Close()
 	}
+	if c.mailFile != nil {
+		_ = c.mailFile.Close()
+	}
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"taskflow/internal"
+	"taskflow/internal/domain"
 	middleware2 "taskflow/internal/http/middleware"
 	"taskflow/internal/lib/logger/logger"
 
@@ -30,6 +31,10 @@ func NewPublicServer(cfg internal.AppConfig, logger logger.Logger) *PublicServer
 
 func (s *PublicServer) Configure(container *Container) (*PublicServer, error) {
 	e := echo.New()
+	// Login throttling counts failures per client IP. X-Forwarded-For is
+	// trusted only from proxies on loopback and private networks, so clients
+	// cannot pick the address they are counted under.
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestID())
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
@@ -53,6 +58,7 @@ func (s *PublicServer) Configure(container *Container) (*PublicServer, error) {
 	}))
 	s.echo = e
 	s.echo.GET("/swagger/*", echoSwagger.WrapHandler)
+	s.echo.GET("/.well-known/jwks.json", container.AuthHandler.JWKS)
 	s.v1(container)
 
 	return s, nil
@@ -82,25 +88,141 @@ func (s *PublicServer) v1(container *Container) {
 
 	registerHandler := container.AuthHandler.Register
 	loginHandler := container.AuthHandler.Login
+	refreshHandler := container.AuthHandler.Refresh
+	logoutHandler := container.AuthHandler.Logout
+	logoutAllHandler := container.AuthHandler.LogoutAll
+	forgotPasswordHandler := container.PasswordResetHandler.Forgot
+	resetPasswordHandler := container.PasswordResetHandler.Reset
+	verifyEmailHandler := container.EmailVerificationHandler.Verify
+	resendVerificationHandler := container.EmailVerificationHandler.Resend
+	verifyMFAHandler := container.AuthHandler.VerifyMFA
+	enrollMFAHandler := container.MFAHandler.Enroll
+	confirmMFAHandler := container.MFAHandler.Confirm
+	disableMFAHandler := container.MFAHandler.Disable
+	createAPIKeyHandler := container.APIKeyHandler.Create
+	listAPIKeysHandler := container.APIKeyHandler.List
+	revokeAPIKeyHandler := container.APIKeyHandler.Revoke
 	createUserHandler := container.UserHandler.Create
 	meHandler := container.UserHandler.Me
 	listTaskHandler := container.TaskHandler.List
 	createTaskHandler := container.TaskHandler.Create
 	getTaskHandler := container.TaskHandler.Get
+	updateTaskHandler := container.TaskHandler.Update
 	changeTaskStatusHandler := container.TaskHandler.ChangeStatus
+	reopenTaskHandler := container.TaskHandler.Reopen
+	restoreTaskHandler := container.TaskHandler.Restore
 	deleteTaskHandler := container.TaskHandler.Delete
+	attachTaskLabelHandler := container.TaskHandler.AttachLabel
+	detachTaskLabelHandler := container.TaskHandler.DetachLabel
+	listSubtasksHandler := container.TaskHandler.ListSubtasks
+	moveTaskHandler := container.TaskHandler.Move
+	assignTaskHandler := container.TaskHandler.Assign
+	unassignTaskHandler := container.TaskHandler.Unassign
+	addTaskBlockerHandler := container.TaskHandler.AddBlocker
+	removeTaskBlockerHandler := container.TaskHandler.RemoveBlocker
+	getTaskSeriesHandler := container.TaskHandler.GetSeries
+	updateTaskSeriesHandler := container.TaskHandler.UpdateSeries
+	stopTaskSeriesHandler := container.TaskHandler.StopSeries
+	addChecklistItemHandler := container.TaskHandler.AddChecklistItem
+	updateChecklistItemHandler := container.TaskHandler.UpdateChecklistItem
+	removeChecklistItemHandler := container.TaskHandler.RemoveChecklistItem
+	reorderChecklistHandler := container.TaskHandler.ReorderChecklist
+	listCommentsHandler := container.CommentHandler.List
+	createCommentHandler := container.CommentHandler.Create
+	updateCommentHandler := container.CommentHandler.Update
+	deleteCommentHandler := container.CommentHandler.Delete
+	listLabelHandler := container.LabelHandler.List
+	createLabelHandler := container.LabelHandler.Create
+	updateLabelHandler := container.LabelHandler.Update
+	deleteLabelHandler := container.LabelHandler.Delete
 	getAnalyticsHandler := container.AnalyticsHandler.Get
+	createProjectHandler := container.ProjectHandler.Create
+	listProjectsHandler := container.ProjectHandler.List
+	getProjectHandler := container.ProjectHandler.Get
+	updateProjectHandler := container.ProjectHandler.Update
+	deleteProjectHandler := container.ProjectHandler.Delete
+	listProjectMembersHandler := container.ProjectHandler.ListMembers
+	setProjectMemberHandler := container.ProjectHandler.SetMember
+	removeProjectMemberHandler := container.ProjectHandler.RemoveMember
+	createInvitationHandler := container.InvitationHandler.Create
+	acceptInvitationHandler := container.InvitationHandler.Accept
+	declineInvitationHandler := container.InvitationHandler.Decline
+	getWorkflowHandler := container.WorkflowHandler.Get
+	replaceWorkflowHandler := container.WorkflowHandler.Replace
+	resetWorkflowHandler := container.WorkflowHandler.Reset
 
-	authM := middleware2.AuthMiddleware(container.TokenService)
+	authM := middleware2.AuthMiddleware(container.TokenService, container.TokenDenylist, container.APIKeyService)
+	projectM := middleware2.ProjectRoleMiddleware(container.ProjectService)
+	accountS := middleware2.RequireScopes(domain.ScopeAccount)
+	usersReadS := middleware2.RequireScopes(domain.ScopeUsersRead)
+	tasksReadS := middleware2.RequireScopes(domain.ScopeTasksRead)
+	tasksWriteS := middleware2.RequireScopes(domain.ScopeTasksWrite)
+	projectsReadS := middleware2.RequireScopes(domain.ScopeProjectsRead)
+	projectsWriteS := middleware2.RequireScopes(domain.ScopeProjectsWrite)
+	analyticsReadS := middleware2.RequireScopes(domain.ScopeAnalyticsRead)
 
 	v1.POST("/auth/register", registerHandler)
 	v1.POST("/auth/login", loginHandler)
+	v1.POST("/auth/refresh", refreshHandler)
+	v1.POST("/auth/logout", logoutHandler, authM, accountS)
+	v1.POST("/auth/logout/all", logoutAllHandler, authM, accountS)
+	v1.POST("/auth/password/forgot", forgotPasswordHandler)
+	v1.POST("/auth/password/reset", resetPasswordHandler)
+	v1.POST("/auth/email/verify", verifyEmailHandler)
+	v1.POST("/auth/email/verify/resend", resendVerificationHandler, authM, accountS)
+	v1.POST("/auth/mfa/verify", verifyMFAHandler)
+	v1.POST("/auth/mfa/enroll", enrollMFAHandler, authM, accountS)
+	v1.POST("/auth/mfa/confirm", confirmMFAHandler, authM, accountS)
+	v1.POST("/auth/mfa/disable", disableMFAHandler, authM, accountS)
+	v1.POST("/api-keys", createAPIKeyHandler, authM, accountS)
+	v1.GET("/api-keys", listAPIKeysHandler, authM, accountS)
+	v1.DELETE("/api-keys/:id", revokeAPIKeyHandler, authM, accountS)
 	v1.POST("/users", createUserHandler)
-	v1.GET("/me", meHandler, authM)
-	v1.GET("/tasks", listTaskHandler, authM)
-	v1.POST("/task", createTaskHandler, authM)
-	v1.GET("/task/:id", getTaskHandler, authM)
-	v1.PATCH("/tasks/:id/status", changeTaskStatusHandler, authM)
-	v1.DELETE("/tasks/:id", deleteTaskHandler, authM)
-	v1.GET("/analytics", getAnalyticsHandler, authM)
+	v1.GET("/me", meHandler, authM, usersReadS)
+	v1.GET("/tasks", listTaskHandler, authM, tasksReadS)
+	v1.POST("/task", createTaskHandler, authM, tasksWriteS)
+	v1.GET("/task/:id", getTaskHandler, authM, tasksReadS)
+	v1.PATCH("/tasks/:id", updateTaskHandler, authM, tasksWriteS)
+	v1.PATCH("/tasks/:id/status", changeTaskStatusHandler, authM, tasksWriteS)
+	v1.POST("/tasks/:id/reopen", reopenTaskHandler, authM, tasksWriteS)
+	v1.POST("/tasks/:id/restore", restoreTaskHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id", deleteTaskHandler, authM, tasksWriteS)
+	v1.PUT("/tasks/:id/labels/:labelId", attachTaskLabelHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/labels/:labelId", detachTaskLabelHandler, authM, tasksWriteS)
+	v1.GET("/tasks/:id/subtasks", listSubtasksHandler, authM, tasksReadS)
+	v1.PUT("/tasks/:id/parent", moveTaskHandler, authM, tasksWriteS)
+	v1.PUT("/tasks/:id/assignee", assignTaskHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/assignee", unassignTaskHandler, authM, tasksWriteS)
+	v1.PUT("/tasks/:id/blockers/:blockerId", addTaskBlockerHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/blockers/:blockerId", removeTaskBlockerHandler, authM, tasksWriteS)
+	v1.GET("/tasks/:id/series", getTaskSeriesHandler, authM, tasksReadS)
+	v1.PATCH("/tasks/:id/series", updateTaskSeriesHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/series", stopTaskSeriesHandler, authM, tasksWriteS)
+	v1.POST("/tasks/:id/checklist", addChecklistItemHandler, authM, tasksWriteS)
+	v1.PUT("/tasks/:id/checklist/order", reorderChecklistHandler, authM, tasksWriteS)
+	v1.PATCH("/tasks/:id/checklist/:itemId", updateChecklistItemHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/checklist/:itemId", removeChecklistItemHandler, authM, tasksWriteS)
+	v1.GET("/tasks/:id/comments", listCommentsHandler, authM, tasksReadS)
+	v1.POST("/tasks/:id/comments", createCommentHandler, authM, tasksWriteS)
+	v1.PATCH("/tasks/:id/comments/:commentId", updateCommentHandler, authM, tasksWriteS)
+	v1.DELETE("/tasks/:id/comments/:commentId", deleteCommentHandler, authM, tasksWriteS)
+	v1.GET("/labels", listLabelHandler, authM, tasksReadS)
+	v1.POST("/labels", createLabelHandler, authM, tasksWriteS)
+	v1.PATCH("/labels/:id", updateLabelHandler, authM, tasksWriteS)
+	v1.DELETE("/labels/:id", deleteLabelHandler, authM, tasksWriteS)
+	v1.GET("/analytics", getAnalyticsHandler, authM, analyticsReadS)
+	v1.POST("/projects", createProjectHandler, authM, projectsWriteS)
+	v1.GET("/projects", listProjectsHandler, authM, projectsReadS)
+	v1.GET("/projects/:id", getProjectHandler, authM, projectsReadS, projectM)
+	v1.PATCH("/projects/:id", updateProjectHandler, authM, projectsWriteS, projectM)
+	v1.DELETE("/projects/:id", deleteProjectHandler, authM, projectsWriteS, projectM)
+	v1.GET("/projects/:id/members", listProjectMembersHandler, authM, projectsReadS, projectM)
+	v1.PUT("/projects/:id/members/:userId", setProjectMemberHandler, authM, projectsWriteS, projectM)
+	v1.DELETE("/projects/:id/members/:userId", removeProjectMemberHandler, authM, projectsWriteS, projectM)
+	v1.POST("/projects/:id/invitations", createInvitationHandler, authM, projectsWriteS, projectM)
+	v1.POST("/invitations/accept", acceptInvitationHandler)
+	v1.POST("/invitations/decline", declineInvitationHandler)
+	v1.GET("/workflow", getWorkflowHandler, authM, tasksReadS)
+	v1.PUT("/workflow", replaceWorkflowHandler, authM, tasksWriteS)
+	v1.DELETE("/workflow", resetWorkflowHandler, authM, tasksWriteS)
 }
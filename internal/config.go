@@ -6,11 +6,17 @@ import (
 )
 
 type AppConfig struct {
-	PublicServerConfig PublicServerConfig
-	PostgresConfig     PostgresConfig
-	RedisConfig        RedisConfig
-	KafkaConfig        KafkaConfig
-	AuthConfig         AuthConfig
+	PublicServerConfig      PublicServerConfig
+	PostgresConfig          PostgresConfig
+	RedisConfig             RedisConfig
+	KafkaConfig             KafkaConfig
+	AuthConfig              AuthConfig
+	MailConfig              MailConfig
+	InvitationConfig        InvitationConfig
+	PasswordResetConfig     PasswordResetConfig
+	EmailVerificationConfig EmailVerificationConfig
+	MFAConfig               MFAConfig
+	LoginThrottleConfig     LoginThrottleConfig
 }
 
 type PublicServerConfig struct {
@@ -41,9 +47,70 @@ type KafkaConfig struct {
 	AnalyticsGroupID string `env:"KAFKA_ANALYTICS_GROUP_ID" envDefault:"taskflow-analytics"`
 }
 
+// AuthConfig signs tokens with JWTSecret (HS256) unless KeyringFile names a
+// JSON keyring, which replaces it. Issuer and Audience should differ between
+// environments so that their tokens are not interchangeable.
 type AuthConfig struct {
-	JWTSecret          string `env:"JWT_SECRET" envDefault:"taskflow-dev-secret"`
-	JWTExpirationHours int    `env:"JWT_EXPIRATION_HOURS" envDefault:"24"`
+	JWTSecret             string `env:"JWT_SECRET" envDefault:"taskflow-dev-secret"`
+	KeyringFile           string `env:"JWT_KEYRING_FILE"`
+	Issuer                string `env:"JWT_ISSUER" envDefault:"taskflow"`
+	Audience              string `env:"JWT_AUDIENCE" envDefault:"taskflow-api"`
+	LeewaySeconds         int    `env:"JWT_LEEWAY_SECONDS" envDefault:"30"`
+	AccessTokenTTLMinutes int    `env:"ACCESS_TOKEN_TTL_MINUTES" envDefault:"15"`
+	RefreshTokenTTLHours  int    `env:"REFRESH_TOKEN_TTL_HOURS" envDefault:"720"`
+}
+
+// MailConfig selects how mail leaves the service: "smtp" delivers it, "log"
+// writes it to MailFile or, without one, to stdout.
+type MailConfig struct {
+	Driver       string `env:"MAIL_DRIVER" envDefault:"log"`
+	From         string `env:"MAIL_FROM" envDefault:"taskflow@localhost"`
+	File         string `env:"MAIL_FILE"`
+	SMTPHost     string `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort     int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+}
+
+type InvitationConfig struct {
+	AcceptURL string `env:"INVITATION_ACCEPT_URL" envDefault:"http://localhost:1323/invitations/accept"`
+	TTLHours  int    `env:"INVITATION_TTL_HOURS" envDefault:"72"`
+}
+
+type PasswordResetConfig struct {
+	ResetURL   string `env:"PASSWORD_RESET_URL" envDefault:"http://localhost:1323/password/reset"`
+	TTLMinutes int    `env:"PASSWORD_RESET_TTL_MINUTES" envDefault:"60"`
+}
+
+// EmailVerificationConfig controls the mail sent on registration. With
+// Required set, users who have not verified their email are kept out of shared
+// projects.
+type EmailVerificationConfig struct {
+	VerifyURL string `env:"EMAIL_VERIFICATION_URL" envDefault:"http://localhost:1323/verify-email"`
+	TTLHours  int    `env:"EMAIL_VERIFICATION_TTL_HOURS" envDefault:"48"`
+	Required  bool   `env:"EMAIL_VERIFICATION_REQUIRED" envDefault:"false"`
+}
+
+// MFAConfig controls two-factor authentication. Issuer is the name
+// authenticator apps show next to the account; MaxAttempts is how many codes
+// one MFA token may be tried with before the login has to start over.
+type MFAConfig struct {
+	Issuer            string `env:"MFA_ISSUER" envDefault:"Taskflow"`
+	PendingTTLMinutes int    `env:"MFA_PENDING_TTL_MINUTES" envDefault:"5"`
+	MaxAttempts       int    `env:"MFA_MAX_ATTEMPTS" envDefault:"5"`
+}
+
+// LoginThrottleConfig limits failed logins. After the free attempts each
+// failure doubles the wait from BaseDelaySeconds; at the lockout attempts the
+// email or IP is locked out for LockoutMinutes. The IP limits are higher
+// because users behind one NAT or proxy share an address.
+type LoginThrottleConfig struct {
+	FreeAttempts      int `env:"LOGIN_FREE_ATTEMPTS" envDefault:"5"`
+	LockoutAttempts   int `env:"LOGIN_LOCKOUT_ATTEMPTS" envDefault:"10"`
+	IPFreeAttempts    int `env:"LOGIN_IP_FREE_ATTEMPTS" envDefault:"20"`
+	IPLockoutAttempts int `env:"LOGIN_IP_LOCKOUT_ATTEMPTS" envDefault:"100"`
+	BaseDelaySeconds  int `env:"LOGIN_BASE_DELAY_SECONDS" envDefault:"1"`
+	LockoutMinutes    int `env:"LOGIN_LOCKOUT_MINUTES" envDefault:"15"`
 }
 
 func NewConfig[T any](files ...string) (T, error) {
@@ -1,9 +1,22 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type TaskAnalytics struct {
 	TasksCreated   int64
 	TasksCompleted int64
-	UpdatedAt      time.Time
+	// Assignees breaks the user's assignments and completions down by the
+	// assignee of the tasks involved.
+	Assignees []AssigneeAnalytics
+	UpdatedAt time.Time
+}
+
+type AssigneeAnalytics struct {
+	AssigneeID     uuid.UUID
+	TasksAssigned  int64
+	TasksCompleted int64
 }
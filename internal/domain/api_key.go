@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const maxAPIKeyNameLength = 100
+
+var (
+	ErrEmptyAPIKeyName   = errors.New("api key name is empty")
+	ErrAPIKeyNameTooLong = errors.New("api key name is too long")
+	ErrNoAPIKeyScopes    = errors.New("api key needs at least one scope")
+	ErrInvalidAPIKeyTTL  = errors.New("api key expiry must be in the future")
+	ErrAPIKeyExpired     = errors.New("api key expired")
+	ErrAPIKeyRevoked     = errors.New("api key revoked")
+)
+
+// APIKey is a long-lived credential a user creates for scripts and CI. The
+// key is shown once; only its prefix, which identifies it, and the hash of
+// its secret are kept.
+type APIKey struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	SecretHash string
+	Scopes     []Scope
+	// ExpiresAt is nil for a key that does not expire.
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+func NewAPIKey(
+	userID uuid.UUID,
+	name, prefix, secretHash string,
+	scopes []Scope,
+	expiresAt *time.Time,
+) (APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIKey{}, ErrEmptyAPIKeyName
+	}
+	if utf8.RuneCountInString(name) > maxAPIKeyNameLength {
+		return APIKey{}, ErrAPIKeyNameTooLong
+	}
+	if len(scopes) == 0 {
+		return APIKey{}, ErrNoAPIKeyScopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return APIKey{}, ErrScopeNotGrantable
+		}
+	}
+
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return APIKey{}, ErrInvalidAPIKeyTTL
+	}
+
+	return APIKey{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  now,
+	}, nil
+}
+
+// CheckUsable reports why the key cannot authenticate a request at now.
+func (k APIKey) CheckUsable(now time.Time) error {
+	if k.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrAPIKeyExpired
+	}
+
+	return nil
+}
+
+func (k APIKey) HasScope(scope Scope) bool {
+	return HasScopes(k.Scopes, scope)
+}
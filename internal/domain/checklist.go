@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxChecklistItems          = 100
+	maxChecklistItemTextLength = 500
+)
+
+var (
+	ErrEmptyChecklistItem    = errors.New("checklist item text is empty")
+	ErrChecklistItemTooLong  = errors.New("checklist item text is too long")
+	ErrChecklistFull         = errors.New("checklist has too many items")
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrInvalidChecklistOrder = errors.New("order must list every checklist item exactly once")
+)
+
+// ChecklistItem is a step of a task that is too small to be a subtask.
+// Position is the zero-based place of the item in the checklist.
+type ChecklistItem struct {
+	ID       uuid.UUID
+	Text     string
+	Checked  bool
+	Position int
+}
+
+// ChecklistProgress counts the checklist items of a task. It is available on
+// every read, including lists, where the items themselves are not loaded.
+type ChecklistProgress struct {
+	Total int
+	Done  int
+}
+
+// AddChecklistItem appends an unchecked item to the checklist.
+func (t *Task) AddChecklistItem(text string) (ChecklistItem, error) {
+	if len(t.Checklist) >= MaxChecklistItems {
+		return ChecklistItem{}, ErrChecklistFull
+	}
+
+	text, err := normalizeChecklistText(text)
+	if err != nil {
+		return ChecklistItem{}, err
+	}
+
+	item := ChecklistItem{
+		ID:       uuid.New(),
+		Text:     text,
+		Position: len(t.Checklist),
+	}
+	t.Checklist = append(t.Checklist, item)
+	return item, nil
+}
+
+func (t *Task) CheckChecklistItem(id uuid.UUID, checked bool) error {
+	i, err := t.checklistIndex(id)
+	if err != nil {
+		return err
+	}
+	t.Checklist[i].Checked = checked
+	return nil
+}
+
+func (t *Task) ChangeChecklistItemText(id uuid.UUID, text string) error {
+	i, err := t.checklistIndex(id)
+	if err != nil {
+		return err
+	}
+
+	text, err = normalizeChecklistText(text)
+	if err != nil {
+		return err
+	}
+	t.Checklist[i].Text = text
+	return nil
+}
+
+func (t *Task) RemoveChecklistItem(id uuid.UUID) error {
+	i, err := t.checklistIndex(id)
+	if err != nil {
+		return err
+	}
+
+	t.Checklist = append(t.Checklist[:i:i], t.Checklist[i+1:]...)
+	t.renumberChecklist()
+	return nil
+}
+
+// ReorderChecklist puts the items in the order of ids, which must name every
+// item exactly once.
+func (t *Task) ReorderChecklist(ids []uuid.UUID) error {
+	if len(ids) != len(t.Checklist) {
+		return ErrInvalidChecklistOrder
+	}
+
+	byID := make(map[uuid.UUID]ChecklistItem, len(t.Checklist))
+	for _, item := range t.Checklist {
+		byID[item.ID] = item
+	}
+
+	reordered := make([]ChecklistItem, 0, len(ids))
+	for _, id := range ids {
+		item, ok := byID[id]
+		if !ok {
+			return ErrInvalidChecklistOrder
+		}
+		delete(byID, id)
+		reordered = append(reordered, item)
+	}
+
+	t.Checklist = reordered
+	t.renumberChecklist()
+	return nil
+}
+
+func (t *Task) checklistIndex(id uuid.UUID) (int, error) {
+	for i, item := range t.Checklist {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return 0, ErrChecklistItemNotFound
+}
+
+func (t *Task) renumberChecklist() {
+	for i := range t.Checklist {
+		t.Checklist[i].Position = i
+	}
+}
+
+func normalizeChecklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmptyChecklistItem
+	}
+	if utf8.RuneCountInString(text) > maxChecklistItemTextLength {
+		return "", ErrChecklistItemTooLong
+	}
+	return text, nil
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxCommentBodyLength is the limit on a comment's markdown source, in
+// characters.
+const MaxCommentBodyLength = 10000
+
+var (
+	ErrEmptyComment       = errors.New("comment body is empty")
+	ErrCommentTooLong     = errors.New("comment body is too long")
+	ErrInvalidCommentTask = errors.New("invalid comment task")
+	ErrInvalidAuthor      = errors.New("invalid comment author")
+	ErrNotCommentAuthor   = errors.New("only the author can change a comment")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+)
+
+// Comment is a markdown message in the discussion thread of a task.
+type Comment struct {
+	ID       uuid.UUID
+	TaskID   uuid.UUID
+	AuthorID uuid.UUID
+	// Body is markdown source; it is stored and returned as written.
+	Body      string
+	CreatedAt time.Time
+	// EditedAt is set by the most recent edit.
+	EditedAt *time.Time
+}
+
+func NewComment(taskID, authorID uuid.UUID, body string) (Comment, error) {
+	if taskID == uuid.Nil {
+		return Comment{}, ErrInvalidCommentTask
+	}
+	if authorID == uuid.Nil {
+		return Comment{}, ErrInvalidAuthor
+	}
+
+	body, err := normalizeCommentBody(body)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	return Comment{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		AuthorID:  authorID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func NewCommentFromStorage(
+	id, taskID, authorID uuid.UUID,
+	body string,
+	createdAt time.Time,
+	editedAt *time.Time,
+) (Comment, error) {
+	if taskID == uuid.Nil {
+		return Comment{}, ErrInvalidCommentTask
+	}
+	if authorID == uuid.Nil {
+		return Comment{}, ErrInvalidAuthor
+	}
+
+	return Comment{
+		ID:        id,
+		TaskID:    taskID,
+		AuthorID:  authorID,
+		Body:      body,
+		CreatedAt: createdAt,
+		EditedAt:  editedAt,
+	}, nil
+}
+
+// Edit replaces the body on behalf of userID, who must be the author.
+func (c *Comment) Edit(userID uuid.UUID, body string, now time.Time) error {
+	if err := c.CheckAuthor(userID); err != nil {
+		return err
+	}
+
+	body, err := normalizeCommentBody(body)
+	if err != nil {
+		return err
+	}
+
+	c.Body = body
+	n := now.UTC()
+	c.EditedAt = &n
+	return nil
+}
+
+func (c Comment) CheckAuthor(userID uuid.UUID) error {
+	if c.AuthorID != userID {
+		return ErrNotCommentAuthor
+	}
+	return nil
+}
+
+// normalizeCommentBody drops surrounding blank lines and trailing whitespace.
+// Leading spaces are kept, as markdown gives them meaning.
+func normalizeCommentBody(body string) (string, error) {
+	body = strings.TrimRight(body, " \t\r\n")
+	body = strings.TrimLeft(body, "\r\n")
+	if strings.TrimSpace(body) == "" {
+		return "", ErrEmptyComment
+	}
+	if utf8.RuneCountInString(body) > MaxCommentBodyLength {
+		return "", ErrCommentTooLong
+	}
+	return body, nil
+}
+
+// CommentCursor points at the last comment of a page; the next page starts
+// right after it.
+type CommentCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorAfter returns the cursor that continues after c.
+func (c Comment) CursorAfter() CommentCursor {
+	return CommentCursor{CreatedAt: c.CreatedAt, ID: c.ID}
+}
+
+// String encodes the cursor as an opaque token.
+func (c CommentCursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseCommentCursor(token string) (CommentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+
+	at, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+
+	commentID, err := uuid.Parse(id)
+	if err != nil {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+
+	return CommentCursor{CreatedAt: createdAt, ID: commentID}, nil
+}
+
+// CommentFilter selects a page of a task's thread, oldest first.
+type CommentFilter struct {
+	TaskID uuid.UUID
+	After  *CommentCursor
+	Limit  int
+}
+
+func (f *CommentFilter) Normalize() {
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvitationExpired  = errors.New("invitation expired")
+	ErrInvitationAnswered = errors.New("invitation has already been answered")
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+)
+
+// Invitation asks the owner of an email address to join a project with the
+// given role. It may be answered once, before it expires.
+type Invitation struct {
+	ID         uuid.UUID
+	ProjectID  uuid.UUID
+	InviterID  uuid.UUID
+	Email      string
+	Role       ProjectRole
+	Status     InvitationStatus
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	AnsweredAt *time.Time
+}
+
+// Invite builds an invitation to the project. Personal projects are not
+// shared, and nobody is invited as an owner.
+func (p Project) Invite(inviterID uuid.UUID, email string, role ProjectRole, ttl time.Duration) (Invitation, error) {
+	if p.Personal {
+		return Invitation{}, ErrPersonalProject
+	}
+	if !role.IsValid() || role == RoleOwner {
+		return Invitation{}, ErrInvalidProjectRole
+	}
+
+	email = NormalizeUserEmail(email)
+	if err := ValidateUserEmail(email); err != nil {
+		return Invitation{}, err
+	}
+
+	now := time.Now()
+
+	return Invitation{
+		ID:        uuid.New(),
+		ProjectID: p.ID,
+		InviterID: inviterID,
+		Email:     email,
+		Role:      role,
+		Status:    InvitationPending,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+// Accept answers the invitation positively.
+func (i *Invitation) Accept(now time.Time) error {
+	return i.answer(InvitationAccepted, now)
+}
+
+// Decline answers the invitation negatively.
+func (i *Invitation) Decline(now time.Time) error {
+	return i.answer(InvitationDeclined, now)
+}
+
+func (i *Invitation) answer(status InvitationStatus, now time.Time) error {
+	if i.Status != InvitationPending {
+		return ErrInvitationAnswered
+	}
+	if !now.Before(i.ExpiresAt) {
+		return ErrInvitationExpired
+	}
+
+	i.Status = status
+	i.AnsweredAt = &now
+	return nil
+}
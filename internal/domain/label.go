@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLabelColor  = "#808080"
+	maxLabelNameLength = 50
+)
+
+var (
+	ErrEmptyLabelName    = errors.New("label name is empty")
+	ErrLabelNameTooLong  = errors.New("label name is too long")
+	ErrInvalidLabelColor = errors.New("label color must be a #rrggbb hex value")
+	ErrInvalidLabelOwner = errors.New("invalid label owner")
+	ErrLabelNameTaken    = errors.New("label with this name already exists")
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Label is a per-user tag that groups tasks across statuses.
+type Label struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt time.Time
+}
+
+func NewLabel(userID uuid.UUID, name, color string) (Label, error) {
+	if userID == uuid.Nil {
+		return Label{}, ErrInvalidLabelOwner
+	}
+
+	if strings.TrimSpace(color) == "" {
+		color = DefaultLabelColor
+	}
+
+	l := Label{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := l.Rename(name); err != nil {
+		return Label{}, err
+	}
+	if err := l.ChangeColor(color); err != nil {
+		return Label{}, err
+	}
+
+	return l, nil
+}
+
+func NewLabelFromStorage(id, userID uuid.UUID, name, color string, createdAt time.Time) (Label, error) {
+	if userID == uuid.Nil {
+		return Label{}, ErrInvalidLabelOwner
+	}
+
+	l := Label{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: createdAt,
+	}
+	if err := l.Rename(name); err != nil {
+		return Label{}, err
+	}
+	if err := l.ChangeColor(color); err != nil {
+		return Label{}, err
+	}
+
+	return l, nil
+}
+
+func (l *Label) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrEmptyLabelName
+	}
+	if utf8.RuneCountInString(name) > maxLabelNameLength {
+		return ErrLabelNameTooLong
+	}
+	l.Name = name
+	return nil
+}
+
+func (l *Label) ChangeColor(color string) error {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !labelColorPattern.MatchString(color) {
+		return ErrInvalidLabelColor
+	}
+	l.Color = color
+	return nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPasswordResetExpired = errors.New("password reset expired")
+	ErrPasswordResetUsed    = errors.New("password reset has already been used")
+)
+
+// PasswordReset lets a user who forgot their password set a new one. The
+// token mailed for it works once and only until it expires; only its hash is
+// kept.
+type PasswordReset struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+func NewPasswordReset(userID uuid.UUID, tokenHash string, ttl time.Duration) PasswordReset {
+	now := time.Now()
+
+	return PasswordReset{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// Use marks the reset used, which it may be only once and before it expires.
+func (r *PasswordReset) Use(now time.Time) error {
+	if r.UsedAt != nil {
+		return ErrPasswordResetUsed
+	}
+	if !now.Before(r.ExpiresAt) {
+		return ErrPasswordResetExpired
+	}
+
+	r.UsedAt = &now
+	return nil
+}
@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxProjectNameLength = 100
+	personalProjectName  = "Personal"
+)
+
+var (
+	ErrEmptyProjectName     = errors.New("project name is empty")
+	ErrProjectNameTooLong   = errors.New("project name is too long")
+	ErrInvalidProjectName   = errors.New("project name contains control characters")
+	ErrInvalidProjectOwner  = errors.New("invalid project owner")
+	ErrPersonalProject      = errors.New("personal projects cannot be shared or deleted")
+	ErrOwnerCannotLeave     = errors.New("project owner cannot leave the project")
+	ErrAlreadyProjectMember = errors.New("user is already a project member")
+	ErrProjectMismatch      = errors.New("tasks belong to different projects")
+	ErrInvalidProjectRole   = errors.New("invalid project role")
+	ErrOwnerRoleFixed       = errors.New("project owner role cannot be changed")
+)
+
+// ProjectRole is what a member may do in a project. Every project has exactly
+// one owner; the other roles are granted by the owner or an admin.
+type ProjectRole string
+
+const (
+	RoleOwner  ProjectRole = "owner"
+	RoleAdmin  ProjectRole = "admin"
+	RoleEditor ProjectRole = "editor"
+	RoleViewer ProjectRole = "viewer"
+)
+
+// Permission is an action on a project checked against the member's role.
+type Permission string
+
+const (
+	PermissionViewTasks     Permission = "tasks:view"
+	PermissionEditTasks     Permission = "tasks:edit"
+	PermissionComment       Permission = "tasks:comment"
+	PermissionManageMembers Permission = "members:manage"
+	PermissionUpdateProject Permission = "project:update"
+	PermissionDeleteProject Permission = "project:delete"
+)
+
+var rolePermissions = map[ProjectRole][]Permission{
+	RoleOwner: {
+		PermissionViewTasks,
+		PermissionEditTasks,
+		PermissionComment,
+		PermissionManageMembers,
+		PermissionUpdateProject,
+		PermissionDeleteProject,
+	},
+	RoleAdmin: {
+		PermissionViewTasks,
+		PermissionEditTasks,
+		PermissionComment,
+		PermissionManageMembers,
+		PermissionUpdateProject,
+	},
+	RoleEditor: {
+		PermissionViewTasks,
+		PermissionEditTasks,
+		PermissionComment,
+	},
+	RoleViewer: {
+		PermissionViewTasks,
+	},
+}
+
+// roleRanks orders the roles; a member manages only members ranked below.
+var roleRanks = map[ProjectRole]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+func (r ProjectRole) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+func (r ProjectRole) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAssign reports whether a member with this role may move another member
+// from current to next. current is empty for a user joining the project. The
+// owner role is never assigned this way.
+func (r ProjectRole) CanAssign(current, next ProjectRole) bool {
+	if !r.Can(PermissionManageMembers) || next == RoleOwner {
+		return false
+	}
+	if current != "" && roleRanks[current] >= roleRanks[r] {
+		return false
+	}
+
+	return roleRanks[next] < roleRanks[r]
+}
+
+// CanRemove reports whether a member with this role may remove a member with
+// the other one.
+func (r ProjectRole) CanRemove(other ProjectRole) bool {
+	return r.Can(PermissionManageMembers) && roleRanks[other] < roleRanks[r]
+}
+
+// Project is a workspace whose tasks are shared by all of its members. Every
+// user has a personal project that holds the tasks created without one.
+type Project struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID
+	Name      string
+	Personal  bool
+	CreatedAt time.Time
+}
+
+// ProjectMember grants a user access to the tasks of a project.
+type ProjectMember struct {
+	ProjectID uuid.UUID
+	UserID    uuid.UUID
+	Role      ProjectRole
+	JoinedAt  time.Time
+}
+
+func NewProject(ownerID uuid.UUID, name string) (Project, error) {
+	if ownerID == uuid.Nil {
+		return Project{}, ErrInvalidProjectOwner
+	}
+
+	p := Project{
+		ID:        uuid.New(),
+		OwnerID:   ownerID,
+		CreatedAt: time.Now(),
+	}
+	if err := p.Rename(name); err != nil {
+		return Project{}, err
+	}
+
+	return p, nil
+}
+
+// NewPersonalProject builds the project that holds the tasks a user creates
+// without choosing one.
+func NewPersonalProject(ownerID uuid.UUID) (Project, error) {
+	p, err := NewProject(ownerID, personalProjectName)
+	if err != nil {
+		return Project{}, err
+	}
+	p.Personal = true
+
+	return p, nil
+}
+
+func NewProjectFromStorage(id, ownerID uuid.UUID, name string, personal bool, createdAt time.Time) (Project, error) {
+	if ownerID == uuid.Nil {
+		return Project{}, ErrInvalidProjectOwner
+	}
+
+	return Project{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      name,
+		Personal:  personal,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (p *Project) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrEmptyProjectName
+	}
+	if utf8.RuneCountInString(name) > maxProjectNameLength {
+		return ErrProjectNameTooLong
+	}
+	// The name goes into invitation mail subjects, where a line break would
+	// start a new header.
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return ErrInvalidProjectName
+	}
+
+	p.Name = name
+	return nil
+}
+
+func (p Project) IsOwner(userID uuid.UUID) bool {
+	return p.OwnerID == userID
+}
+
+// OwnerMember is the membership created together with the project.
+func (p Project) OwnerMember() ProjectMember {
+	return ProjectMember{ProjectID: p.ID, UserID: p.OwnerID, Role: RoleOwner, JoinedAt: p.CreatedAt}
+}
+
+// AddMember grants a user access to the project with the given role.
+func (p Project) AddMember(userID uuid.UUID, role ProjectRole) (ProjectMember, error) {
+	if p.Personal {
+		return ProjectMember{}, ErrPersonalProject
+	}
+	if p.IsOwner(userID) {
+		return ProjectMember{}, ErrAlreadyProjectMember
+	}
+	if !role.IsValid() || role == RoleOwner {
+		return ProjectMember{}, ErrInvalidProjectRole
+	}
+
+	return ProjectMember{ProjectID: p.ID, UserID: userID, Role: role, JoinedAt: time.Now()}, nil
+}
+
+// ChangeRole gives the member another role. The owner keeps theirs.
+func (m *ProjectMember) ChangeRole(role ProjectRole) error {
+	if !role.IsValid() || role == RoleOwner {
+		return ErrInvalidProjectRole
+	}
+	if m.Role == RoleOwner {
+		return ErrOwnerRoleFixed
+	}
+
+	m.Role = role
+	return nil
+}
+
+// CheckRemoveMember reports whether the user may leave the project.
+func (p Project) CheckRemoveMember(userID uuid.UUID) error {
+	if p.IsOwner(userID) {
+		return ErrOwnerCannotLeave
+	}
+	return nil
+}
+
+// CheckDelete reports whether the project may be deleted together with its
+// tasks.
+func (p Project) CheckDelete() error {
+	if p.Personal {
+		return ErrPersonalProject
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+// maxRecurrencePeriods bounds how far occurrences are enumerated, so a rule
+// that never matches (BYMONTHDAY=31 with INTERVAL=2 from February, say) ends.
+const maxRecurrencePeriods = 10000
+
+const untilLayout = "20060102T150405Z"
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RFC 5545 RRULE that tasks support: FREQ,
+// INTERVAL, COUNT, UNTIL, BYDAY (weekly rules, plain weekdays only) and
+// BYMONTHDAY (monthly rules).
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	// Count limits the number of occurrences, the first one included; zero
+	// means no limit.
+	Count      int
+	Until      *time.Time
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+// ParseRecurrence parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,TH".
+// A leading "RRULE:" is accepted.
+func ParseRecurrence(value string) (Recurrence, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.ToUpper(value), "RRULE:")
+	if value == "" {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+
+	r := Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return Recurrence{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+		if seen[key] {
+			return Recurrence{}, fmt.Errorf("%w: duplicate %s", ErrInvalidRecurrence, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(val)
+		case "INTERVAL":
+			r.Interval, err = parsePositive(val)
+		case "COUNT":
+			r.Count, err = parsePositive(val)
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(val)
+			r.Until = &until
+		case "BYDAY":
+			r.ByDay, err = parseWeekdays(val)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseMonthDays(val)
+		case "WKST":
+			// Weeks always start on Monday; other week starts are not supported.
+			if val != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			err = errors.New("unsupported part")
+		}
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("%w: %s: %v", ErrInvalidRecurrence, key, err)
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return Recurrence{}, err
+	}
+
+	return r, nil
+}
+
+func (r Recurrence) validate() error {
+	switch r.Freq {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return fmt.Errorf("%w: FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY", ErrInvalidRecurrence)
+	}
+
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRecurrence)
+	}
+	if len(r.ByDay) > 0 && r.Freq != FrequencyWeekly {
+		return fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRecurrence)
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != FrequencyMonthly {
+		return fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRecurrence)
+	}
+
+	return nil
+}
+
+// String renders the rule back as a canonical RRULE value.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			for code, weekday := range weekdayCodes {
+				if weekday == day {
+					codes = append(codes, code)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of a series starting at start that falls
+// strictly after the given time. ok is false once COUNT or UNTIL ends the
+// series.
+func (r Recurrence) Next(start, after time.Time) (next time.Time, ok bool) {
+	n := 0
+	for occurrence := range r.occurrences(start) {
+		n++
+		if r.Count > 0 && n > r.Count {
+			return time.Time{}, false
+		}
+		if r.Until != nil && occurrence.After(*r.Until) {
+			return time.Time{}, false
+		}
+		if occurrence.After(after) {
+			return occurrence, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// occurrences yields start, which RFC 5545 always counts as the first
+// occurrence, followed by every later date the rule produces, in order.
+func (r Recurrence) occurrences(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if !yield(start) {
+			return
+		}
+
+		for period := 0; period < maxRecurrencePeriods; period++ {
+			for _, candidate := range r.period(start, period*max(r.Interval, 1)) {
+				if !candidate.After(start) {
+					continue
+				}
+				if !yield(candidate) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// period returns the dates the rule produces offset periods after the one
+// containing start, in chronological order.
+func (r Recurrence) period(start time.Time, offset int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	switch r.Freq {
+	case FrequencyDaily:
+		return []time.Time{start.AddDate(0, 0, offset)}
+
+	case FrequencyWeekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*offset)}
+		}
+
+		monday := start.AddDate(0, 0, -daysSinceMonday(start.Weekday())+7*offset)
+		dates := make([]time.Time, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			dates = append(dates, monday.AddDate(0, 0, daysSinceMonday(day)))
+		}
+		slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+		return dates
+
+	case FrequencyMonthly:
+		first := at(start.Year(), start.Month()+time.Month(offset), 1)
+		length := first.AddDate(0, 1, -1).Day()
+
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{start.Day()}
+		}
+
+		dates := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			if day < 0 {
+				day = length + day + 1
+			}
+			// Months too short for the day are skipped, as RFC 5545 requires.
+			if day < 1 || day > length {
+				continue
+			}
+			dates = append(dates, at(first.Year(), first.Month(), day))
+		}
+		slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+		return dates
+
+	case FrequencyYearly:
+		date := at(start.Year()+offset, start.Month(), start.Day())
+		// February 29 only recurs in leap years.
+		if date.Day() != start.Day() {
+			return nil
+		}
+		return []time.Time{date}
+	}
+
+	return nil
+}
+
+func daysSinceMonday(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return t, nil
+	}
+
+	// A date-only UNTIL includes the whole day.
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, errors.New("must be a UTC date-time or a date")
+	}
+
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		day, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("unsupported weekday %q", code)
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
+
+func parseMonthDays(value string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("invalid month day %q", item)
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
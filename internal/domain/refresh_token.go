@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// RefreshToken renews a user's access token. Each refresh replaces the token
+// with a successor in the same family; presenting a replaced token again means
+// it leaked, and the whole family is revoked. Only a hash of the token itself
+// is kept.
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken starts a new token family, as on login.
+func NewRefreshToken(userID uuid.UUID, tokenHash string, ttl time.Duration) RefreshToken {
+	now := time.Now()
+
+	return RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  uuid.New(),
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// Check reports whether the token may still be exchanged for new tokens.
+func (t RefreshToken) Check(now time.Time) error {
+	if t.RevokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+	if t.RotatedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	if !now.Before(t.ExpiresAt) {
+		return ErrRefreshTokenExpired
+	}
+	return nil
+}
+
+// Rotate marks the token used and returns its successor in the same family.
+func (t *RefreshToken) Rotate(tokenHash string, ttl time.Duration, now time.Time) (RefreshToken, error) {
+	if err := t.Check(now); err != nil {
+		return RefreshToken{}, err
+	}
+
+	t.RotatedAt = &now
+
+	return RefreshToken{
+		ID:        uuid.New(),
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
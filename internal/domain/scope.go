@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownScope      = errors.New("unknown scope")
+	ErrScopeNotGrantable = errors.New("scope cannot be granted to an api key")
+)
+
+// Scope limits what a credential may do. Reading and writing are separate
+// scopes, so that a credential can be made read-only.
+type Scope string
+
+const (
+	// ScopeTasksRead and ScopeTasksWrite cover tasks together with what
+	// organizes them: labels, comments, checklists, series and the workflow.
+	ScopeTasksRead     Scope = "tasks:read"
+	ScopeTasksWrite    Scope = "tasks:write"
+	ScopeProjectsRead  Scope = "projects:read"
+	ScopeProjectsWrite Scope = "projects:write"
+	ScopeAnalyticsRead Scope = "analytics:read"
+	ScopeUsersRead     Scope = "users:read"
+	// ScopeAccount manages the account itself: sessions, two-factor
+	// authentication and API keys. Only interactive logins hold it.
+	ScopeAccount Scope = "account"
+)
+
+// AllScopes lists every scope. Interactive logins are granted all of them.
+var AllScopes = []Scope{
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeAnalyticsRead,
+	ScopeUsersRead,
+	ScopeAccount,
+}
+
+// APIKeyScopes lists the scopes an API key may be granted.
+var APIKeyScopes = []Scope{
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeAnalyticsRead,
+	ScopeUsersRead,
+}
+
+// ParseScopes validates scope names and returns them without duplicates.
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(strings.TrimSpace(name))
+		if !slices.Contains(AllScopes, scope) {
+			return nil, ErrUnknownScope
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// HasScopes reports whether the granted scopes include every required one.
+func HasScopes(granted []Scope, required ...Scope) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+
+	return true
+}
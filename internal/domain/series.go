@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRecurrenceWithoutDueDate = errors.New("recurring task needs a due date")
+	ErrSeriesStopped            = errors.New("recurrence has been stopped")
+)
+
+// TaskSeries ties the occurrences of a recurring task together. Its title,
+// description and priority are the template for occurrences created next.
+type TaskSeries struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Rule        Recurrence
+	Title       string
+	Description string
+	Priority    Priority
+	// StartsAt is the due date the rule is anchored to.
+	StartsAt time.Time
+	// LastDueAt is the due date of the newest occurrence.
+	LastDueAt time.Time
+	StoppedAt *time.Time
+	CreatedAt time.Time
+}
+
+// NewTaskSeries starts a series with task as its first occurrence.
+func NewTaskSeries(task Task, rule Recurrence) (TaskSeries, error) {
+	if task.DueAt == nil {
+		return TaskSeries{}, ErrRecurrenceWithoutDueDate
+	}
+
+	return TaskSeries{
+		ID:          uuid.New(),
+		UserID:      task.UserID,
+		Rule:        rule,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		StartsAt:    *task.DueAt,
+		LastDueAt:   *task.DueAt,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (s TaskSeries) IsActive() bool {
+	return s.StoppedAt == nil
+}
+
+func (s *TaskSeries) Rename(title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return ErrEmptyTitle
+	}
+	s.Title = title
+	return nil
+}
+
+func (s *TaskSeries) ChangeDescription(desc string) {
+	s.Description = strings.TrimSpace(desc)
+}
+
+func (s *TaskSeries) ChangePriority(priority Priority) error {
+	priority = Priority(strings.ToLower(strings.TrimSpace(string(priority))))
+	if !priority.IsValid() {
+		return ErrInvalidPriority
+	}
+	s.Priority = priority
+	return nil
+}
+
+// ChangeRule replaces the rule. The new rule is anchored to the newest
+// occurrence, so COUNT counts from there on.
+func (s *TaskSeries) ChangeRule(rule Recurrence) error {
+	if !s.IsActive() {
+		return ErrSeriesStopped
+	}
+	s.Rule = rule
+	s.StartsAt = s.LastDueAt
+	return nil
+}
+
+// Stop ends the series; occurrences already created are kept.
+func (s *TaskSeries) Stop(now time.Time) {
+	if !s.IsActive() {
+		return
+	}
+	n := now.UTC()
+	s.StoppedAt = &n
+}
+
+// NextOccurrence builds the occurrence that follows completed. ok is false when
+// the series is stopped or exhausted, or when completed is not the newest
+// occurrence, which has its successor already.
+func (s *TaskSeries) NextOccurrence(completed Task) (next Task, ok bool, err error) {
+	if !s.IsActive() || completed.DueAt == nil || !completed.DueAt.Equal(s.LastDueAt) {
+		return Task{}, false, nil
+	}
+
+	dueAt, ok := s.Rule.Next(s.StartsAt, s.LastDueAt)
+	if !ok {
+		return Task{}, false, nil
+	}
+
+	next, err = NewTask(s.UserID, s.Title, s.Description)
+	if err != nil {
+		return Task{}, false, err
+	}
+	if err := next.ChangePriority(s.Priority); err != nil {
+		return Task{}, false, err
+	}
+	next.Reschedule(&dueAt)
+	next.ProjectID = completed.ProjectID
+	next.AssigneeID = completed.AssigneeID
+	seriesID := s.ID
+	next.SeriesID = &seriesID
+
+	s.LastDueAt = *next.DueAt
+	return next, true, nil
+}
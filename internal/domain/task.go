@@ -8,6 +8,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// Status is the key of a workflow status. The four constants below are the
+// statuses of the default workflow.
 type Status string
 
 const (
@@ -17,30 +19,118 @@ const (
 	StatusCancelled  Status = "canceled"
 )
 
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
 var (
+	ErrInvalidPriority   = errors.New("invalid priority")
 	ErrInvalidStatus     = errors.New("invalid status")
 	ErrInvalidTransition = errors.New("invalid status transition")
 	ErrEmptyTitle        = errors.New("title is empty")
 	ErrInvalidTaskOwner  = errors.New("invalid task owner")
+	ErrVersionConflict   = errors.New("task was modified concurrently")
+	ErrOpenSubtasks      = errors.New("task has open subtasks")
+	ErrTaskCycle         = errors.New("task cannot be moved under itself or its subtasks")
+	ErrTaskTooDeep       = errors.New("task hierarchy is too deep")
+	ErrParentClosed      = errors.New("open subtasks cannot be added to a closed task")
+	ErrBlocked           = errors.New("task is blocked by open tasks")
+	ErrDependencyCycle   = errors.New("dependency would create a cycle")
+	ErrNotDone           = errors.New("only done tasks can be reopened")
+	ErrNotCanceled       = errors.New("only canceled tasks can be restored")
+	ErrAssigneeNotMember = errors.New("assignee is not a member of the task's project")
 )
 
+// MaxTaskDepth is the number of levels a task hierarchy may have, the root
+// task included.
+const MaxTaskDepth = 5
+
+// SubtaskProgress summarizes the direct subtasks of a task. Canceled subtasks
+// are left out of both counters.
+type SubtaskProgress struct {
+	Total int
+	Done  int
+}
+
+func (p SubtaskProgress) Open() int {
+	return p.Total - p.Done
+}
+
+// Blocker is a task that has to be finished before the task depending on it
+// can start.
+type Blocker struct {
+	ID       uuid.UUID
+	Status   Status
+	Category StatusCategory
+}
+
+func (b Blocker) IsOpen() bool {
+	return !b.Category.IsClosed()
+}
+
 type Task struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
+	ID uuid.UUID
+	// UserID is the creator of the task. Access is granted by membership in
+	// the project.
+	UserID    uuid.UUID
+	ProjectID uuid.UUID
+	// AssigneeID is the project member working on the task, if any.
+	AssigneeID  *uuid.UUID
 	Title       string
 	Description string
 	Status      Status
+	Category    StatusCategory
+	Priority    Priority
+	DueAt       *time.Time
 	CreatedAt   time.Time
 	CompletedAt *time.Time
+	// CompletedBy is the user who moved the task to done. It is unknown for
+	// tasks completed before it was recorded.
+	CompletedBy *uuid.UUID
+	ParentID    *uuid.UUID
+	SeriesID    *uuid.UUID
+	Subtasks    SubtaskProgress
+	BlockedBy   []Blocker
+	Labels      []Label
+	// Checklist is ordered by position. It is loaded for single tasks only;
+	// ChecklistProgress is always set.
+	Checklist         []ChecklistItem
+	ChecklistProgress ChecklistProgress
+	// Version is bumped by storage on every write and backs optimistic locking.
+	Version int64
 }
 
 type TaskFilter struct {
-	Limit   int
-	Offset  int
-	Status  *Status
-	Search  *string
-	SortBy  string
-	SortDir string
+	Limit     int
+	Offset    int
+	Status    *Status
+	Category  *StatusCategory
+	Priority  *Priority
+	Search    *string
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Overdue selects open tasks whose due date has passed (true) or every
+	// other task (false).
+	Overdue *bool
+	// LabelsAny keeps tasks carrying at least one of the labels, LabelsAll
+	// only tasks carrying every one of them.
+	LabelsAny []uuid.UUID
+	LabelsAll []uuid.UUID
+	ProjectID *uuid.UUID
+	// AssigneeID keeps the tasks assigned to the user, Unassigned only the
+	// tasks nobody is assigned to.
+	AssigneeID *uuid.UUID
+	Unassigned bool
+	ParentID   *uuid.UUID
+	SeriesID   *uuid.UUID
+	Blocked    *bool
+	SortBy     string
+	SortDir    string
 }
 
 func (f *TaskFilter) Normalize() {
@@ -82,25 +172,33 @@ func NewTask(userID uuid.UUID, title, description string) (Task, error) {
 		Title:       title,
 		Description: description,
 		Status:      StatusPending,
+		Category:    CategoryTodo,
+		Priority:    PriorityMedium,
 		CreatedAt:   createdAt,
+		Version:     1,
 	}, nil
 }
 
-func (s Status) IsValid() bool {
-	s = NormalizeStatus(s)
-
-	switch s {
-	case StatusPending, StatusInProgress, StatusDone, StatusCancelled:
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
 		return true
 	default:
 		return false
 	}
 }
 
-func NewTaskFromStorage(id, userID uuid.UUID, title, description string, status Status, createdAt time.Time, completedAt *time.Time) (Task, error) {
+func NewTaskFromStorage(
+	id, userID uuid.UUID,
+	title, description string,
+	status Status,
+	category StatusCategory,
+	createdAt time.Time,
+	completedAt *time.Time,
+) (Task, error) {
 	status = NormalizeStatus(status)
 
-	if !status.IsValid() {
+	if status == "" || !category.IsValid() {
 		return Task{}, ErrInvalidStatus
 	}
 
@@ -117,6 +215,7 @@ func NewTaskFromStorage(id, userID uuid.UUID, title, description string, status
 		Title:       title,
 		Description: description,
 		Status:      status,
+		Category:    category,
 		CreatedAt:   createdAt,
 		CompletedAt: completedAt,
 	}
@@ -137,25 +236,89 @@ func (t *Task) Rename(newTitle string) error {
 	return nil
 }
 
-func (t *Task) ChangeStatus(to Status, now time.Time) error {
-	to = NormalizeStatus(to)
+// EnterWorkflow puts a new task into the initial status of the workflow.
+func (t *Task) EnterWorkflow(w Workflow) {
+	initial := w.Initial()
+	t.Status = initial.Key
+	t.Category = initial.Category
+}
 
-	if !to.IsValid() {
+// ChangeStatus moves the task to another status of the workflow along one of
+// its transitions on behalf of the user.
+func (t *Task) ChangeStatus(w Workflow, to Status, by uuid.UUID, now time.Time) error {
+	target, ok := w.Status(to)
+	if !ok {
 		return ErrInvalidStatus
 	}
-	if !isAllowedTransition(t.Status, to) {
+	if !w.Allows(t.Status, target.Key) {
 		return ErrInvalidTransition
 	}
+	if (target.Category == CategoryInProgress || target.Category == CategoryDone) && t.IsBlocked() {
+		return ErrBlocked
+	}
+	if target.Category == CategoryDone && t.Subtasks.Open() > 0 {
+		return ErrOpenSubtasks
+	}
 
-	t.Status = to
+	t.Status = target.Key
+	t.Category = target.Category
 
-	if to == StatusDone {
+	if target.Category == CategoryDone {
 		n := now.UTC()
 		t.CompletedAt = &n
+		t.CompletedBy = &by
 	} else {
 		t.CompletedAt = nil
+		t.CompletedBy = nil
+	}
+
+	return t.checkInvariants()
+}
+
+// Reopen moves a done task back to an open status of the workflow, the
+// initial one when to is empty. Unlike ChangeStatus it does not need a
+// transition in the workflow, so a completed task can always be taken back.
+func (t *Task) Reopen(w Workflow, to Status) error {
+	if !t.IsDone() {
+		return ErrNotDone
+	}
+
+	target := w.Initial()
+	if to != "" {
+		s, ok := w.Status(to)
+		if !ok {
+			return ErrInvalidStatus
+		}
+		target = s
 	}
 
+	if target.Category != CategoryTodo && target.Category != CategoryInProgress {
+		return ErrInvalidTransition
+	}
+	if target.Category == CategoryInProgress && t.IsBlocked() {
+		return ErrBlocked
+	}
+
+	t.Status = target.Key
+	t.Category = target.Category
+	t.CompletedAt = nil
+	t.CompletedBy = nil
+
+	return t.checkInvariants()
+}
+
+// Restore moves a canceled task back to the initial status of the workflow.
+func (t *Task) Restore(w Workflow) error {
+	if t.Category != CategoryCanceled {
+		return ErrNotCanceled
+	}
+
+	initial := w.Initial()
+	t.Status = initial.Key
+	t.Category = initial.Category
+	t.CompletedAt = nil
+	t.CompletedBy = nil
+
 	return t.checkInvariants()
 }
 
@@ -164,32 +327,125 @@ func (t *Task) ChangeDescription(desc string) {
 	t.Description = desc
 }
 
-func NormalizeStatus(status Status) Status {
-	if status == "cancelled" {
-		return StatusCancelled
+func (t *Task) ChangePriority(priority Priority) error {
+	priority = Priority(strings.ToLower(strings.TrimSpace(string(priority))))
+	if !priority.IsValid() {
+		return ErrInvalidPriority
 	}
+	t.Priority = priority
+	return nil
+}
 
-	return status
+// Reschedule sets a new due date; nil removes it.
+func (t *Task) Reschedule(dueAt *time.Time) {
+	if dueAt == nil {
+		t.DueAt = nil
+		return
+	}
+
+	d := dueAt.UTC()
+	t.DueAt = &d
 }
 
-func isAllowedTransition(from, to Status) bool {
-	switch from {
-	case StatusPending:
-		return to == StatusInProgress || to == StatusCancelled || to == StatusDone
-	case StatusInProgress:
-		return to == StatusDone || to == StatusCancelled
-	case StatusDone, StatusCancelled:
-		return false
-	default:
+// MoveUnder places the task below parent. ancestors lists the parent's own
+// ancestors, nearest first, and height is how many levels of subtasks the task
+// already has below it.
+func (t *Task) MoveUnder(parent Task, ancestors []uuid.UUID, height int) error {
+	if parent.ID == t.ID {
+		return ErrTaskCycle
+	}
+	if parent.ProjectID != t.ProjectID {
+		return ErrProjectMismatch
+	}
+	for _, id := range ancestors {
+		if id == t.ID {
+			return ErrTaskCycle
+		}
+	}
+
+	// parent's ancestors + parent + the task itself + its subtree
+	if len(ancestors)+2+height > MaxTaskDepth {
+		return ErrTaskTooDeep
+	}
+
+	if parent.isClosed() && !t.isClosed() {
+		return ErrParentClosed
+	}
+
+	parentID := parent.ID
+	t.ParentID = &parentID
+	return nil
+}
+
+// Assign hands the task to a member of its project.
+func (t *Task) Assign(member ProjectMember) error {
+	if member.ProjectID != t.ProjectID || member.UserID == uuid.Nil {
+		return ErrAssigneeNotMember
+	}
+
+	assigneeID := member.UserID
+	t.AssigneeID = &assigneeID
+	return nil
+}
+
+// Unassign leaves the task without an assignee.
+func (t *Task) Unassign() {
+	t.AssigneeID = nil
+}
+
+// MoveToRoot detaches the task from its parent.
+func (t *Task) MoveToRoot() {
+	t.ParentID = nil
+}
+
+// MarkSubtasksDone records that every open subtask has been completed, which
+// lets a cascading completion pass the open subtasks rule.
+func (t *Task) MarkSubtasksDone() {
+	t.Subtasks.Done = t.Subtasks.Total
+}
+
+// IsBlocked reports whether any task this one depends on is still open.
+func (t Task) IsBlocked() bool {
+	for _, b := range t.BlockedBy {
+		if b.IsOpen() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOverdue reports whether an open task has missed its due date.
+func (t Task) IsOverdue(now time.Time) bool {
+	if t.DueAt == nil || t.isClosed() {
 		return false
 	}
+
+	return t.DueAt.Before(now)
+}
+
+// IsDone reports whether the task sits in a done status.
+func (t Task) IsDone() bool {
+	return t.Category == CategoryDone
+}
+
+func (t Task) isClosed() bool {
+	return t.Category.IsClosed()
+}
+
+func NormalizeStatus(status Status) Status {
+	if status == "cancelled" {
+		return StatusCancelled
+	}
+
+	return status
 }
 
 func (t *Task) checkInvariants() error {
-	if t.Status == StatusDone && t.CompletedAt == nil {
+	if t.IsDone() && t.CompletedAt == nil {
 		return ErrInvalidTransition
 	}
-	if t.Status != StatusDone && t.CompletedAt != nil {
+	if !t.IsDone() && (t.CompletedAt != nil || t.CompletedBy != nil) {
 		return ErrInvalidTransition
 	}
 	return nil
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"net/mail"
 	"strings"
 	"time"
 
@@ -13,13 +14,19 @@ var (
 	ErrInvalidUserEmail  = errors.New("invalid user email")
 	ErrEmptyPassword     = errors.New("password is empty")
 	ErrEmptyPasswordHash = errors.New("password hash is empty")
+	ErrEmailNotVerified  = errors.New("email not verified")
 )
 
+// maxEmailLength is the longest address SMTP can deliver to (RFC 5321).
+const maxEmailLength = 254
+
 type User struct {
 	ID           uuid.UUID
 	Email        string
 	PasswordHash string
 	CreatedAt    time.Time
+	// EmailVerifiedAt is when the user proved to own the email; nil until then.
+	EmailVerifiedAt *time.Time
 }
 
 func NewUser(email, passwordHash string) (User, error) {
@@ -33,8 +40,8 @@ func NewUserWithID(id uuid.UUID, email, passwordHash string) (User, error) {
 	if id == uuid.Nil {
 		return User{}, ErrInvalidUserID
 	}
-	if email == "" {
-		return User{}, ErrInvalidUserEmail
+	if err := ValidateUserEmail(email); err != nil {
+		return User{}, err
 	}
 	if passwordHash == "" {
 		return User{}, ErrEmptyPasswordHash
@@ -47,14 +54,65 @@ func NewUserWithID(id uuid.UUID, email, passwordHash string) (User, error) {
 	}, nil
 }
 
-func NewUserFromStorage(id uuid.UUID, email, passwordHash string, createdAt time.Time) (User, error) {
-	user, err := NewUserWithID(id, email, passwordHash)
-	if err != nil {
-		return User{}, err
+// NewUserFromStorage does not check the email format, which accounts created
+// before it was checked may not meet.
+func NewUserFromStorage(
+	id uuid.UUID,
+	email, passwordHash string,
+	createdAt time.Time,
+	emailVerifiedAt *time.Time,
+) (User, error) {
+	if id == uuid.Nil {
+		return User{}, ErrInvalidUserID
+	}
+	if email == "" {
+		return User{}, ErrInvalidUserEmail
+	}
+	if passwordHash == "" {
+		return User{}, ErrEmptyPasswordHash
+	}
+
+	return User{
+		ID:              id,
+		Email:           email,
+		PasswordHash:    passwordHash,
+		CreatedAt:       createdAt,
+		EmailVerifiedAt: emailVerifiedAt,
+	}, nil
+}
+
+// ValidateUserEmail accepts a single bare address in the syntax of RFC 5322,
+// such as user@example.com. Display names, comments and address lists are
+// rejected, as are domains without a dot.
+func ValidateUserEmail(email string) error {
+	if email == "" || len(email) > maxEmailLength {
+		return ErrInvalidUserEmail
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return ErrInvalidUserEmail
 	}
 
-	user.CreatedAt = createdAt
-	return user, nil
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return ErrInvalidUserEmail
+	}
+
+	return nil
+}
+
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail records that the user proved to own the email. Verifying again
+// keeps the first time.
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &now
+	}
 }
 
 func normalizeEmail(email string) string {
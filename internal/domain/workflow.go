@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// StatusCategory is the meaning a workflow status has for the rest of the
+// system: only done statuses complete a task, done and canceled ones close it,
+// and in_progress and done ones are refused while the task is blocked.
+type StatusCategory string
+
+const (
+	CategoryTodo       StatusCategory = "todo"
+	CategoryInProgress StatusCategory = "in_progress"
+	CategoryDone       StatusCategory = "done"
+	CategoryCanceled   StatusCategory = "canceled"
+)
+
+const (
+	MaxWorkflowStatuses      = 20
+	maxWorkflowStatusNameLen = 50
+)
+
+var (
+	ErrInvalidWorkflow = errors.New("invalid workflow")
+	ErrStatusInUse     = errors.New("status is used by tasks")
+)
+
+var statusKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+func (c StatusCategory) IsValid() bool {
+	switch c {
+	case CategoryTodo, CategoryInProgress, CategoryDone, CategoryCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c StatusCategory) IsClosed() bool {
+	return c == CategoryDone || c == CategoryCanceled
+}
+
+// WorkflowStatus is one column of a workflow.
+type WorkflowStatus struct {
+	Key      Status
+	Name     string
+	Category StatusCategory
+}
+
+type Transition struct {
+	From Status
+	To   Status
+}
+
+// Workflow is the set of statuses a user's tasks move through and the
+// transitions allowed between them. The first status is the one new tasks
+// start in.
+type Workflow struct {
+	// UserID is nil for the default workflow.
+	UserID      *uuid.UUID
+	Statuses    []WorkflowStatus
+	Transitions []Transition
+}
+
+// DefaultWorkflow is the workflow of users who have not defined their own. It
+// is seeded into storage as well.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Statuses: []WorkflowStatus{
+			{Key: StatusPending, Name: "Pending", Category: CategoryTodo},
+			{Key: StatusInProgress, Name: "In progress", Category: CategoryInProgress},
+			{Key: StatusDone, Name: "Done", Category: CategoryDone},
+			{Key: StatusCancelled, Name: "Canceled", Category: CategoryCanceled},
+		},
+		Transitions: []Transition{
+			{From: StatusPending, To: StatusInProgress},
+			{From: StatusPending, To: StatusDone},
+			{From: StatusPending, To: StatusCancelled},
+			{From: StatusInProgress, To: StatusDone},
+			{From: StatusInProgress, To: StatusCancelled},
+		},
+	}
+}
+
+// NewWorkflow builds a user's workflow and checks that it is usable: status
+// keys are unique, the first status is a todo one, at least one status is done
+// and every transition connects two distinct known statuses.
+func NewWorkflow(userID uuid.UUID, statuses []WorkflowStatus, transitions []Transition) (Workflow, error) {
+	if len(statuses) == 0 || len(statuses) > MaxWorkflowStatuses {
+		return Workflow{}, fmt.Errorf("%w: between 1 and %d statuses are required", ErrInvalidWorkflow, MaxWorkflowStatuses)
+	}
+
+	w := Workflow{UserID: &userID}
+	seen := make(map[Status]bool, len(statuses))
+	hasDone := false
+
+	for _, s := range statuses {
+		s.Key = Status(strings.TrimSpace(string(s.Key)))
+		s.Name = strings.TrimSpace(s.Name)
+
+		if !statusKeyPattern.MatchString(string(s.Key)) {
+			return Workflow{}, fmt.Errorf("%w: status key %q must be lowercase letters, digits and underscores", ErrInvalidWorkflow, s.Key)
+		}
+		if s.Key == "cancelled" {
+			return Workflow{}, fmt.Errorf("%w: status key %q is reserved", ErrInvalidWorkflow, s.Key)
+		}
+		if seen[s.Key] {
+			return Workflow{}, fmt.Errorf("%w: duplicate status %q", ErrInvalidWorkflow, s.Key)
+		}
+		if s.Name == "" || utf8.RuneCountInString(s.Name) > maxWorkflowStatusNameLen {
+			return Workflow{}, fmt.Errorf("%w: status %q needs a name of at most %d characters", ErrInvalidWorkflow, s.Key, maxWorkflowStatusNameLen)
+		}
+		if !s.Category.IsValid() {
+			return Workflow{}, fmt.Errorf("%w: status %q has unknown category %q", ErrInvalidWorkflow, s.Key, s.Category)
+		}
+
+		seen[s.Key] = true
+		hasDone = hasDone || s.Category == CategoryDone
+		w.Statuses = append(w.Statuses, s)
+	}
+
+	if w.Statuses[0].Category != CategoryTodo {
+		return Workflow{}, fmt.Errorf("%w: the first status must be a todo status", ErrInvalidWorkflow)
+	}
+	if !hasDone {
+		return Workflow{}, fmt.Errorf("%w: at least one done status is required", ErrInvalidWorkflow)
+	}
+
+	edges := make(map[Transition]bool, len(transitions))
+	for _, t := range transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return Workflow{}, fmt.Errorf("%w: transition %s -> %s uses an unknown status", ErrInvalidWorkflow, t.From, t.To)
+		}
+		if t.From == t.To {
+			return Workflow{}, fmt.Errorf("%w: transition %s -> %s leads nowhere", ErrInvalidWorkflow, t.From, t.To)
+		}
+		if edges[t] {
+			continue
+		}
+		edges[t] = true
+		w.Transitions = append(w.Transitions, t)
+	}
+
+	return w, nil
+}
+
+func NewWorkflowFromStorage(userID *uuid.UUID, statuses []WorkflowStatus, transitions []Transition) (Workflow, error) {
+	if len(statuses) == 0 || statuses[0].Category != CategoryTodo {
+		return Workflow{}, ErrInvalidWorkflow
+	}
+
+	return Workflow{
+		UserID:      userID,
+		Statuses:    statuses,
+		Transitions: transitions,
+	}, nil
+}
+
+func (w Workflow) IsDefault() bool {
+	return w.UserID == nil
+}
+
+// Initial returns the status new tasks start in.
+func (w Workflow) Initial() WorkflowStatus {
+	return w.Statuses[0]
+}
+
+func (w Workflow) Status(key Status) (WorkflowStatus, bool) {
+	key = NormalizeStatus(key)
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return s, true
+		}
+	}
+
+	return WorkflowStatus{}, false
+}
+
+// FirstIn returns the first status of the category in workflow order.
+func (w Workflow) FirstIn(category StatusCategory) (WorkflowStatus, bool) {
+	for _, s := range w.Statuses {
+		if s.Category == category {
+			return s, true
+		}
+	}
+
+	return WorkflowStatus{}, false
+}
+
+func (w Workflow) Allows(from, to Status) bool {
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckReplaces reports whether w can take the place of a workflow while tasks
+// sit in the inUse statuses: each of them has to stay, with the same category,
+// since stored tasks keep their status and its meaning.
+func (w Workflow) CheckReplaces(inUse map[Status]StatusCategory) error {
+	for key, category := range inUse {
+		s, ok := w.Status(key)
+		if !ok {
+			return fmt.Errorf("%w: %q cannot be removed", ErrStatusInUse, key)
+		}
+		if s.Category != category {
+			return fmt.Errorf("%w: the category of %q cannot change", ErrStatusInUse, key)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type TaskAnalyticsResponse struct {
 	TasksCreated   int64     `json:"tasks_created"`
@@ -8,4 +12,13 @@ type TaskAnalyticsResponse struct {
 	TasksOpen      int64     `json:"tasks_open"`
 	CompletionRate float64   `json:"completion_rate"`
 	LastUpdatedAt  time.Time `json:"last_updated_at"`
+	// Assignees counts the tasks the user assigned to and completed for each
+	// assignee, most assigned first.
+	Assignees []AssigneeAnalyticsResponse `json:"assignees"`
+}
+
+type AssigneeAnalyticsResponse struct {
+	AssigneeID     uuid.UUID `json:"assignee_id"`
+	TasksAssigned  int64     `json:"tasks_assigned"`
+	TasksCompleted int64     `json:"tasks_completed"`
 }
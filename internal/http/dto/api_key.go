@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"CI deploy"`
+	Scopes []string `json:"scopes" example:"tasks:read"`
+	// ExpiresAt is omitted for a key that does not expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Prefix identifies the key; every key starts with tf_<prefix>_.
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	// Key is sent as the bearer token. It is shown only this once.
+	Key string `json:"key"`
+}
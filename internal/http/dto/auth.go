@@ -1,11 +1,91 @@
 package dto
 
+import "time"
+
 type AuthRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	// RefreshToken, when given, is revoked together with the access token.
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	// Token is the short-lived access token sent as the bearer token.
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// RefreshToken is exchanged at /auth/refresh for a new pair once the
+	// access token expires. It can be used only once.
+	RefreshToken string       `json:"refresh_token"`
+	User         UserResponse `json:"user"`
+}
+
+// MFAChallengeResponse answers a login when the user has two-factor
+// authentication enabled. The MFA token is exchanged at /auth/mfa/verify
+// together with a code for an AuthResponse.
+type MFAChallengeResponse struct {
+	MFAToken  string    `json:"mfa_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	// Code is a code from the authenticator app or an unused recovery code.
+	Code string `json:"code"`
+}
+
+type MFACodeRequest struct {
+	// Code is a code from the authenticator app; disabling also accepts an
+	// unused recovery code.
+	Code string `json:"code"`
+}
+
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	// OTPAuthURI is the otpauth:// URI to show as a QR code.
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	// RecoveryCodes each replace a code from the authenticator app once.
+	// They are shown only this time.
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	// Token is the token from the password reset mail.
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	// Token is the token from the verification mail.
+	Token string `json:"token"`
+}
+
+// JWK is a public signing key as described in RFC 7517. RSA keys carry N and
+// E, Ed25519 keys Crv and X.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
 }
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateCommentRequest struct {
+	Body string `json:"body" example:"Blocked on the **API review**."`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+type CommentPageResponse struct {
+	Comments []CommentResponse `json:"comments"`
+	// NextCursor is passed as the cursor query parameter to fetch the next
+	// page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateLabelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color" example:"#1e88e5"`
+}
+
+type UpdateLabelRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color" example:"#1e88e5"`
+}
+
+type LabelResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateProjectRequest struct {
+	Name string `json:"name" example:"Platform team"`
+}
+
+type UpdateProjectRequest struct {
+	Name string `json:"name" example:"Platform team"`
+}
+
+type SetProjectMemberRequest struct {
+	Role string `json:"role" enums:"admin,editor,viewer" example:"editor"`
+}
+
+type ProjectResponse struct {
+	ID       uuid.UUID `json:"id"`
+	OwnerID  uuid.UUID `json:"owner_id"`
+	Name     string    `json:"name"`
+	Personal bool      `json:"personal"`
+	// Role is the caller's role, returned when a single project is requested.
+	Role      string    `json:"role,omitempty" enums:"owner,admin,editor,viewer"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProjectMemberResponse struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Role     string    `json:"role" enums:"owner,admin,editor,viewer"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" example:"teammate@example.com"`
+	Role  string `json:"role" enums:"admin,editor,viewer" example:"editor"`
+}
+
+type InvitationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role" enums:"admin,editor,viewer"`
+	Status    string    `json:"status" enums:"pending,accepted,declined"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+	// Password registers the account of an invitee who has none yet.
+	Password string `json:"password,omitempty"`
+}
+
+type DeclineInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type AcceptInvitationResponse struct {
+	Member ProjectMemberResponse `json:"member"`
+	// The tokens are returned when accepting the invitation registered the
+	// account.
+	Token        string     `json:"token,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+}
@@ -1,30 +1,150 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority" enums:"low,medium,high,urgent"`
+	DueAt       *time.Time `json:"due_at"`
+	// ProjectID defaults to the project of the parent task, or to the
+	// personal project of the user.
+	ProjectID *uuid.UUID `json:"project_id"`
+	// ParentID creates the task as a subtask of another task.
+	ParentID *uuid.UUID `json:"parent_id"`
+	// Recurrence is an RFC 5545 RRULE such as "FREQ=WEEKLY;BYDAY=MO"; it
+	// requires due_at, which becomes the first occurrence.
+	Recurrence string `json:"recurrence"`
 }
 
 type UpdateTaskRequest struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
+	Status      *string `json:"status"`
+	Priority    *string `json:"priority" enums:"low,medium,high,urgent"`
+	// DueAt is an RFC 3339 timestamp; null or an empty string removes it.
+	DueAt *string `json:"due_at"`
+}
+
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
 }
 
 type ChangeStatusRequest struct {
+	// Status is a status key of the user's workflow.
 	Status string `json:"status"`
+	// Cascade completes every open subtask together with the task; it only
+	// applies when status is a done status.
+	Cascade bool `json:"cascade"`
+}
+
+type ReopenTaskRequest struct {
+	// Status is an open status of the user's workflow to reopen the task in;
+	// the initial status when empty.
+	Status string `json:"status" example:"in_progress"`
+}
+
+type MoveTaskRequest struct {
+	// ParentID is the new parent task; null makes the task a top level task.
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type TaskProgressResponse struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type ChecklistItemResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Text     string    `json:"text"`
+	Checked  bool      `json:"checked"`
+	Position int       `json:"position"`
+}
+
+type AddChecklistItemRequest struct {
+	Text string `json:"text"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Text    *string `json:"text"`
+	Checked *bool   `json:"checked"`
+}
+
+type ReorderChecklistRequest struct {
+	// ItemIDs lists every checklist item of the task in the new order.
+	ItemIDs []uuid.UUID `json:"item_ids"`
+}
+
+type AssignTaskRequest struct {
+	// AssigneeID is a member of the task's project.
+	AssigneeID uuid.UUID `json:"assignee_id"`
+}
+
+type TaskBlockerResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Status         string    `json:"status"`
+	StatusCategory string    `json:"status_category" enums:"todo,in_progress,done,canceled"`
 }
 
 type TaskResponse struct {
-	ID          uuid.UUID  `json:"id"`
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// CreatedBy is the user who created the task.
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	AssigneeID  *uuid.UUID `json:"assignee_id,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      string     `json:"status"`
+	// StatusCategory is what the workflow status means: done tasks count as
+	// completed, done and canceled ones as closed.
+	StatusCategory string          `json:"status_category" enums:"todo,in_progress,done,canceled"`
+	Priority       string          `json:"priority"`
+	DueAt          *time.Time      `json:"due_at,omitempty"`
+	Overdue        bool            `json:"overdue"`
+	Labels         []LabelResponse `json:"labels"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	ParentID       *uuid.UUID      `json:"parent_id,omitempty"`
+	SeriesID       *uuid.UUID      `json:"series_id,omitempty"`
+	// Progress counts done subtasks out of all non-canceled ones; it is
+	// omitted for tasks without subtasks.
+	Progress *TaskProgressResponse `json:"progress,omitempty"`
+	// Blocked is true while any task in BlockedBy is still open.
+	Blocked   bool                  `json:"blocked"`
+	BlockedBy []TaskBlockerResponse `json:"blocked_by"`
+	// Checklist is returned for single tasks and omitted from lists.
+	Checklist []ChecklistItemResponse `json:"checklist,omitempty"`
+	// ChecklistProgress counts checked items; it is omitted for tasks without
+	// a checklist.
+	ChecklistProgress *TaskProgressResponse `json:"checklist_progress,omitempty"`
+	Version           int64                 `json:"version"`
+}
+
+type UpdateSeriesRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Priority    *string `json:"priority" enums:"low,medium,high,urgent"`
+	// Recurrence replaces the RRULE; it is anchored to the newest occurrence.
+	Recurrence *string `json:"recurrence"`
+}
+
+type SeriesResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Recurrence  string     `json:"recurrence"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	StartsAt    time.Time  `json:"starts_at"`
+	LastDueAt   time.Time  `json:"last_due_at"`
+	Active      bool       `json:"active"`
+	StoppedAt   *time.Time `json:"stopped_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
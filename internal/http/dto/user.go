@@ -15,4 +15,6 @@ type UserResponse struct {
 	ID        uuid.UUID `json:"id"`
 	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
+	// EmailVerifiedAt is null until the user follows the verification link.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
@@ -0,0 +1,27 @@
+package dto
+
+type WorkflowStatusDTO struct {
+	// Key is what tasks carry in their status field.
+	Key      string `json:"key" example:"review"`
+	Name     string `json:"name" example:"In review"`
+	Category string `json:"category" enums:"todo,in_progress,done,canceled"`
+}
+
+type WorkflowTransitionDTO struct {
+	From string `json:"from" example:"in_progress"`
+	To   string `json:"to" example:"review"`
+}
+
+type ReplaceWorkflowRequest struct {
+	// Statuses are in board order; new tasks start in the first one, which
+	// must be a todo status.
+	Statuses    []WorkflowStatusDTO     `json:"statuses"`
+	Transitions []WorkflowTransitionDTO `json:"transitions"`
+}
+
+type WorkflowResponse struct {
+	// Default is true while the user has no workflow of their own.
+	Default     bool                    `json:"default"`
+	Statuses    []WorkflowStatusDTO     `json:"statuses"`
+	Transitions []WorkflowTransitionDTO `json:"transitions"`
+}
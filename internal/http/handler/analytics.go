@@ -20,12 +20,13 @@ func NewAnalyticsHandler(service *service.TaskAnalyticsService) *AnalyticsHandle
 
 // Get godoc
 // @Summary Get task analytics
-// @Description Returns per-user task analytics produced by the Kafka worker and stored in task_analytics.
+// @Description Returns per-user task analytics produced by the Kafka worker and stored in task_analytics, with a breakdown by assignee from task_assignee_analytics.
 // @Tags analytics
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} dto.TaskAnalyticsResponse
 // @Failure 401 {string} string "missing token, invalid token, or invalid auth context"
+// @Failure 403 {string} string "insufficient scope"
 // @Failure 500 {string} string "unexpected server error"
 // @Router /analytics [get]
 func (h *AnalyticsHandler) Get(c echo.Context) error {
@@ -53,11 +54,21 @@ func toTaskAnalyticsResponse(analytics domain.TaskAnalytics) dto.TaskAnalyticsRe
 		completionRate = float64(analytics.TasksCompleted) / float64(analytics.TasksCreated)
 	}
 
+	assignees := make([]dto.AssigneeAnalyticsResponse, 0, len(analytics.Assignees))
+	for _, a := range analytics.Assignees {
+		assignees = append(assignees, dto.AssigneeAnalyticsResponse{
+			AssigneeID:     a.AssigneeID,
+			TasksAssigned:  a.TasksAssigned,
+			TasksCompleted: a.TasksCompleted,
+		})
+	}
+
 	return dto.TaskAnalyticsResponse{
 		TasksCreated:   analytics.TasksCreated,
 		TasksCompleted: analytics.TasksCompleted,
 		TasksOpen:      tasksOpen,
 		CompletionRate: completionRate,
 		LastUpdatedAt:  analytics.UpdatedAt,
+		Assignees:      assignees,
 	}
 }
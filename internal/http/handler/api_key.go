@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// Create godoc
+// @Summary Create API key
+// @Description Creates a personal API key limited to the given scopes. The key is sent as a bearer token and is returned only in this response. Keys cannot hold the account scope, so they cannot manage API keys.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAPIKeyRequest true "API key creation payload"
+// @Success 201 {object} dto.CreatedAPIKeyResponse
+// @Failure 400 {string} string "invalid request or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	key, secret, err := h.service.CreateAPIKey(c.Request().Context(), userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return apiKeyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            secret,
+	})
+}
+
+// List godoc
+// @Summary List API keys
+// @Description Returns the API keys of the authenticated user that have not been revoked, newest first. Secrets are never returned.
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.APIKeyResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	keys, err := h.service.ListAPIKeys(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toAPIKeyResponse(key))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Description Revokes an API key of the authenticated user. Requests made with it are rejected from then on.
+// @Tags api-keys
+// @Security BearerAuth
+// @Param id path string true "API key ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "api key not found"
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c echo.Context) error {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.RevokeAPIKey(c.Request().Context(), userID, keyID); err != nil {
+		return apiKeyError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func apiKeyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrEmptyAPIKeyName),
+		errors.Is(err, domain.ErrAPIKeyNameTooLong),
+		errors.Is(err, domain.ErrNoAPIKeyScopes),
+		errors.Is(err, domain.ErrUnknownScope),
+		errors.Is(err, domain.ErrScopeNotGrantable),
+		errors.Is(err, domain.ErrInvalidAPIKeyTTL):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toAPIKeyResponse(key domain.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
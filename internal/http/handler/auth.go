@@ -1,8 +1,17 @@
 package handler
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math"
+	"math/big"
 	"net/http"
+	"strconv"
+	"taskflow/internal/domain"
 	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
 	"taskflow/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -22,7 +31,7 @@ func NewAuthHandler(authService *service.AuthService, userService *service.UserS
 
 // Register godoc
 // @Summary Register a new user
-// @Description Creates a new user account and returns an authentication token for the created user.
+// @Description Creates a new user account and returns a short-lived access token and a refresh token for the created user.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -36,18 +45,167 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 // Login godoc
 // @Summary Authenticate user
-// @Description Verifies user credentials and returns an authentication token.
+// @Description Verifies user credentials and returns a short-lived access token and a refresh token. Users with two-factor authentication enabled get 202 with an MFA token instead, which is exchanged at /auth/mfa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dto.AuthRequest true "Login payload"
 // @Success 200 {object} dto.AuthResponse
+// @Success 202 {object} dto.MFAChallengeResponse "second factor required"
 // @Failure 400 {string} string "invalid request or invalid credentials"
+// @Failure 429 {string} string "too many failed login attempts; see the Retry-After header"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c echo.Context) error {
 	return h.authenticate(c, false)
 }
 
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can be used once; presenting a used one again revokes every refresh token issued since the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh token"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "invalid, expired, revoked, or reused refresh token"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req dto.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	tokens, err := h.authService.Refresh(c.Request().Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, service.ErrInvalidToken),
+		errors.Is(err, domain.ErrRefreshTokenExpired),
+		errors.Is(err, domain.ErrRefreshTokenRevoked),
+		errors.Is(err, domain.ErrRefreshTokenReused):
+		return c.JSON(http.StatusUnauthorized, err.Error())
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return h.respond(c, tokens)
+}
+
+// VerifyMFA godoc
+// @Summary Complete two-factor login
+// @Description Exchanges the MFA token from a login and a code from the authenticator app or an unused recovery code for a short-lived access token and a refresh token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyMFARequest true "MFA token and code"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "invalid, expired, or used up MFA token, or invalid code"
+// @Failure 429 {string} string "too many failed codes; see the Retry-After header"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c echo.Context) error {
+	var req dto.VerifyMFARequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	tokens, err := h.authService.VerifyMFA(c.Request().Context(), req.MFAToken, req.Code)
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		return tooManyAttempts(c, throttled)
+	case errors.Is(err, service.ErrInvalidMFAToken),
+		errors.Is(err, service.ErrMFATokenExpired),
+		errors.Is(err, domain.ErrInvalidMFACode):
+		return c.JSON(http.StatusUnauthorized, err.Error())
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return h.respond(c, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revokes the access token the request is made with. A refresh token passed in the body is revoked too, together with every refresh token issued since the same login.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body dto.LogoutRequest false "Refresh token to revoke"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "missing, invalid, or revoked token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req dto.LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	claims, ok := middleware2.TokenClaimsFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.authService.Logout(c.Request().Context(), claims, req.RefreshToken); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Log out everywhere
+// @Description Revokes every access and refresh token of the authenticated user, signing out all of their sessions.
+// @Tags auth
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {string} string "missing, invalid, or revoked token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/logout/all [post]
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.authService.LogoutAll(c.Request().Context(), userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// JWKS serves the public keys access tokens are signed with, so that other
+// services can verify them. It is mounted at /.well-known/jwks.json, outside
+// the API base path, and is not part of the Swagger spec. HS256 keys are
+// secret and never listed.
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	keys := h.authService.VerificationKeys()
+	resp := dto.JWKSResponse{Keys: make([]dto.JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := dto.JWK{Kid: key.ID, Alg: key.Algorithm, Use: "sig"}
+		switch public := key.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		resp.Keys = append(resp.Keys, jwk)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 func (h *AuthHandler) authenticate(c echo.Context, register bool) error {
 	var req dto.AuthRequest
 	if err := c.Bind(&req); err != nil {
@@ -55,26 +213,52 @@ func (h *AuthHandler) authenticate(c echo.Context, register bool) error {
 	}
 
 	var (
-		token string
-		err   error
+		tokens service.TokenPair
+		err    error
 	)
 
 	if register {
-		token, err = h.authService.Register(c.Request().Context(), req.Email, req.Password)
+		tokens, err = h.authService.Register(c.Request().Context(), req.Email, req.Password)
 	} else {
-		token, err = h.authService.Login(c.Request().Context(), req.Email, req.Password)
+		tokens, err = h.authService.Login(c.Request().Context(), req.Email, req.Password, c.RealIP())
+	}
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		return tooManyAttempts(c, throttled)
 	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	user, err := h.userService.GetUserByEmail(c.Request().Context(), req.Email)
+	if tokens.MFAToken != "" {
+		return c.JSON(http.StatusAccepted, dto.MFAChallengeResponse{
+			MFAToken:  tokens.MFAToken,
+			ExpiresAt: tokens.ExpiresAt,
+		})
+	}
+
+	return h.respond(c, tokens)
+}
+
+func (h *AuthHandler) respond(c echo.Context, tokens service.TokenPair) error {
+	user, err := h.userService.GetUser(c.Request().Context(), tokens.UserID)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
 	return c.JSON(http.StatusOK, dto.AuthResponse{
-		Token: token,
-		User:  toUserResponse(user),
+		Token:        tokens.AccessToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		RefreshToken: tokens.RefreshToken,
+		User:         toUserResponse(user),
 	})
 }
+
+// tooManyAttempts answers a throttled request with the wait in Retry-After,
+// rounded up so that a client retrying on time is not turned away again.
+func tooManyAttempts(c echo.Context, throttled *service.LoginThrottledError) error {
+	seconds := max(1, int(math.Ceil(throttled.RetryAfter.Seconds())))
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	return c.JSON(http.StatusTooManyRequests, throttled.Error())
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type CommentHandler struct {
+	service   *service.CommentService
+	analytics service.AnalyticsPublisher
+}
+
+func NewCommentHandler(commentService *service.CommentService, analytics service.AnalyticsPublisher) *CommentHandler {
+	if analytics == nil {
+		analytics = service.NewNoopAnalyticsPublisher()
+	}
+
+	return &CommentHandler{
+		service:   commentService,
+		analytics: analytics,
+	}
+}
+
+// Create godoc
+// @Summary Add comment
+// @Description Adds a markdown comment to the thread of a task.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param request body dto.CreateCommentRequest true "Comment payload"
+// @Success 201 {object} dto.CommentResponse
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow commenting, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Router /tasks/{id}/comments [post]
+func (h *CommentHandler) Create(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.CreateCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	comment, err := h.service.AddComment(c.Request().Context(), userID, taskID, req.Body)
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
+		Type:      service.TaskEventCommentAdded,
+		UserID:    userID,
+		TaskID:    taskID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	return c.JSON(http.StatusCreated, toCommentResponse(comment))
+}
+
+// List godoc
+// @Summary List comments
+// @Description Returns the comment thread of a task oldest first, one page at a time. Pass next_cursor from the previous page as cursor to continue.
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of comments to return"
+// @Success 200 {object} dto.CommentPageResponse
+// @Failure 400 {string} string "invalid task id or invalid cursor"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Router /tasks/{id}/comments [get]
+func (h *CommentHandler) List(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	var limit int
+	if raw := c.QueryParam("limit"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil {
+			limit = v
+		}
+	}
+
+	page, err := h.service.ListComments(c.Request().Context(), userID, taskID, c.QueryParam("cursor"), limit)
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	resp := dto.CommentPageResponse{
+		Comments:   make([]dto.CommentResponse, 0, len(page.Comments)),
+		NextCursor: page.NextCursor,
+	}
+	for _, comment := range page.Comments {
+		resp.Comments = append(resp.Comments, toCommentResponse(comment))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Edit comment
+// @Description Replaces the body of a comment and records the edit time. Only the author can edit a comment.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param commentId path string true "Comment ID" format(uuid)
+// @Param request body dto.UpdateCommentRequest true "Comment payload"
+// @Success 200 {object} dto.CommentResponse
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "only the author can change a comment, or insufficient scope"
+// @Failure 404 {string} string "task or comment not found"
+// @Router /tasks/{id}/comments/{commentId} [patch]
+func (h *CommentHandler) Update(c echo.Context) error {
+	taskID, commentID, err := parseCommentPath(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	var req dto.UpdateCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	comment, err := h.service.EditComment(c.Request().Context(), userID, taskID, commentID, req.Body)
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toCommentResponse(comment))
+}
+
+// Delete godoc
+// @Summary Delete comment
+// @Description Deletes a comment. Only the author can delete a comment.
+// @Tags comments
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param commentId path string true "Comment ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "only the author can change a comment, or insufficient scope"
+// @Failure 404 {string} string "task or comment not found"
+// @Router /tasks/{id}/comments/{commentId} [delete]
+func (h *CommentHandler) Delete(c echo.Context) error {
+	taskID, commentID, err := parseCommentPath(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.DeleteComment(c.Request().Context(), userID, taskID, commentID); err != nil {
+		return commentError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseCommentPath(c echo.Context) (taskID, commentID uuid.UUID, err error) {
+	taskID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid id")
+	}
+
+	commentID, err = uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid comment id")
+	}
+
+	return taskID, commentID, nil
+}
+
+func commentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, "task not found")
+	case errors.Is(err, service.ErrCommentNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrForbidden),
+		errors.Is(err, domain.ErrNotCommentAuthor):
+		return c.JSON(http.StatusForbidden, err.Error())
+	default:
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+}
+
+func toCommentResponse(comment domain.Comment) dto.CommentResponse {
+	return dto.CommentResponse{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerificationHandler struct {
+	service *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(service *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{service: service}
+}
+
+// Verify godoc
+// @Summary Verify email
+// @Description Marks the email of the account the token was mailed to verified. Verifying an already verified email succeeds.
+// @Tags auth
+// @Accept json
+// @Param request body dto.VerifyEmailRequest true "Verification token"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid request or invalid verification token"
+// @Failure 410 {string} string "verification token expired"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/email/verify [post]
+func (h *EmailVerificationHandler) Verify(c echo.Context) error {
+	var req dto.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	if err := h.service.Verify(c.Request().Context(), req.Token); err != nil {
+		return emailVerificationError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Resend godoc
+// @Summary Resend verification mail
+// @Description Mails a new verification link to the authenticated user.
+// @Tags auth
+// @Security BearerAuth
+// @Success 202 "Accepted"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "user not found"
+// @Failure 409 {string} string "email already verified"
+// @Failure 429 {string} string "too many verification mails requested; see the Retry-After header"
+// @Failure 500 {string} string "verification mail could not be sent"
+// @Router /auth/email/verify/resend [post]
+func (h *EmailVerificationHandler) Resend(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.Resend(c.Request().Context(), userID); err != nil {
+		return emailVerificationError(c, err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func emailVerificationError(c echo.Context, err error) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		return tooManyAttempts(c, throttled)
+	case errors.Is(err, service.ErrInvalidVerificationToken):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrVerificationTokenExpired):
+		return c.JSON(http.StatusGone, err.Error())
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		return c.JSON(http.StatusConflict, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
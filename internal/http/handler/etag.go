@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"taskflow/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+func taskETag(task domain.Task) string {
+	return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// taskListETag is a weak validator over the ids and versions of a page of
+// tasks: it changes whenever any task on the page is written.
+func taskListETag(tasks []domain.Task) string {
+	hash := sha256.New()
+	for _, t := range tasks {
+		fmt.Fprintf(hash, "%s:%d;", t.ID, t.Version)
+	}
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether etag is listed in an If-Match or If-None-Match
+// header. If-Match requires strong comparison, If-None-Match uses weak one.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+			etag = strings.TrimPrefix(etag, "W/")
+		} else if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(etag, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModified answers a conditional GET with 304 when If-None-Match lists the
+// current validator.
+func notModified(c echo.Context, etag string) bool {
+	ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch)
+	return ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true)
+}
+
+// writePreconditionHolds evaluates If-Match and If-None-Match for an unsafe
+// request against the current task.
+func writePreconditionHolds(c echo.Context, current domain.Task) bool {
+	etag := taskETag(current)
+
+	if ifMatch := c.Request().Header.Get(headerIfMatch); ifMatch != "" && !etagMatches(ifMatch, etag, false) {
+		return false
+	}
+	if ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		return false
+	}
+
+	return true
+}
+
+func hasWritePrecondition(c echo.Context) bool {
+	return c.Request().Header.Get(headerIfMatch) != "" || c.Request().Header.Get(headerIfNoneMatch) != ""
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type InvitationHandler struct {
+	service *service.InvitationService
+}
+
+func NewInvitationHandler(service *service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+// Create godoc
+// @Summary Invite to project
+// @Description Mails an invitation to join the project to an email address. The invitation link is single-use and expires; owners and admins may invite only with roles below their own. The role defaults to editor.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.CreateInvitationRequest true "Invitation payload"
+// @Success 201 {object} dto.InvitationResponse
+// @Failure 400 {string} string "invalid request, invalid id, invalid email, or invalid role"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow granting this role, email not verified, or insufficient scope"
+// @Failure 404 {string} string "project not found"
+// @Failure 409 {string} string "personal project or user is already a member"
+// @Failure 500 {string} string "invitation could not be sent"
+// @Router /projects/{id}/invitations [post]
+func (h *InvitationHandler) Create(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	role := domain.RoleEditor
+	if req.Role != "" {
+		role = domain.ProjectRole(req.Role)
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	invitation, err := h.service.Invite(c.Request().Context(), userID, projectID, req.Email, role)
+	if err != nil {
+		return invitationError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toInvitationResponse(invitation))
+}
+
+// Accept godoc
+// @Summary Accept invitation
+// @Description Accepts the invitation the token was mailed with and adds the invitee to the project. Invitees without an account are registered with the given password and receive access and refresh tokens.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.AcceptInvitationRequest true "Invitation token and, for new accounts, a password"
+// @Success 200 {object} dto.AcceptInvitationResponse
+// @Failure 400 {string} string "invalid request or missing password"
+// @Failure 404 {string} string "invitation not found"
+// @Failure 409 {string} string "invitation already answered or user is already a member"
+// @Failure 410 {string} string "invitation expired"
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) Accept(c echo.Context) error {
+	var req dto.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	accepted, err := h.service.Accept(c.Request().Context(), req.Token, req.Password)
+	if err != nil {
+		return invitationError(c, err)
+	}
+
+	resp := dto.AcceptInvitationResponse{Member: toProjectMemberResponse(accepted.Member)}
+	if accepted.Tokens != nil {
+		resp.Token = accepted.Tokens.AccessToken
+		resp.ExpiresAt = &accepted.Tokens.ExpiresAt
+		resp.RefreshToken = accepted.Tokens.RefreshToken
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Decline godoc
+// @Summary Decline invitation
+// @Description Declines the invitation the token was mailed with; the token cannot be used afterwards.
+// @Tags invitations
+// @Accept json
+// @Param request body dto.DeclineInvitationRequest true "Invitation token"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid request"
+// @Failure 404 {string} string "invitation not found"
+// @Failure 409 {string} string "invitation already answered"
+// @Failure 410 {string} string "invitation expired"
+// @Router /invitations/decline [post]
+func (h *InvitationHandler) Decline(c echo.Context) error {
+	var req dto.DeclineInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	if err := h.service.Decline(c.Request().Context(), req.Token); err != nil {
+		return invitationError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func invitationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvitationNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvitationAnswered):
+		return c.JSON(http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvitationExpired):
+		return c.JSON(http.StatusGone, err.Error())
+	case errors.Is(err, domain.ErrInvalidUserEmail),
+		errors.Is(err, domain.ErrEmptyPassword):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		return projectError(c, err)
+	}
+}
+
+func toInvitationResponse(invitation domain.Invitation) dto.InvitationResponse {
+	return dto.InvitationResponse{
+		ID:        invitation.ID,
+		ProjectID: invitation.ProjectID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		Status:    string(invitation.Status),
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
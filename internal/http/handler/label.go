@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type LabelHandler struct {
+	service *service.LabelService
+}
+
+func NewLabelHandler(service *service.LabelService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+// Create godoc
+// @Summary Create label
+// @Description Creates a label for the authenticated user. Label names are unique per user, case-insensitively.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateLabelRequest true "Label creation payload"
+// @Success 201 {object} dto.LabelResponse
+// @Failure 400 {string} string "invalid request or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 409 {string} string "label with this name already exists"
+// @Router /labels [post]
+func (h *LabelHandler) Create(c echo.Context) error {
+	var req dto.CreateLabelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	label, err := h.service.CreateLabel(c.Request().Context(), userID, req.Name, req.Color)
+	if err != nil {
+		return labelError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toLabelResponse(label))
+}
+
+// List godoc
+// @Summary List labels
+// @Description Returns the labels of the authenticated user ordered by name.
+// @Tags labels
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.LabelResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /labels [get]
+func (h *LabelHandler) List(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	labels, err := h.service.ListLabels(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, toLabelResponses(labels))
+}
+
+// Update godoc
+// @Summary Update label
+// @Description Renames or recolors a label. Tasks carrying the label get a new version.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Label ID" format(uuid)
+// @Param request body dto.UpdateLabelRequest true "Label update payload"
+// @Success 200 {object} dto.LabelResponse
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "label not found"
+// @Failure 409 {string} string "label with this name already exists"
+// @Router /labels/{id} [patch]
+func (h *LabelHandler) Update(c echo.Context) error {
+	labelID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.UpdateLabelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	label, err := h.service.UpdateLabel(c.Request().Context(), userID, labelID, req.Name, req.Color)
+	if err != nil {
+		return labelError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toLabelResponse(label))
+}
+
+// Delete godoc
+// @Summary Delete label
+// @Description Deletes a label and detaches it from every task.
+// @Tags labels
+// @Security BearerAuth
+// @Param id path string true "Label ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid label id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "label not found"
+// @Router /labels/{id} [delete]
+func (h *LabelHandler) Delete(c echo.Context) error {
+	labelID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.DeleteLabel(c.Request().Context(), userID, labelID); err != nil {
+		return labelError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func labelError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrLabelNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrLabelNameTaken):
+		return c.JSON(http.StatusConflict, err.Error())
+	default:
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+}
+
+func toLabelResponse(label domain.Label) dto.LabelResponse {
+	return dto.LabelResponse{
+		ID:        label.ID,
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt,
+	}
+}
+
+func toLabelResponses(labels []domain.Label) []dto.LabelResponse {
+	resp := make([]dto.LabelResponse, 0, len(labels))
+	for _, label := range labels {
+		resp = append(resp, toLabelResponse(label))
+	}
+
+	return resp
+}
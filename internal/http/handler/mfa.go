@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MFAHandler struct {
+	service *service.MFAService
+}
+
+func NewMFAHandler(service *service.MFAService) *MFAHandler {
+	return &MFAHandler{service: service}
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generates a TOTP secret for the authenticated user. It takes effect once confirmed with a first code; enrolling again before that replaces the secret.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MFAEnrollmentResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "user not found"
+// @Failure 409 {string} string "two-factor authentication already enabled"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	enrollment, err := h.service.Enroll(c.Request().Context(), userID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.MFAEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication with the first code from the authenticator app and returns single-use recovery codes. They are shown only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} dto.MFARecoveryCodesResponse
+// @Failure 400 {string} string "invalid request or invalid code"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "no enrollment started"
+// @Failure 409 {string} string "two-factor authentication already enabled"
+// @Failure 429 {string} string "too many failed codes; see the Retry-After header"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	codes, err := h.service.Confirm(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off and discards the recovery codes. Requires a current code from the authenticator app or an unused recovery code.
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param request body dto.MFACodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid request or invalid code"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "two-factor authentication not enabled"
+// @Failure 429 {string} string "too many failed codes; see the Retry-After header"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	if err := h.service.Disable(c.Request().Context(), userID, req.Code); err != nil {
+		return mfaError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func mfaError(c echo.Context, err error) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		return tooManyAttempts(c, throttled)
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, domain.ErrMFANotEnabled):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		return c.JSON(http.StatusConflict, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
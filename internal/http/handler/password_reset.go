@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	"taskflow/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PasswordResetHandler struct {
+	service *service.PasswordResetService
+}
+
+func NewPasswordResetHandler(service *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{service: service}
+}
+
+// Forgot godoc
+// @Summary Request password reset
+// @Description Mails a single-use password reset link to the account with the email. The response is the same whether or not such an account exists. Requests are throttled per email and client IP.
+// @Tags auth
+// @Accept json
+// @Param request body dto.ForgotPasswordRequest true "Account email"
+// @Success 202 "Accepted"
+// @Failure 400 {string} string "invalid request"
+// @Failure 429 {string} string "too many reset requests; see the Retry-After header"
+// @Router /auth/password/forgot [post]
+func (h *PasswordResetHandler) Forgot(c echo.Context) error {
+	var req dto.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	// The mail is sent in the background, and its failures never reach the
+	// caller, who could otherwise tell that the account exists.
+	err := h.service.Forgot(c.Request().Context(), req.Email, c.RealIP())
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		return tooManyAttempts(c, throttled)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// Reset godoc
+// @Summary Reset password
+// @Description Sets a new password with the token from a password reset mail. The token works once; every session of the user is signed out.
+// @Tags auth
+// @Accept json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid request or empty password"
+// @Failure 404 {string} string "password reset not found"
+// @Failure 409 {string} string "password reset already used"
+// @Failure 410 {string} string "password reset expired"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /auth/password/reset [post]
+func (h *PasswordResetHandler) Reset(c echo.Context) error {
+	var req dto.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	if err := h.service.Reset(c.Request().Context(), req.Token, req.Password); err != nil {
+		return passwordResetError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func passwordResetError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPasswordResetNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrPasswordResetUsed):
+		return c.JSON(http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrPasswordResetExpired):
+		return c.JSON(http.StatusGone, err.Error())
+	case errors.Is(err, domain.ErrEmptyPassword):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
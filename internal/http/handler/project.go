@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ProjectHandler struct {
+	service *service.ProjectService
+}
+
+func NewProjectHandler(service *service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
+// Create godoc
+// @Summary Create project
+// @Description Creates a shared project owned by the authenticated user, who becomes its first member.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateProjectRequest true "Project creation payload"
+// @Success 201 {object} dto.ProjectResponse
+// @Failure 400 {string} string "invalid request or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Router /projects [post]
+func (h *ProjectHandler) Create(c echo.Context) error {
+	var req dto.CreateProjectRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	project, err := h.service.CreateProject(c.Request().Context(), userID, req.Name)
+	if err != nil {
+		return projectError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toProjectResponse(project))
+}
+
+// List godoc
+// @Summary List projects
+// @Description Returns the projects the authenticated user is a member of, the personal project first.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.ProjectResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /projects [get]
+func (h *ProjectHandler) List(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	projects, err := h.service.ListProjects(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := make([]dto.ProjectResponse, 0, len(projects))
+	for _, project := range projects {
+		resp = append(resp, toProjectResponse(project))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get godoc
+// @Summary Get project
+// @Description Returns a project the authenticated user is a member of together with the user's role in it.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} dto.ProjectResponse
+// @Failure 400 {string} string "invalid project id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "project not found"
+// @Router /projects/{id} [get]
+func (h *ProjectHandler) Get(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	project, err := h.service.GetProject(c.Request().Context(), userID, projectID)
+	if err != nil {
+		return projectError(c, err)
+	}
+
+	resp := toProjectResponse(project)
+	if role, ok := middleware2.ProjectRoleFromContext(c); ok {
+		resp.Role = string(role)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Rename project
+// @Description Renames a project. Only the owner and admins may do it.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body dto.UpdateProjectRequest true "Project update payload"
+// @Success 200 {object} dto.ProjectResponse
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow changing the project, or insufficient scope"
+// @Failure 404 {string} string "project not found"
+// @Router /projects/{id} [patch]
+func (h *ProjectHandler) Update(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.UpdateProjectRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	project, err := h.service.RenameProject(c.Request().Context(), userID, projectID, req.Name)
+	if err != nil {
+		return projectError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+// Delete godoc
+// @Summary Delete project
+// @Description Deletes a shared project together with its tasks. Only the owner may do it; personal projects cannot be deleted.
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid project id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow deleting the project, or insufficient scope"
+// @Failure 404 {string} string "project not found"
+// @Failure 409 {string} string "personal projects cannot be deleted"
+// @Router /projects/{id} [delete]
+func (h *ProjectHandler) Delete(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.DeleteProject(c.Request().Context(), userID, projectID); err != nil {
+		return projectError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListMembers godoc
+// @Summary List project members
+// @Description Returns the members of a project with their roles in the order they joined.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {array} dto.ProjectMemberResponse
+// @Failure 400 {string} string "invalid project id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "project not found"
+// @Router /projects/{id}/members [get]
+func (h *ProjectHandler) ListMembers(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	members, err := h.service.ListMembers(c.Request().Context(), userID, projectID)
+	if err != nil {
+		return projectError(c, err)
+	}
+
+	resp := make([]dto.ProjectMemberResponse, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, toProjectMemberResponse(member))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// SetMember godoc
+// @Summary Add project member or change role
+// @Description Shares the project with another user or changes the role of an existing member. Owners and admins may grant only roles below their own; the role defaults to editor.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param userId path string true "User ID" format(uuid)
+// @Param request body dto.SetProjectMemberRequest false "Member role payload"
+// @Success 200 {object} dto.ProjectMemberResponse
+// @Failure 400 {string} string "invalid request, invalid id, or invalid role"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow granting this role, email not verified, or insufficient scope"
+// @Failure 404 {string} string "project or user not found"
+// @Failure 409 {string} string "personal project or owner role"
+// @Router /projects/{id}/members/{userId} [put]
+func (h *ProjectHandler) SetMember(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid user id")
+	}
+
+	var req dto.SetProjectMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	role := domain.RoleEditor
+	if req.Role != "" {
+		role = domain.ProjectRole(req.Role)
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	member, err := h.service.SetMember(c.Request().Context(), userID, projectID, memberID, role)
+	if err != nil {
+		return projectError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toProjectMemberResponse(member))
+}
+
+// RemoveMember godoc
+// @Summary Remove project member
+// @Description Removes a member from the project and unassigns them from its tasks. Owners and admins may remove members ranked below them; members may remove themselves to leave.
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID" format(uuid)
+// @Param userId path string true "User ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow removing the member, or insufficient scope"
+// @Failure 404 {string} string "project or member not found"
+// @Failure 409 {string} string "project owner cannot leave the project"
+// @Router /projects/{id}/members/{userId} [delete]
+func (h *ProjectHandler) RemoveMember(c echo.Context) error {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid user id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	if err := h.service.RemoveMember(c.Request().Context(), userID, projectID, memberID); err != nil {
+		return projectError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func projectError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrForbidden),
+		errors.Is(err, domain.ErrEmailNotVerified):
+		return c.JSON(http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrPersonalProject),
+		errors.Is(err, domain.ErrOwnerCannotLeave),
+		errors.Is(err, domain.ErrAlreadyProjectMember),
+		errors.Is(err, domain.ErrOwnerRoleFixed):
+		return c.JSON(http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrEmptyProjectName),
+		errors.Is(err, domain.ErrProjectNameTooLong),
+		errors.Is(err, domain.ErrInvalidProjectName),
+		errors.Is(err, domain.ErrInvalidProjectRole):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toProjectResponse(project domain.Project) dto.ProjectResponse {
+	return dto.ProjectResponse{
+		ID:        project.ID,
+		OwnerID:   project.OwnerID,
+		Name:      project.Name,
+		Personal:  project.Personal,
+		CreatedAt: project.CreatedAt,
+	}
+}
+
+func toProjectMemberResponse(member domain.ProjectMember) dto.ProjectMemberResponse {
+	return dto.ProjectMemberResponse{
+		UserID:   member.UserID,
+		Role:     string(member.Role),
+		JoinedAt: member.JoinedAt,
+	}
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	middleware2 "taskflow/internal/http/middleware"
 	"taskflow/internal/service"
 	"time"
@@ -33,7 +37,7 @@ func NewTaskHandler(taskService *service.TaskService, analytics service.Analytic
 
 // Create godoc
 // @Summary Create task
-// @Description Creates a task for the authenticated user.
+// @Description Creates a task in one of the user's projects, the personal project by default, optionally as a subtask of another task or as the first occurrence of a recurring series. Subtasks go to the project of their parent.
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -42,6 +46,7 @@ func NewTaskHandler(taskService *service.TaskService, analytics service.Analytic
 // @Success 201 {object} dto.TaskResponse
 // @Failure 400 {string} string "invalid request or validation error"
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow creating tasks in the project, or insufficient scope"
 // @Router /task [post]
 func (h *TaskHandler) Create(c echo.Context) error {
 	var req dto.CreateTaskRequest
@@ -55,12 +60,23 @@ func (h *TaskHandler) Create(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, "invalid auth context")
 	}
 
-	task, err := h.service.CreateTask(
-		c.Request().Context(),
-		userID,
-		req.Title,
-		req.Description,
-	)
+	input := service.CreateTaskInput{
+		Title:       req.Title,
+		Description: req.Description,
+		DueAt:       req.DueAt,
+		ProjectID:   req.ProjectID,
+		ParentID:    req.ParentID,
+		Recurrence:  req.Recurrence,
+	}
+	if req.Priority != "" {
+		priority := domain.Priority(req.Priority)
+		input.Priority = &priority
+	}
+
+	task, err := h.service.CreateTask(c.Request().Context(), userID, input)
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, err.Error())
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
@@ -77,14 +93,18 @@ func (h *TaskHandler) Create(c echo.Context) error {
 
 // Get godoc
 // @Summary Get task by ID
-// @Description Returns a single task that belongs to the authenticated user. The service checks Redis before querying PostgreSQL.
+// @Description Returns a single task from a project the authenticated user is a member of. The service checks Redis before querying PostgreSQL. The task version is returned as an ETag and honoured in If-None-Match.
 // @Tags tasks
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Task ID" format(uuid)
+// @Param If-None-Match header string false "ETag of a previously fetched version"
 // @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "Task version"
+// @Success 304 "Not Modified"
 // @Failure 400 {string} string "invalid task id"
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
 // @Failure 404 {string} string "task not found"
 // @Router /task/{id} [get]
 func (h *TaskHandler) Get(c echo.Context) error {
@@ -109,21 +129,33 @@ func (h *TaskHandler) Get(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, "task not found")
 	}
 
+	etag := taskETag(task)
+	c.Response().Header().Set(headerETag, etag)
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
 	return c.JSON(http.StatusOK, toResponse(task))
 }
 
 // ChangeStatus godoc
 // @Summary Change task status
-// @Description Updates the status of a task that belongs to the authenticated user.
+// @Description Moves a task from one of the authenticated user's projects to another status of the project owner's workflow, along one of its transitions. A task with open subtasks can only be moved to a done status with cascade, which completes the subtasks as well. Completing a recurring task creates its next occurrence.
 // @Tags tasks
 // @Accept json
 // @Security BearerAuth
 // @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param If-None-Match header string false "Apply only if the task does not have this ETag"
 // @Param request body dto.ChangeStatusRequest true "Status update payload"
 // @Success 204 "No Content"
-// @Failure 400 {string} string "invalid request, invalid id, or invalid transition"
+// @Header 204 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, unknown status, or transition not allowed by the workflow"
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
 // @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task is blocked, has open subtasks, or was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
 // @Router /tasks/{id}/status [patch]
 func (h *TaskHandler) ChangeStatus(c echo.Context) error {
 	idParam := c.Param("id")
@@ -145,37 +177,500 @@ func (h *TaskHandler) ChangeStatus(c echo.Context) error {
 
 	status := domain.NormalizeStatus(domain.Status(req.Status))
 
-	if err := h.service.ChangeStatus(
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	var (
+		task      domain.Task
+		completed []uuid.UUID
+	)
+	if req.Cascade {
+		task, completed, err = h.service.CompleteWithSubtasks(
+			c.Request().Context(),
+			userID,
+			taskID,
+			status,
+			expectedVersion,
+		)
+	} else {
+		task, err = h.service.ChangeStatus(
+			c.Request().Context(),
+			userID,
+			taskID,
+			status,
+			expectedVersion,
+		)
+	}
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	if task.IsDone() {
+		for _, id := range append(completed, taskID) {
+			event := service.TaskEvent{
+				Type:      service.TaskEventCompleted,
+				UserID:    userID,
+				TaskID:    id,
+				CreatedAt: time.Now().UTC(),
+			}
+			if id == taskID {
+				event.AssigneeID = task.AssigneeID
+			}
+			_ = h.analytics.PublishTaskEvent(c.Request().Context(), event)
+		}
+	}
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Reopen godoc
+// @Summary Reopen task
+// @Description Moves a done task back to an open status of the project owner's workflow, the initial status when none is given. The completion counted for the task in analytics is taken back.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.ReopenTaskRequest false "Status to reopen the task in"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, or status that is not open"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task is not done, is blocked, or was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/reopen [post]
+func (h *TaskHandler) Reopen(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.ReopenTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := h.service.ReopenTask(
 		c.Request().Context(),
 		userID,
 		taskID,
-		status,
-	); err != nil {
+		domain.Status(req.Status),
+		expectedVersion,
+	)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
+}
+
+// Restore godoc
+// @Summary Restore task
+// @Description Moves a canceled task back to the initial status of the project owner's workflow.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task is not canceled or was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/restore [post]
+func (h *TaskHandler) Restore(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := h.service.RestoreTask(c.Request().Context(), userID, taskID, expectedVersion)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
+		Type:      service.TaskEventUpdated,
+		UserID:    userID,
+		TaskID:    taskID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
+}
+
+// Update godoc
+// @Summary Update task
+// @Description Partially updates a task from one of the authenticated user's projects. Accepts a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document over title, description, status, priority, and due_at; all changes are validated and stored together.
+// @Tags tasks
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param If-None-Match header string false "Apply only if the task does not have this ETag"
+// @Param request body dto.UpdateTaskRequest true "Merge patch document, or an array of JSON Patch operations"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, invalid patch, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "patch test operation failed or task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Failure 415 {string} string "unsupported patch media type"
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) Update(c echo.Context) error {
+	idParam := c.Param("id")
+
+	taskID, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return c.JSON(http.StatusUnsupportedMediaType, "unsupported patch media type")
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	current, err := h.service.GetTask(c.Request().Context(), userID, taskID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, "task not found")
+	}
+	if !writePreconditionHolds(c, current) {
+		return c.JSON(http.StatusPreconditionFailed, service.ErrPreconditionFailed.Error())
+	}
+
+	var req dto.UpdateTaskRequest
+	switch mediaType {
+	case mimeMergePatch, echo.MIMEApplicationJSON:
+		req, err = decodeMergePatch(body)
+	case mimeJSONPatch:
+		req, err = applyJSONPatch(body, current)
+	default:
+		return c.JSON(http.StatusUnsupportedMediaType, "unsupported patch media type")
+	}
+	if errors.Is(err, errPatchTestFailed) {
+		return c.JSON(http.StatusConflict, err.Error())
+	}
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	if status == domain.StatusDone {
+	patch, err := toTaskPatch(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	// The patch was computed against current, so it may only be applied to
+	// that exact version.
+	task, err := h.service.PatchTask(c.Request().Context(), userID, taskID, patch, &current.Version)
+	if errors.Is(err, service.ErrPreconditionFailed) {
+		err = domain.ErrVersionConflict
+	}
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
+		Type:      service.TaskEventUpdated,
+		UserID:    userID,
+		TaskID:    taskID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	if !current.IsDone() && task.IsDone() {
 		_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
-			Type:      service.TaskEventCompleted,
-			UserID:    userID,
-			TaskID:    taskID,
-			CreatedAt: time.Now().UTC(),
+			Type:       service.TaskEventCompleted,
+			UserID:     userID,
+			TaskID:     taskID,
+			AssigneeID: task.AssigneeID,
+			CreatedAt:  time.Now().UTC(),
 		})
 	}
 
-	return c.NoContent(http.StatusNoContent)
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
+}
+
+// AttachLabel godoc
+// @Summary Attach label to task
+// @Description Puts one of the user's labels on a task. Attaching a label twice is a no-op.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param labelId path string true "Label ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid task or label id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task or label not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/labels/{labelId} [put]
+func (h *TaskHandler) AttachLabel(c echo.Context) error {
+	return h.changeRelation(c, "labelId", "invalid label id", h.service.AttachLabel)
+}
+
+// DetachLabel godoc
+// @Summary Detach label from task
+// @Description Takes a label off a task. Detaching a label the task does not carry is a no-op.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param labelId path string true "Label ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid task or label id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task or label not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/labels/{labelId} [delete]
+func (h *TaskHandler) DetachLabel(c echo.Context) error {
+	return h.changeRelation(c, "labelId", "invalid label id", h.service.DetachLabel)
+}
+
+// AddBlocker godoc
+// @Summary Add blocker
+// @Description Makes a task wait for another task of the user. The task cannot move to in_progress or done while any blocker is open. Dependencies that would form a cycle are rejected.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param blockerId path string true "Blocking task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid task or blocker id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task or blocking task not found"
+// @Failure 409 {string} string "dependency would create a cycle or task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/blockers/{blockerId} [put]
+func (h *TaskHandler) AddBlocker(c echo.Context) error {
+	return h.changeRelation(c, "blockerId", "invalid blocker id", h.service.AddBlocker)
+}
+
+// RemoveBlocker godoc
+// @Summary Remove blocker
+// @Description Drops a dependency of a task. Removing a blocker the task does not have is a no-op.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param blockerId path string true "Blocking task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid task or blocker id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/blockers/{blockerId} [delete]
+func (h *TaskHandler) RemoveBlocker(c echo.Context) error {
+	return h.changeRelation(c, "blockerId", "invalid blocker id", h.service.RemoveBlocker)
+}
+
+// changeRelation links or unlinks the task and the row named by the otherParam
+// path parameter.
+func (h *TaskHandler) changeRelation(
+	c echo.Context,
+	otherParam, invalidOther string,
+	change func(ctx context.Context, userID, taskID, otherID uuid.UUID, expectedVersion *int64) (domain.Task, error),
+) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	otherID, err := uuid.Parse(c.Param(otherParam))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, invalidOther)
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := change(c.Request().Context(), userID, taskID, otherID, expectedVersion)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
+}
+
+// ListSubtasks godoc
+// @Summary List subtasks
+// @Description Returns the direct subtasks of a task, oldest first.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {array} dto.TaskResponse
+// @Failure 400 {string} string "invalid task id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) ListSubtasks(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	tasks, err := h.service.ListSubtasks(c.Request().Context(), userID, taskID)
+	if errors.Is(err, service.ErrTaskNotFound) {
+		return c.JSON(http.StatusNotFound, "task not found")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := make([]dto.TaskResponse, 0, len(tasks))
+	for _, t := range tasks {
+		resp = append(resp, toResponse(t))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Move godoc
+// @Summary Move task
+// @Description Makes a task a subtask of another task, or a top level task when parent_id is null. Moving a task under itself or one of its subtasks, or past the maximum hierarchy depth, is rejected.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.MoveTaskRequest true "New parent"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, unknown parent, cycle, or hierarchy too deep"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/parent [put]
+func (h *TaskHandler) Move(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.MoveTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := h.service.MoveTask(c.Request().Context(), userID, taskID, req.ParentID, expectedVersion)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
+		Type:      service.TaskEventUpdated,
+		UserID:    userID,
+		TaskID:    taskID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
 }
 
 // Delete godoc
 // @Summary Delete task
-// @Description Deletes a task that belongs to the authenticated user.
+// @Description Deletes a task from one of the authenticated user's projects together with all of its subtasks.
 // @Tags tasks
 // @Security BearerAuth
 // @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Delete only if the task still has this ETag"
+// @Param If-None-Match header string false "Delete only if the task does not have this ETag"
 // @Success 204 "No Content"
 // @Failure 400 {string} string "invalid task id"
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
 // @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
 // @Router /tasks/{id} [delete]
 func (h *TaskHandler) Delete(c echo.Context) error {
 	idParam := c.Param("id")
@@ -190,11 +685,13 @@ func (h *TaskHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, "invalid auth context")
 	}
 
-	if err := h.service.DeleteTask(c.Request().Context(), userID, taskID); err != nil {
-		if errors.Is(err, service.ErrTaskNotFound) {
-			return c.JSON(http.StatusNotFound, "task not found")
-		}
-		return c.JSON(http.StatusBadRequest, err.Error())
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	if err := h.service.DeleteTask(c.Request().Context(), userID, taskID, expectedVersion); err != nil {
+		return taskWriteError(c, err)
 	}
 
 	_ = h.analytics.PublishTaskEvent(c.Request().Context(), service.TaskEvent{
@@ -209,18 +706,33 @@ func (h *TaskHandler) Delete(c echo.Context) error {
 
 // List godoc
 // @Summary List tasks
-// @Description Returns tasks for the authenticated user with optional pagination, filtering, search, and sorting.
+// @Description Returns the tasks of every project the authenticated user is a member of, with optional pagination, filtering, search, and sorting.
 // @Tags tasks
 // @Produce json
 // @Security BearerAuth
 // @Param limit query int false "Maximum number of tasks to return"
 // @Param offset query int false "Pagination offset"
-// @Param status query string false "Task status filter" Enums(pending,in_progress,done,canceled)
+// @Param project_id query string false "Only tasks of this project" format(uuid)
+// @Param assignee query string false "Only tasks assigned to the caller (me), to the user with this ID, or to nobody (none)"
+// @Param status query string false "Workflow status key filter"
+// @Param status_category query string false "Status category filter" Enums(todo,in_progress,done,canceled)
+// @Param priority query string false "Task priority filter" Enums(low,medium,high,urgent)
 // @Param search query string false "Case-insensitive title search"
-// @Param sort_by query string false "Sort column"
+// @Param due_before query string false "Only tasks due before this RFC 3339 timestamp" format(date-time)
+// @Param due_after query string false "Only tasks due after this RFC 3339 timestamp" format(date-time)
+// @Param overdue query bool false "Only open tasks past their due date (true) or all others (false)"
+// @Param blocked query bool false "Only tasks waiting for an open blocker (true) or all others (false)"
+// @Param labels_any query string false "Comma-separated label IDs; tasks carrying at least one of them"
+// @Param labels_all query string false "Comma-separated label IDs; tasks carrying all of them"
+// @Param sort_by query string false "Sort column; status sorts by status category" Enums(created_at,title,status,completed_at,priority,due_at)
 // @Param sort_dir query string false "Sort direction" Enums(asc,desc)
+// @Param If-None-Match header string false "ETag of a previously fetched page"
 // @Success 200 {array} dto.TaskResponse
+// @Header 200 {string} ETag "Weak validator over ids and versions of the page"
+// @Success 304 "Not Modified"
+// @Failure 400 {string} string "invalid filter"
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
 // @Failure 500 {string} string "unexpected server error"
 // @Router /tasks [get]
 func (h *TaskHandler) List(c echo.Context) error {
@@ -251,11 +763,103 @@ func (h *TaskHandler) List(c echo.Context) error {
 		filter.Status = &s
 	}
 
+	// status category
+	if project := c.QueryParam("project_id"); project != "" {
+		projectID, err := uuid.Parse(project)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "invalid project_id")
+		}
+		filter.ProjectID = &projectID
+	}
+
+	// assignee
+	if assignee := c.QueryParam("assignee"); assignee != "" {
+		switch assignee {
+		case "me":
+			filter.AssigneeID = &userID
+		case "none":
+			filter.Unassigned = true
+		default:
+			assigneeID, err := uuid.Parse(assignee)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, "invalid assignee")
+			}
+			filter.AssigneeID = &assigneeID
+		}
+	}
+
+	if category := c.QueryParam("status_category"); category != "" {
+		sc := domain.StatusCategory(category)
+		if !sc.IsValid() {
+			return c.JSON(http.StatusBadRequest, "invalid status_category")
+		}
+		filter.Category = &sc
+	}
+
+	// priority
+	if priority := c.QueryParam("priority"); priority != "" {
+		p := domain.Priority(priority)
+		if !p.IsValid() {
+			return c.JSON(http.StatusBadRequest, domain.ErrInvalidPriority.Error())
+		}
+		filter.Priority = &p
+	}
+
 	// search
 	if search := c.QueryParam("search"); search != "" {
 		filter.Search = &search
 	}
 
+	// due dates
+	for param, target := range map[string]**time.Time{
+		"due_before": &filter.DueBefore,
+		"due_after":  &filter.DueAfter,
+	} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "invalid "+param)
+		}
+		*target = &v
+	}
+
+	// overdue
+	if overdue := c.QueryParam("overdue"); overdue != "" {
+		v, err := strconv.ParseBool(overdue)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "invalid overdue")
+		}
+		filter.Overdue = &v
+	}
+
+	// blocked
+	if blocked := c.QueryParam("blocked"); blocked != "" {
+		v, err := strconv.ParseBool(blocked)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "invalid blocked")
+		}
+		filter.Blocked = &v
+	}
+
+	// labels
+	for param, target := range map[string]*[]uuid.UUID{
+		"labels_any": &filter.LabelsAny,
+		"labels_all": &filter.LabelsAll,
+	} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		ids, err := parseIDList(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "invalid "+param)
+		}
+		*target = ids
+	}
+
 	filter.SortBy = c.QueryParam("sort_by")
 	filter.SortDir = c.QueryParam("sort_dir")
 
@@ -268,6 +872,12 @@ func (h *TaskHandler) List(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	etag := taskListETag(tasks)
+	c.Response().Header().Set(headerETag, etag)
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
 	resp := make([]dto.TaskResponse, 0, len(tasks))
 	for _, t := range tasks {
 		resp = append(resp, toResponse(t))
@@ -278,11 +888,108 @@ func (h *TaskHandler) List(c echo.Context) error {
 
 func toResponse(t domain.Task) dto.TaskResponse {
 	return dto.TaskResponse{
-		ID:          t.ID,
-		Title:       t.Title,
-		Description: t.Description,
-		Status:      string(t.Status),
-		CreatedAt:   t.CreatedAt,
-		CompletedAt: t.CompletedAt,
+		ID:                t.ID,
+		ProjectID:         t.ProjectID,
+		CreatedBy:         t.UserID,
+		AssigneeID:        t.AssigneeID,
+		Title:             t.Title,
+		Description:       t.Description,
+		Status:            string(t.Status),
+		StatusCategory:    string(t.Category),
+		Priority:          string(t.Priority),
+		DueAt:             t.DueAt,
+		Overdue:           t.IsOverdue(time.Now()),
+		CreatedAt:         t.CreatedAt,
+		CompletedAt:       t.CompletedAt,
+		Labels:            toLabelResponses(t.Labels),
+		ParentID:          t.ParentID,
+		SeriesID:          t.SeriesID,
+		Progress:          toProgressResponse(t.Subtasks),
+		Blocked:           t.IsBlocked(),
+		BlockedBy:         toBlockerResponses(t.BlockedBy),
+		Checklist:         toChecklistResponses(t.Checklist),
+		ChecklistProgress: toChecklistProgressResponse(t.ChecklistProgress),
+		Version:           t.Version,
+	}
+}
+
+func toBlockerResponses(blockers []domain.Blocker) []dto.TaskBlockerResponse {
+	resp := make([]dto.TaskBlockerResponse, 0, len(blockers))
+	for _, b := range blockers {
+		resp = append(resp, dto.TaskBlockerResponse{
+			ID:             b.ID,
+			Status:         string(b.Status),
+			StatusCategory: string(b.Category),
+		})
+	}
+
+	return resp
+}
+
+func toProgressResponse(p domain.SubtaskProgress) *dto.TaskProgressResponse {
+	if p.Total == 0 {
+		return nil
+	}
+
+	return &dto.TaskProgressResponse{Done: p.Done, Total: p.Total}
+}
+
+func parseIDList(value string) ([]uuid.UUID, error) {
+	parts := strings.Split(value, ",")
+	ids := make([]uuid.UUID, 0, len(parts))
+	for _, part := range parts {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// expectedVersion turns If-Match / If-None-Match on a write into the version
+// the service has to find in storage, so the check and the write agree.
+func (h *TaskHandler) expectedVersion(c echo.Context, userID, taskID uuid.UUID) (*int64, error) {
+	if !hasWritePrecondition(c) {
+		return nil, nil
+	}
+
+	current, err := h.service.GetTask(c.Request().Context(), userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !writePreconditionHolds(c, current) {
+		return nil, service.ErrPreconditionFailed
+	}
+
+	return &current.Version, nil
+}
+
+func taskWriteError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		return c.JSON(http.StatusNotFound, "task not found")
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrNotRecurring):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrAssigneeNotMember):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrLabelNotFound),
+		errors.Is(err, service.ErrBlockerNotFound),
+		errors.Is(err, domain.ErrChecklistItemNotFound):
+		return c.JSON(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return c.JSON(http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, domain.ErrVersionConflict),
+		errors.Is(err, domain.ErrOpenSubtasks),
+		errors.Is(err, domain.ErrBlocked),
+		errors.Is(err, domain.ErrDependencyCycle),
+		errors.Is(err, domain.ErrNotDone),
+		errors.Is(err, domain.ErrNotCanceled):
+		return c.JSON(http.StatusConflict, err.Error())
+	default:
+		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 }
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	middleware2 "taskflow/internal/http/middleware"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+)
+
+// Assign godoc
+// @Summary Assign task
+// @Description Assigns a task to a member of its project and publishes a task_assigned event. Assigning the current assignee again is a no-op.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.AssignTaskRequest true "Assignee"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, or assignee is not a project member"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/assignee [put]
+func (h *TaskHandler) Assign(c echo.Context) error {
+	var req dto.AssignTaskRequest
+	if err := c.Bind(&req); err != nil || req.AssigneeID == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	return h.changeAssignee(c, func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error) {
+		return h.service.AssignTask(ctx, userID, taskID, req.AssigneeID, expectedVersion)
+	})
+}
+
+// Unassign godoc
+// @Summary Unassign task
+// @Description Leaves a task without an assignee. Unassigning a task nobody is assigned to is a no-op.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/assignee [delete]
+func (h *TaskHandler) Unassign(c echo.Context) error {
+	return h.changeAssignee(c, h.service.UnassignTask)
+}
+
+// changeAssignee runs an assignment change against the task in the id path
+// parameter and responds with the updated task.
+func (h *TaskHandler) changeAssignee(
+	c echo.Context,
+	change func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error),
+) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := change(c.Request().Context(), userID, taskID, expectedVersion)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(http.StatusOK, toResponse(task))
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+)
+
+// AddChecklistItem godoc
+// @Summary Add checklist item
+// @Description Appends an unchecked item to the checklist of a task.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.AddChecklistItemRequest true "Checklist item"
+// @Success 201 {object} dto.TaskResponse
+// @Header 201 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, validation error, or checklist full"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/checklist [post]
+func (h *TaskHandler) AddChecklistItem(c echo.Context) error {
+	var req dto.AddChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	return h.changeChecklist(c, http.StatusCreated, func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error) {
+		return h.service.AddChecklistItem(ctx, userID, taskID, req.Text, expectedVersion)
+	})
+}
+
+// UpdateChecklistItem godoc
+// @Summary Update checklist item
+// @Description Checks or unchecks a checklist item or changes its text.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param itemId path string true "Checklist item ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.UpdateChecklistItemRequest true "Checklist item changes"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task or checklist item not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/checklist/{itemId} [patch]
+func (h *TaskHandler) UpdateChecklistItem(c echo.Context) error {
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid checklist item id")
+	}
+
+	var req dto.UpdateChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	return h.changeChecklist(c, http.StatusOK, func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error) {
+		return h.service.UpdateChecklistItem(ctx, userID, taskID, itemID, service.ChecklistItemPatch{
+			Text:    req.Text,
+			Checked: req.Checked,
+		}, expectedVersion)
+	})
+}
+
+// RemoveChecklistItem godoc
+// @Summary Remove checklist item
+// @Description Deletes an item from the checklist of a task; the items after it move up.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param itemId path string true "Checklist item ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid task or checklist item id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task or checklist item not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/checklist/{itemId} [delete]
+func (h *TaskHandler) RemoveChecklistItem(c echo.Context) error {
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid checklist item id")
+	}
+
+	return h.changeChecklist(c, http.StatusOK, func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error) {
+		return h.service.RemoveChecklistItem(ctx, userID, taskID, itemID, expectedVersion)
+	})
+}
+
+// ReorderChecklist godoc
+// @Summary Reorder checklist
+// @Description Puts the checklist items of a task in a new order. item_ids must list every item exactly once.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param If-Match header string false "Apply only if the task still has this ETag"
+// @Param request body dto.ReorderChecklistRequest true "New order"
+// @Success 200 {object} dto.TaskResponse
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {string} string "invalid request, invalid id, or incomplete order"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "task was modified concurrently"
+// @Failure 412 {string} string "task version does not match"
+// @Router /tasks/{id}/checklist/order [put]
+func (h *TaskHandler) ReorderChecklist(c echo.Context) error {
+	var req dto.ReorderChecklistRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	return h.changeChecklist(c, http.StatusOK, func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error) {
+		return h.service.ReorderChecklist(ctx, userID, taskID, req.ItemIDs, expectedVersion)
+	})
+}
+
+// changeChecklist runs a checklist change against the task in the id path
+// parameter and responds with the updated task.
+func (h *TaskHandler) changeChecklist(
+	c echo.Context,
+	status int,
+	change func(ctx context.Context, userID, taskID uuid.UUID, expectedVersion *int64) (domain.Task, error),
+) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	expectedVersion, err := h.expectedVersion(c, userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	task, err := change(c.Request().Context(), userID, taskID, expectedVersion)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(status, toResponse(task))
+}
+
+func toChecklistResponses(items []domain.ChecklistItem) []dto.ChecklistItemResponse {
+	if len(items) == 0 {
+		return nil
+	}
+
+	resp := make([]dto.ChecklistItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, dto.ChecklistItemResponse{
+			ID:       item.ID,
+			Text:     item.Text,
+			Checked:  item.Checked,
+			Position: item.Position,
+		})
+	}
+
+	return resp
+}
+
+func toChecklistProgressResponse(p domain.ChecklistProgress) *dto.TaskProgressResponse {
+	if p.Total == 0 {
+		return nil
+	}
+
+	return &dto.TaskProgressResponse{Done: p.Done, Total: p.Total}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	"taskflow/internal/service"
+	"time"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	errInvalidPatch    = errors.New("invalid patch document")
+	errPatchTestFailed = errors.New("patch test operation failed")
+)
+
+// patchableTaskFields lists the task members a patch document may change.
+var patchableTaskFields = []string{"title", "description", "status", "priority", "due_at"}
+
+// decodeMergePatch turns an RFC 7396 document into an update request.
+// A null member resets the field to its empty value and lets the domain
+// decide whether that is allowed.
+func decodeMergePatch(body []byte) (dto.UpdateTaskRequest, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return dto.UpdateTaskRequest{}, errInvalidPatch
+	}
+
+	var req dto.UpdateTaskRequest
+	for field, raw := range doc {
+		value, err := decodePatchValue(raw)
+		if err != nil {
+			return dto.UpdateTaskRequest{}, err
+		}
+
+		if err := setPatchField(&req, field, value); err != nil {
+			return dto.UpdateTaskRequest{}, err
+		}
+	}
+
+	return req, nil
+}
+
+// applyJSONPatch runs RFC 6902 operations against the current task and
+// returns only the fields whose values ended up different.
+func applyJSONPatch(body []byte, task domain.Task) (dto.UpdateTaskRequest, error) {
+	var ops []dto.JSONPatchOperation
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return dto.UpdateTaskRequest{}, errInvalidPatch
+	}
+
+	original := taskPatchDocument(task)
+	doc := make(map[string]string, len(original))
+	for field, value := range original {
+		doc[field] = value
+	}
+
+	for _, op := range ops {
+		field, err := patchField(op.Path)
+		if err != nil {
+			return dto.UpdateTaskRequest{}, err
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if len(op.Value) == 0 {
+				return dto.UpdateTaskRequest{}, fmt.Errorf("%w: %s requires a value", errInvalidPatch, op.Op)
+			}
+			value, err := decodePatchValue(op.Value)
+			if err != nil {
+				return dto.UpdateTaskRequest{}, err
+			}
+			doc[field] = value
+		case "remove":
+			doc[field] = ""
+		case "test":
+			value, err := decodePatchValue(op.Value)
+			if err != nil {
+				return dto.UpdateTaskRequest{}, err
+			}
+			if doc[field] != value {
+				return dto.UpdateTaskRequest{}, errPatchTestFailed
+			}
+		case "copy", "move":
+			from, err := patchField(op.From)
+			if err != nil {
+				return dto.UpdateTaskRequest{}, err
+			}
+			value := doc[from]
+			if op.Op == "move" {
+				doc[from] = ""
+			}
+			doc[field] = value
+		default:
+			return dto.UpdateTaskRequest{}, fmt.Errorf("%w: unsupported operation %q", errInvalidPatch, op.Op)
+		}
+	}
+
+	var req dto.UpdateTaskRequest
+	for _, field := range patchableTaskFields {
+		if doc[field] == original[field] {
+			continue
+		}
+		if err := setPatchField(&req, field, doc[field]); err != nil {
+			return dto.UpdateTaskRequest{}, err
+		}
+	}
+
+	return req, nil
+}
+
+// toTaskPatch validates transport-level formats and builds the service patch.
+func toTaskPatch(req dto.UpdateTaskRequest) (service.TaskPatch, error) {
+	patch := service.TaskPatch{
+		Title:       req.Title,
+		Description: req.Description,
+	}
+	if req.Status != nil {
+		status := domain.NormalizeStatus(domain.Status(*req.Status))
+		patch.Status = &status
+	}
+	if req.Priority != nil {
+		priority := domain.Priority(*req.Priority)
+		patch.Priority = &priority
+	}
+	if req.DueAt != nil {
+		if *req.DueAt == "" {
+			patch.ClearDueAt = true
+		} else {
+			dueAt, err := time.Parse(time.RFC3339, *req.DueAt)
+			if err != nil {
+				return service.TaskPatch{}, fmt.Errorf("%w: due_at must be an RFC 3339 timestamp", errInvalidPatch)
+			}
+			patch.DueAt = &dueAt
+		}
+	}
+
+	return patch, nil
+}
+
+func taskPatchDocument(task domain.Task) map[string]string {
+	doc := map[string]string{
+		"title":       task.Title,
+		"description": task.Description,
+		"status":      string(task.Status),
+		"priority":    string(task.Priority),
+		"due_at":      "",
+	}
+	if task.DueAt != nil {
+		doc["due_at"] = task.DueAt.UTC().Format(time.RFC3339)
+	}
+
+	return doc
+}
+
+func setPatchField(req *dto.UpdateTaskRequest, field, value string) error {
+	switch field {
+	case "title":
+		req.Title = &value
+	case "description":
+		req.Description = &value
+	case "status":
+		req.Status = &value
+	case "priority":
+		req.Priority = &value
+	case "due_at":
+		req.DueAt = &value
+	default:
+		return fmt.Errorf("%w: unsupported field %q", errInvalidPatch, field)
+	}
+
+	return nil
+}
+
+func patchField(path string) (string, error) {
+	for _, field := range patchableTaskFields {
+		if path == "/"+field {
+			return field, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unsupported path %q", errInvalidPatch, path)
+}
+
+func decodePatchValue(raw json.RawMessage) (string, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%w: values must be strings", errInvalidPatch)
+	}
+	if value == nil {
+		return "", nil
+	}
+
+	return *value, nil
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+)
+
+// GetSeries godoc
+// @Summary Get task series
+// @Description Returns the recurring series a task belongs to.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 400 {string} string "invalid task id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 404 {string} string "task not found or task is not recurring"
+// @Router /tasks/{id}/series [get]
+func (h *TaskHandler) GetSeries(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	series, err := h.service.GetSeries(c.Request().Context(), userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+// UpdateSeries godoc
+// @Summary Update task series
+// @Description Edits the whole recurring series a task belongs to. Title, description and priority apply to the open occurrences and to the ones created later; a new recurrence rule is anchored to the newest occurrence. Use PATCH /tasks/{id} to edit a single occurrence.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Param request body dto.UpdateSeriesRequest true "Series changes"
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 400 {string} string "invalid request, invalid id, or validation error"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found or task is not recurring"
+// @Router /tasks/{id}/series [patch]
+func (h *TaskHandler) UpdateSeries(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.UpdateSeriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	patch := service.SeriesPatch{
+		Title:       req.Title,
+		Description: req.Description,
+		Rule:        req.Recurrence,
+	}
+	if req.Priority != nil {
+		priority := domain.Priority(*req.Priority)
+		patch.Priority = &priority
+	}
+
+	series, err := h.service.UpdateSeries(c.Request().Context(), userID, taskID, patch)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+// StopSeries godoc
+// @Summary Stop task recurrence
+// @Description Stops the recurring series a task belongs to. Existing occurrences are kept and no new ones are created.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID" format(uuid)
+// @Success 200 {object} dto.SeriesResponse
+// @Failure 400 {string} string "invalid task id"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "role does not allow editing tasks, or insufficient scope"
+// @Failure 404 {string} string "task not found or task is not recurring"
+// @Router /tasks/{id}/series [delete]
+func (h *TaskHandler) StopSeries(c echo.Context) error {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid id")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	series, err := h.service.StopSeries(c.Request().Context(), userID, taskID)
+	if err != nil {
+		return taskWriteError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSeriesResponse(series))
+}
+
+func toSeriesResponse(s domain.TaskSeries) dto.SeriesResponse {
+	return dto.SeriesResponse{
+		ID:          s.ID,
+		Recurrence:  s.Rule.String(),
+		Title:       s.Title,
+		Description: s.Description,
+		Priority:    string(s.Priority),
+		StartsAt:    s.StartsAt,
+		LastDueAt:   s.LastDueAt,
+		Active:      s.IsActive(),
+		StoppedAt:   s.StoppedAt,
+		CreatedAt:   s.CreatedAt,
+	}
+}
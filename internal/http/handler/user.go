@@ -50,6 +50,7 @@ func (h *UserHandler) Create(c echo.Context) error {
 // @Security BearerAuth
 // @Success 200 {object} dto.UserResponse
 // @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
 // @Failure 404 {string} string "user not found"
 // @Router /me [get]
 func (h *UserHandler) Me(c echo.Context) error {
@@ -68,8 +69,9 @@ func (h *UserHandler) Me(c echo.Context) error {
 
 func toUserResponse(user domain.User) dto.UserResponse {
 	return dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+		ID:              user.ID,
+		Email:           user.Email,
+		CreatedAt:       user.CreatedAt,
+		EmailVerifiedAt: user.EmailVerifiedAt,
 	}
 }
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/http/dto"
+	middleware2 "taskflow/internal/http/middleware"
+	"taskflow/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WorkflowHandler struct {
+	service *service.WorkflowService
+}
+
+func NewWorkflowHandler(service *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{service: service}
+}
+
+// Get godoc
+// @Summary Get workflow
+// @Description Returns the statuses and transitions the authenticated user's tasks move through; the default workflow until the user defines one.
+// @Tags workflow
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 500 {string} string "unexpected server error"
+// @Router /workflow [get]
+func (h *WorkflowHandler) Get(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	workflow, err := h.service.GetWorkflow(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, toWorkflowResponse(workflow))
+}
+
+// Replace godoc
+// @Summary Replace workflow
+// @Description Defines the authenticated user's own statuses and allowed transitions. Statuses that tasks are in have to be kept with their category.
+// @Tags workflow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReplaceWorkflowRequest true "Workflow definition"
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 400 {string} string "invalid request or invalid workflow"
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 409 {string} string "a removed or recategorized status is used by tasks"
+// @Router /workflow [put]
+func (h *WorkflowHandler) Replace(c echo.Context) error {
+	var req dto.ReplaceWorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, "invalid request")
+	}
+
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	statuses := make([]domain.WorkflowStatus, 0, len(req.Statuses))
+	for _, s := range req.Statuses {
+		statuses = append(statuses, domain.WorkflowStatus{
+			Key:      domain.Status(s.Key),
+			Name:     s.Name,
+			Category: domain.StatusCategory(s.Category),
+		})
+	}
+
+	transitions := make([]domain.Transition, 0, len(req.Transitions))
+	for _, t := range req.Transitions {
+		transitions = append(transitions, domain.Transition{
+			From: domain.Status(t.From),
+			To:   domain.Status(t.To),
+		})
+	}
+
+	workflow, err := h.service.ReplaceWorkflow(c.Request().Context(), userID, statuses, transitions)
+	if err != nil {
+		return workflowError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toWorkflowResponse(workflow))
+}
+
+// Reset godoc
+// @Summary Reset workflow
+// @Description Drops the authenticated user's own workflow so the default one applies again. Tasks have to be in default statuses first.
+// @Tags workflow
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 401 {string} string "missing or invalid token"
+// @Failure 403 {string} string "insufficient scope"
+// @Failure 409 {string} string "a custom status is used by tasks"
+// @Router /workflow [delete]
+func (h *WorkflowHandler) Reset(c echo.Context) error {
+	userID, ok := middleware2.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, "invalid auth context")
+	}
+
+	workflow, err := h.service.ResetWorkflow(c.Request().Context(), userID)
+	if err != nil {
+		return workflowError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toWorkflowResponse(workflow))
+}
+
+func workflowError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrStatusInUse):
+		return c.JSON(http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidWorkflow):
+		return c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toWorkflowResponse(workflow domain.Workflow) dto.WorkflowResponse {
+	resp := dto.WorkflowResponse{
+		Default:     workflow.IsDefault(),
+		Statuses:    make([]dto.WorkflowStatusDTO, 0, len(workflow.Statuses)),
+		Transitions: make([]dto.WorkflowTransitionDTO, 0, len(workflow.Transitions)),
+	}
+
+	for _, s := range workflow.Statuses {
+		resp.Statuses = append(resp.Statuses, dto.WorkflowStatusDTO{
+			Key:      string(s.Key),
+			Name:     s.Name,
+			Category: string(s.Category),
+		})
+	}
+
+	for _, t := range workflow.Transitions {
+		resp.Transitions = append(resp.Transitions, dto.WorkflowTransitionDTO{
+			From: string(t.From),
+			To:   string(t.To),
+		})
+	}
+
+	return resp
+}
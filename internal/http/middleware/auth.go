@@ -1,34 +1,106 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"taskflow/internal/domain"
 	"taskflow/internal/service"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func AuthMiddleware(tokenService *service.TokenService) echo.MiddlewareFunc {
+// AuthMiddleware accepts bearer access tokens that are signed, currently valid,
+// issued by and for this deployment, and not on the denylist, as well as
+// personal API keys that are neither expired nor revoked. Rejections carry a
+// WWW-Authenticate header saying why.
+func AuthMiddleware(
+	tokenService *service.TokenService,
+	denylist service.TokenDenylist,
+	apiKeys *service.APIKeyService,
+) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
 			if !strings.HasPrefix(authHeader, "Bearer ") {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
 				return c.JSON(http.StatusUnauthorized, "missing bearer token")
 			}
 
 			token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-			userID, err := tokenService.Parse(token)
+			if service.IsAPIKey(token) {
+				key, err := apiKeys.Authenticate(c.Request().Context(), token)
+				if err != nil {
+					return invalidToken(c, apiKeyErrorDescription(err))
+				}
+
+				c.Set("userID", key.UserID)
+				c.Set("apiKey", key)
+				c.Set("scopes", key.Scopes)
+				return next(c)
+			}
+
+			claims, err := tokenService.ParseClaims(token)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, "invalid token")
+				return invalidToken(c, tokenErrorDescription(err))
 			}
 
-			c.Set("userID", userID)
+			denied, err := denylist.IsDenied(c.Request().Context(), claims)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, "token check failed")
+			}
+			if denied {
+				return invalidToken(c, "token revoked")
+			}
+
+			c.Set("userID", claims.Subject)
+			c.Set("tokenClaims", claims)
+			c.Set("scopes", claims.Scopes)
 			return next(c)
 		}
 	}
 }
 
+// invalidToken answers with the RFC 6750 invalid_token error, whose
+// description tells the client why the token was rejected.
+func invalidToken(c echo.Context, description string) error {
+	c.Response().Header().Set(
+		echo.HeaderWWWAuthenticate,
+		fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, description),
+	)
+	return c.JSON(http.StatusUnauthorized, description)
+}
+
+func tokenErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, service.ErrTokenExpired):
+		return "token expired"
+	case errors.Is(err, service.ErrTokenNotYetValid):
+		return "token not yet valid"
+	case errors.Is(err, service.ErrTokenIssuedInFuture):
+		return "token issued in the future"
+	case errors.Is(err, service.ErrInvalidTokenIssuer):
+		return "token issued by another issuer"
+	case errors.Is(err, service.ErrInvalidTokenAudience):
+		return "token issued for another audience"
+	default:
+		return "invalid token"
+	}
+}
+
+func apiKeyErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyExpired):
+		return "api key expired"
+	case errors.Is(err, domain.ErrAPIKeyRevoked):
+		return "api key revoked"
+	default:
+		return "invalid api key"
+	}
+}
+
 func UserIDFromContext(c echo.Context) (uuid.UUID, bool) {
 	userID, ok := c.Get("userID").(uuid.UUID)
 	if !ok || userID == uuid.Nil {
@@ -37,3 +109,24 @@ func UserIDFromContext(c echo.Context) (uuid.UUID, bool) {
 
 	return userID, true
 }
+
+// APIKeyFromContext returns the API key the request was authenticated with.
+// It reports false for requests made with an access token.
+func APIKeyFromContext(c echo.Context) (domain.APIKey, bool) {
+	key, ok := c.Get("apiKey").(domain.APIKey)
+	return key, ok
+}
+
+// ScopesFromContext returns the scopes granted by the access token or API key
+// the request was authenticated with.
+func ScopesFromContext(c echo.Context) ([]domain.Scope, bool) {
+	scopes, ok := c.Get("scopes").([]domain.Scope)
+	return scopes, ok
+}
+
+// TokenClaimsFromContext returns the claims of the access token the request
+// was authenticated with.
+func TokenClaimsFromContext(c echo.Context) (service.TokenClaims, bool) {
+	claims, ok := c.Get("tokenClaims").(service.TokenClaims)
+	return claims, ok
+}
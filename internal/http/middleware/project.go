@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/domain"
+	"taskflow/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ProjectRoleMiddleware resolves the caller's role in the project named by the
+// :id route parameter. It runs after AuthMiddleware; projects the caller is
+// not a member of are reported as missing. Permissions are still checked by
+// the services.
+func ProjectRoleMiddleware(projects *service.ProjectService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			projectID, err := uuid.Parse(c.Param("id"))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, "invalid id")
+			}
+
+			userID, ok := UserIDFromContext(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, "invalid auth context")
+			}
+
+			role, err := projects.MemberRole(c.Request().Context(), userID, projectID)
+			if errors.Is(err, service.ErrProjectNotFound) {
+				return c.JSON(http.StatusNotFound, err.Error())
+			}
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+
+			c.Set("projectRole", role)
+			return next(c)
+		}
+	}
+}
+
+func ProjectRoleFromContext(c echo.Context) (domain.ProjectRole, bool) {
+	role, ok := c.Get("projectRole").(domain.ProjectRole)
+	if !ok || !role.IsValid() {
+		return "", false
+	}
+
+	return role, true
+}
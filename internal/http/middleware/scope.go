@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"taskflow/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireScopes lets through requests whose credential grants every scope.
+// It runs after AuthMiddleware and answers others with the RFC 6750
+// insufficient_scope error, naming the scopes the route needs.
+func RequireScopes(scopes ...domain.Scope) echo.MiddlewareFunc {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	required := strings.Join(names, " ")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, _ := ScopesFromContext(c)
+			if !domain.HasScopes(granted, scopes...) {
+				c.Response().Header().Set(
+					echo.HeaderWWWAuthenticate,
+					fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, required),
+				)
+				return c.JSON(http.StatusForbidden, "insufficient_scope")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -37,5 +37,35 @@ func (r *Repository) GetByUserID(ctx context.Context, userID uuid.UUID) (domain.
 		return domain.TaskAnalytics{}, err
 	}
 
+	analytics.Assignees, err = r.assignees(ctx, userID)
+	if err != nil {
+		return domain.TaskAnalytics{}, err
+	}
+
 	return analytics, nil
 }
+
+// assignees returns the breakdown by assignee, most assigned first.
+func (r *Repository) assignees(ctx context.Context, userID uuid.UUID) ([]domain.AssigneeAnalytics, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT assignee_id, tasks_assigned, tasks_completed
+		FROM task_assignee_analytics
+		WHERE user_id = $1
+		ORDER BY tasks_assigned DESC, tasks_completed DESC, assignee_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.AssigneeAnalytics{}
+	for rows.Next() {
+		var a domain.AssigneeAnalytics
+		if err := rows.Scan(&a.AssigneeID, &a.TasksAssigned, &a.TasksCompleted); err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,167 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// lastUsedPrecision bounds how often a key in constant use is written to.
+const lastUsedPrecision = time.Minute
+
+type APIKeyModel struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	SecretHash string     `db:"secret_hash"`
+	Scopes     []string   `db:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+var columns = []string{
+	"id",
+	"user_id",
+	"name",
+	"prefix",
+	"secret_hash",
+	"scopes",
+	"expires_at",
+	"last_used_at",
+	"created_at",
+	"revoked_at",
+}
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key domain.APIKey) error {
+	m := toModel(key)
+	query, args, err := sq.
+		Insert("api_keys").
+		Columns("id", "user_id", "name", "prefix", "secret_hash", "scopes", "expires_at", "created_at").
+		Values(m.ID, m.UserID, m.Name, m.Prefix, m.SecretHash, m.Scopes, m.ExpiresAt, m.CreatedAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	query, args, err := sq.
+		Select(columns...).
+		From("api_keys").
+		Where(sq.Eq{"prefix": prefix}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	m, err := scanAPIKey(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIKey{}, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	return toDomain(m), nil
+}
+
+// List returns the user's keys that have not been revoked, newest first.
+func (r *APIKeyRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	query, args, err := sq.
+		Select(columns...).
+		From("api_keys").
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		OrderBy("created_at desc").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.APIKey{}
+	for rows.Next() {
+		m, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, toDomain(m))
+	}
+
+	return result, rows.Err()
+}
+
+// Revoke revokes the user's key unless it already is revoked.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID, revokedAt time.Time) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE api_keys
+		SET revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID, revokedAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Touch records when the key was used. Uses within lastUsedPrecision of the
+// recorded one are not written.
+func (r *APIKeyRepository) Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, id, usedAt, usedAt.Add(-lastUsedPrecision))
+	return err
+}
+
+func scanAPIKey(row pgx.Row) (APIKeyModel, error) {
+	var m APIKeyModel
+	err := row.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.Name,
+		&m.Prefix,
+		&m.SecretHash,
+		&m.Scopes,
+		&m.ExpiresAt,
+		&m.LastUsedAt,
+		&m.CreatedAt,
+		&m.RevokedAt,
+	)
+	return m, err
+}
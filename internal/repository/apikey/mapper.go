@@ -0,0 +1,45 @@
+package apikey
+
+import "taskflow/internal/domain"
+
+func toModel(k domain.APIKey) APIKeyModel {
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, scope := range k.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return APIKeyModel{
+		ID:         k.ID,
+		UserID:     k.UserID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		SecretHash: k.SecretHash,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+// toDomain keeps scopes as stored, even ones no longer known, so that they
+// show up in listings instead of failing them.
+func toDomain(m APIKeyModel) domain.APIKey {
+	scopes := make([]domain.Scope, 0, len(m.Scopes))
+	for _, scope := range m.Scopes {
+		scopes = append(scopes, domain.Scope(scope))
+	}
+
+	return domain.APIKey{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		SecretHash: m.SecretHash,
+		Scopes:     scopes,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		CreatedAt:  m.CreatedAt,
+		RevokedAt:  m.RevokedAt,
+	}
+}
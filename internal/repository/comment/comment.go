@@ -0,0 +1,175 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+type CommentModel struct {
+	ID        uuid.UUID  `db:"id"`
+	TaskID    uuid.UUID  `db:"task_id"`
+	AuthorID  uuid.UUID  `db:"author_id"`
+	Body      string     `db:"body"`
+	CreatedAt time.Time  `db:"created_at"`
+	EditedAt  *time.Time `db:"edited_at"`
+}
+
+var commentColumns = []string{"id", "task_id", "author_id", "body", "created_at", "edited_at"}
+
+type CommentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommentRepository(db *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment domain.Comment) (domain.Comment, error) {
+	m := toModel(comment)
+
+	query, args, err := sq.
+		Insert("task_comments").
+		Columns("id", "task_id", "author_id", "body").
+		Values(m.ID, m.TaskID, m.AuthorID, m.Body).
+		Suffix("RETURNING " + strings.Join(commentColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	created, err := scanComment(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return toDomain(created)
+}
+
+func (r *CommentRepository) Get(ctx context.Context, id, taskID uuid.UUID) (domain.Comment, error) {
+	query, args, err := sq.
+		Select(commentColumns...).
+		From("task_comments").
+		Where(sq.Eq{"id": id, "task_id": taskID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	m, err := scanComment(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return toDomain(m)
+}
+
+// List returns up to filter.Limit comments of the task in thread order,
+// starting after filter.After. Ties on created_at are broken by id so the
+// cursor never skips or repeats a comment.
+func (r *CommentRepository) List(ctx context.Context, filter domain.CommentFilter) ([]domain.Comment, error) {
+	builder := sq.
+		Select(commentColumns...).
+		From("task_comments").
+		Where(sq.Eq{"task_id": filter.TaskID}).
+		OrderBy("created_at asc", "id asc").
+		Limit(uint64(filter.Limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if filter.After != nil {
+		builder = builder.Where(sq.Expr("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.ID))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Comment{}
+	for rows.Next() {
+		m, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		comment, err := toDomain(m)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, comment)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment domain.Comment) (domain.Comment, error) {
+	m := toModel(comment)
+
+	query, args, err := sq.
+		Update("task_comments").
+		Set("body", m.Body).
+		Set("edited_at", m.EditedAt).
+		Where(sq.Eq{"id": m.ID, "task_id": m.TaskID}).
+		Suffix("RETURNING " + strings.Join(commentColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	updated, err := scanComment(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return toDomain(updated)
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id, taskID uuid.UUID) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM task_comments WHERE id = $1 AND task_id = $2`, id, taskID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func scanComment(row pgx.Row) (CommentModel, error) {
+	var m CommentModel
+	err := row.Scan(
+		&m.ID,
+		&m.TaskID,
+		&m.AuthorID,
+		&m.Body,
+		&m.CreatedAt,
+		&m.EditedAt,
+	)
+	return m, err
+}
@@ -0,0 +1,25 @@
+package comment
+
+import "taskflow/internal/domain"
+
+func toModel(c domain.Comment) CommentModel {
+	return CommentModel{
+		ID:        c.ID,
+		TaskID:    c.TaskID,
+		AuthorID:  c.AuthorID,
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+	}
+}
+
+func toDomain(m CommentModel) (domain.Comment, error) {
+	return domain.NewCommentFromStorage(
+		m.ID,
+		m.TaskID,
+		m.AuthorID,
+		m.Body,
+		m.CreatedAt,
+		m.EditedAt,
+	)
+}
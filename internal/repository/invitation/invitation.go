@@ -0,0 +1,173 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+type InvitationModel struct {
+	ID         uuid.UUID  `db:"id"`
+	ProjectID  uuid.UUID  `db:"project_id"`
+	InviterID  uuid.UUID  `db:"inviter_id"`
+	Email      string     `db:"email"`
+	Role       string     `db:"role"`
+	Status     string     `db:"status"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	AnsweredAt *time.Time `db:"answered_at"`
+}
+
+var invitationColumns = []string{
+	"id",
+	"project_id",
+	"inviter_id",
+	"email",
+	"role",
+	"status",
+	"expires_at",
+	"created_at",
+	"answered_at",
+}
+
+type InvitationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, invitation domain.Invitation) (domain.Invitation, error) {
+	m := toModel(invitation)
+
+	query, args, err := sq.
+		Insert("project_invitations").
+		Columns("id", "project_id", "inviter_id", "email", "role", "status", "expires_at", "created_at").
+		Values(m.ID, m.ProjectID, m.InviterID, m.Email, m.Role, m.Status, m.ExpiresAt, m.CreatedAt).
+		Suffix("RETURNING id, project_id, inviter_id, email, role, status, expires_at, created_at, answered_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	created, err := scanInvitation(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return toDomain(created), nil
+}
+
+func (r *InvitationRepository) Get(ctx context.Context, id uuid.UUID) (domain.Invitation, error) {
+	query, args, err := sq.
+		Select(invitationColumns...).
+		From("project_invitations").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	m, err := scanInvitation(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Invitation{}, ErrInvitationNotFound
+	}
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return toDomain(m), nil
+}
+
+// Accept stores the answer and the membership in one transaction, so a token
+// used twice at the same time adds the member once.
+func (r *InvitationRepository) Accept(ctx context.Context, invitation domain.Invitation, member domain.ProjectMember) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := answer(ctx, tx, toModel(invitation)); err != nil {
+			return err
+		}
+
+		return addMember(ctx, tx, member)
+	})
+}
+
+func (r *InvitationRepository) AcceptAsNewUser(
+	ctx context.Context,
+	invitation domain.Invitation,
+	user domain.User,
+	member domain.ProjectMember,
+) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO users (id, email, password_hash, email_verified_at)
+			VALUES ($1, $2, $3, $4)
+		`, user.ID, user.Email, user.PasswordHash, user.EmailVerifiedAt)
+		if err != nil {
+			return err
+		}
+
+		if err := answer(ctx, tx, toModel(invitation)); err != nil {
+			return err
+		}
+
+		return addMember(ctx, tx, member)
+	})
+}
+
+func (r *InvitationRepository) Decline(ctx context.Context, invitation domain.Invitation) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return answer(ctx, tx, toModel(invitation))
+	})
+}
+
+func addMember(ctx context.Context, tx pgx.Tx, member domain.ProjectMember) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO project_members (project_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`, member.ProjectID, member.UserID, string(member.Role), member.JoinedAt)
+	return err
+}
+
+// answer moves a pending invitation to its final status.
+func answer(ctx context.Context, tx pgx.Tx, m InvitationModel) error {
+	res, err := tx.Exec(ctx, `
+		UPDATE project_invitations
+		SET status = $2, answered_at = $3
+		WHERE id = $1 AND status = 'pending'
+	`, m.ID, m.Status, m.AnsweredAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrInvitationAnswered
+	}
+
+	return nil
+}
+
+func scanInvitation(row pgx.Row) (InvitationModel, error) {
+	var m InvitationModel
+	err := row.Scan(
+		&m.ID,
+		&m.ProjectID,
+		&m.InviterID,
+		&m.Email,
+		&m.Role,
+		&m.Status,
+		&m.ExpiresAt,
+		&m.CreatedAt,
+		&m.AnsweredAt,
+	)
+	return m, err
+}
@@ -0,0 +1,31 @@
+package invitation
+
+import "taskflow/internal/domain"
+
+func toModel(i domain.Invitation) InvitationModel {
+	return InvitationModel{
+		ID:         i.ID,
+		ProjectID:  i.ProjectID,
+		InviterID:  i.InviterID,
+		Email:      i.Email,
+		Role:       string(i.Role),
+		Status:     string(i.Status),
+		ExpiresAt:  i.ExpiresAt,
+		CreatedAt:  i.CreatedAt,
+		AnsweredAt: i.AnsweredAt,
+	}
+}
+
+func toDomain(m InvitationModel) domain.Invitation {
+	return domain.Invitation{
+		ID:         m.ID,
+		ProjectID:  m.ProjectID,
+		InviterID:  m.InviterID,
+		Email:      m.Email,
+		Role:       domain.ProjectRole(m.Role),
+		Status:     domain.InvitationStatus(m.Status),
+		ExpiresAt:  m.ExpiresAt,
+		CreatedAt:  m.CreatedAt,
+		AnsweredAt: m.AnsweredAt,
+	}
+}
@@ -0,0 +1,238 @@
+package label
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrLabelNotFound = errors.New("label not found")
+
+const uniqueViolation = "23505"
+
+type LabelModel struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Name      string    `db:"name"`
+	Color     string    `db:"color"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type LabelRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLabelRepository(db *pgxpool.Pool) *LabelRepository {
+	return &LabelRepository{db: db}
+}
+
+func (r *LabelRepository) Create(ctx context.Context, label domain.Label) (domain.Label, error) {
+	m := toModel(label)
+
+	query, args, err := sq.
+		Insert("labels").
+		Columns("id", "user_id", "name", "color").
+		Values(m.ID, m.UserID, m.Name, m.Color).
+		Suffix("RETURNING id, user_id, name, color, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	created, err := scanLabel(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return domain.Label{}, mapWriteError(err)
+	}
+
+	return toDomain(created)
+}
+
+func (r *LabelRepository) Get(ctx context.Context, id, userID uuid.UUID) (domain.Label, error) {
+	query, args, err := sq.
+		Select("id", "user_id", "name", "color", "created_at").
+		From("labels").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	m, err := scanLabel(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Label{}, ErrLabelNotFound
+	}
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	return toDomain(m)
+}
+
+func (r *LabelRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.Label, error) {
+	query, args, err := sq.
+		Select("id", "user_id", "name", "color", "created_at").
+		From("labels").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("lower(name) asc").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Label{}
+	for rows.Next() {
+		m, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		label, err := toDomain(m)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, label)
+	}
+
+	return result, rows.Err()
+}
+
+// Update renames or recolors a label and bumps the version of every task that
+// carries it, since those tasks are rendered with the label embedded. It
+// returns the new versions of the touched tasks.
+func (r *LabelRepository) Update(ctx context.Context, label domain.Label) (domain.Label, map[uuid.UUID]int64, error) {
+	m := toModel(label)
+
+	var (
+		updated LabelModel
+		touched map[uuid.UUID]int64
+	)
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query, args, err := sq.
+			Update("labels").
+			Set("name", m.Name).
+			Set("color", m.Color).
+			Where(sq.Eq{"id": m.ID, "user_id": m.UserID}).
+			Suffix("RETURNING id, user_id, name, color, created_at").
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		updated, err = scanLabel(tx.QueryRow(ctx, query, args...))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLabelNotFound
+		}
+		if err != nil {
+			return mapWriteError(err)
+		}
+
+		touched, err = touchLabelledTasks(ctx, tx, m.ID)
+		return err
+	})
+	if err != nil {
+		return domain.Label{}, nil, err
+	}
+
+	result, err := toDomain(updated)
+	if err != nil {
+		return domain.Label{}, nil, err
+	}
+
+	return result, touched, nil
+}
+
+// Delete removes a label together with its task links and returns the new
+// versions of the tasks that lost it.
+func (r *LabelRepository) Delete(ctx context.Context, id, userID uuid.UUID) (map[uuid.UUID]int64, error) {
+	var touched map[uuid.UUID]int64
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		touched, err = touchLabelledTasks(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(ctx, `DELETE FROM labels WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrLabelNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return touched, nil
+}
+
+func touchLabelledTasks(ctx context.Context, tx pgx.Tx, labelID uuid.UUID) (map[uuid.UUID]int64, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE tasks
+		SET version = version + 1
+		WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = $1)
+		RETURNING id, version
+	`, labelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	touched := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var (
+			id      uuid.UUID
+			version int64
+		)
+		if err := rows.Scan(&id, &version); err != nil {
+			return nil, err
+		}
+		touched[id] = version
+	}
+
+	return touched, rows.Err()
+}
+
+func scanLabel(row pgx.Row) (LabelModel, error) {
+	var m LabelModel
+	err := row.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.Name,
+		&m.Color,
+		&m.CreatedAt,
+	)
+	return m, err
+}
+
+func mapWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrLabelNameTaken
+	}
+
+	return err
+}
@@ -0,0 +1,23 @@
+package label
+
+import "taskflow/internal/domain"
+
+func toModel(l domain.Label) LabelModel {
+	return LabelModel{
+		ID:        l.ID,
+		UserID:    l.UserID,
+		Name:      l.Name,
+		Color:     l.Color,
+		CreatedAt: l.CreatedAt,
+	}
+}
+
+func toDomain(m LabelModel) (domain.Label, error) {
+	return domain.NewLabelFromStorage(
+		m.ID,
+		m.UserID,
+		m.Name,
+		m.Color,
+		m.CreatedAt,
+	)
+}
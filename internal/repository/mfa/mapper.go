@@ -0,0 +1,23 @@
+package mfa
+
+import "taskflow/internal/domain"
+
+func toModel(m domain.MFA) MFAModel {
+	return MFAModel{
+		UserID:       m.UserID,
+		Secret:       m.Secret,
+		ConfirmedAt:  m.ConfirmedAt,
+		LastUsedStep: m.LastUsedStep,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+func toDomain(m MFAModel) domain.MFA {
+	return domain.MFA{
+		UserID:       m.UserID,
+		Secret:       m.Secret,
+		ConfirmedAt:  m.ConfirmedAt,
+		LastUsedStep: m.LastUsedStep,
+		CreatedAt:    m.CreatedAt,
+	}
+}
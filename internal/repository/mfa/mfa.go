@@ -0,0 +1,173 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MFAModel struct {
+	UserID       uuid.UUID  `db:"user_id"`
+	Secret       string     `db:"secret"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// Get returns the user's enrollment, or domain.ErrMFANotEnabled when the user
+// has none.
+func (r *MFARepository) Get(ctx context.Context, userID uuid.UUID) (domain.MFA, error) {
+	query, args, err := sq.
+		Select("user_id", "secret", "confirmed_at", "last_used_step", "created_at").
+		From("user_mfa").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.MFA{}, err
+	}
+
+	var m MFAModel
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&m.UserID,
+		&m.Secret,
+		&m.ConfirmedAt,
+		&m.LastUsedStep,
+		&m.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.MFA{}, domain.ErrMFANotEnabled
+	}
+	if err != nil {
+		return domain.MFA{}, err
+	}
+
+	return toDomain(m), nil
+}
+
+// Save stores a new enrollment in place of an unconfirmed one. A confirmed
+// enrollment is kept and domain.ErrMFAAlreadyEnabled returned.
+func (r *MFARepository) Save(ctx context.Context, mfa domain.MFA) error {
+	m := toModel(mfa)
+	res, err := r.db.Exec(ctx, `
+		INSERT INTO user_mfa (user_id, secret, confirmed_at, last_used_step, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+			confirmed_at = EXCLUDED.confirmed_at,
+			last_used_step = EXCLUDED.last_used_step,
+			created_at = EXCLUDED.created_at
+		WHERE user_mfa.confirmed_at IS NULL
+	`, m.UserID, m.Secret, m.ConfirmedAt, m.LastUsedStep, m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// Enable confirms the enrollment, provided it is still the unconfirmed one
+// with the same secret, and replaces the user's recovery codes.
+func (r *MFARepository) Enable(ctx context.Context, mfa domain.MFA, codes []domain.MFARecoveryCode) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, `
+			UPDATE user_mfa
+			SET confirmed_at = $3, last_used_step = $4
+			WHERE user_id = $1 AND secret = $2 AND confirmed_at IS NULL
+		`, mfa.UserID, mfa.Secret, mfa.ConfirmedAt, mfa.LastUsedStep)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return domain.ErrMFAAlreadyEnabled
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, mfa.UserID); err != nil {
+			return err
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		insert := sq.
+			Insert("mfa_recovery_codes").
+			Columns("id", "user_id", "code_hash").
+			PlaceholderFormat(sq.Dollar)
+		for _, code := range codes {
+			insert = insert.Values(code.ID, code.UserID, code.CodeHash)
+		}
+
+		query, args, err := insert.ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+// UseStep records the time step of an accepted code unless a code for the
+// same or a later step has been accepted first.
+func (r *MFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks the user's unused recovery code with the hash used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash, usedAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// Delete removes the enrollment together with its recovery codes.
+func (r *MFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+		return err
+	})
+}
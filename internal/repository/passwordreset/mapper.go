@@ -0,0 +1,25 @@
+package passwordreset
+
+import "taskflow/internal/domain"
+
+func toModel(r domain.PasswordReset) PasswordResetModel {
+	return PasswordResetModel{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		TokenHash: r.TokenHash,
+		ExpiresAt: r.ExpiresAt,
+		CreatedAt: r.CreatedAt,
+		UsedAt:    r.UsedAt,
+	}
+}
+
+func toDomain(m PasswordResetModel) domain.PasswordReset {
+	return domain.PasswordReset{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+		UsedAt:    m.UsedAt,
+	}
+}
@@ -0,0 +1,111 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrPasswordResetNotFound = errors.New("password reset not found")
+
+type PasswordResetModel struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+type PasswordResetRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, reset domain.PasswordReset) error {
+	m := toModel(reset)
+	query, args, err := sq.
+		Insert("password_resets").
+		Columns("id", "user_id", "token_hash", "expires_at", "created_at").
+		Values(m.ID, m.UserID, m.TokenHash, m.ExpiresAt, m.CreatedAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *PasswordResetRepository) GetByHash(ctx context.Context, tokenHash string) (domain.PasswordReset, error) {
+	query, args, err := sq.
+		Select("id", "user_id", "token_hash", "expires_at", "created_at", "used_at").
+		From("password_resets").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+
+	var m PasswordResetModel
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&m.ID,
+		&m.UserID,
+		&m.TokenHash,
+		&m.ExpiresAt,
+		&m.CreatedAt,
+		&m.UsedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.PasswordReset{}, ErrPasswordResetNotFound
+	}
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+
+	return toDomain(m), nil
+}
+
+// Use marks the reset used only if nobody has used it first, sets the new
+// password hash and voids the user's other outstanding resets.
+func (r *PasswordResetRepository) Use(ctx context.Context, reset domain.PasswordReset, passwordHash string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, `
+			UPDATE password_resets
+			SET used_at = $2
+			WHERE id = $1 AND used_at IS NULL
+		`, reset.ID, reset.UsedAt)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return domain.ErrPasswordResetUsed
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE users
+			SET password_hash = $2
+			WHERE id = $1
+		`, reset.UserID, passwordHash); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE password_resets
+			SET used_at = $2
+			WHERE user_id = $1 AND used_at IS NULL
+		`, reset.UserID, reset.UsedAt)
+		return err
+	})
+}
@@ -0,0 +1,41 @@
+package project
+
+import "taskflow/internal/domain"
+
+func toModel(p domain.Project) ProjectModel {
+	return ProjectModel{
+		ID:        p.ID,
+		OwnerID:   p.OwnerID,
+		Name:      p.Name,
+		Personal:  p.Personal,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func toDomain(m ProjectModel) (domain.Project, error) {
+	return domain.NewProjectFromStorage(
+		m.ID,
+		m.OwnerID,
+		m.Name,
+		m.Personal,
+		m.CreatedAt,
+	)
+}
+
+func memberToModel(m domain.ProjectMember) MemberModel {
+	return MemberModel{
+		ProjectID: m.ProjectID,
+		UserID:    m.UserID,
+		Role:      string(m.Role),
+		JoinedAt:  m.JoinedAt,
+	}
+}
+
+func memberToDomain(m MemberModel) domain.ProjectMember {
+	return domain.ProjectMember{
+		ProjectID: m.ProjectID,
+		UserID:    m.UserID,
+		Role:      domain.ProjectRole(m.Role),
+		JoinedAt:  m.JoinedAt,
+	}
+}
@@ -0,0 +1,333 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrMemberNotFound  = errors.New("project member not found")
+)
+
+type ProjectModel struct {
+	ID        uuid.UUID `db:"id"`
+	OwnerID   uuid.UUID `db:"owner_id"`
+	Name      string    `db:"name"`
+	Personal  bool      `db:"personal"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type MemberModel struct {
+	ProjectID uuid.UUID `db:"project_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Role      string    `db:"role"`
+	JoinedAt  time.Time `db:"joined_at"`
+}
+
+var projectColumns = []string{"id", "owner_id", "name", "personal", "created_at"}
+
+type ProjectRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProjectRepository(db *pgxpool.Pool) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Create stores the project and makes its owner the first member.
+func (r *ProjectRepository) Create(ctx context.Context, project domain.Project) (domain.Project, error) {
+	m := toModel(project)
+
+	var created ProjectModel
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query, args, err := sq.
+			Insert("projects").
+			Columns("id", "owner_id", "name", "personal").
+			Values(m.ID, m.OwnerID, m.Name, m.Personal).
+			Suffix("RETURNING id, owner_id, name, personal, created_at").
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		created, err = scanProject(tx.QueryRow(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO project_members (project_id, user_id, role, joined_at)
+			VALUES ($1, $2, 'owner', $3)
+		`, created.ID, created.OwnerID, created.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	return toDomain(created)
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, id uuid.UUID) (domain.Project, error) {
+	query, args, err := sq.
+		Select(projectColumns...).
+		From("projects").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	m, err := scanProject(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Project{}, ErrProjectNotFound
+	}
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	return toDomain(m)
+}
+
+// Personal returns the personal project of the user. Users registered after
+// the projects migration get theirs on first use.
+func (r *ProjectRepository) Personal(ctx context.Context, userID uuid.UUID) (domain.Project, error) {
+	project, err := domain.NewPersonalProject(userID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	m := toModel(project)
+
+	var personal ProjectModel
+
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO projects (id, owner_id, name, personal)
+			VALUES ($1, $2, $3, true)
+			ON CONFLICT (owner_id) WHERE personal DO NOTHING
+		`, m.ID, m.OwnerID, m.Name); err != nil {
+			return err
+		}
+
+		personal, err = scanProject(tx.QueryRow(ctx, `
+			SELECT id, owner_id, name, personal, created_at
+			FROM projects
+			WHERE owner_id = $1 AND personal
+		`, userID))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO project_members (project_id, user_id, role, joined_at)
+			VALUES ($1, $2, 'owner', $3)
+			ON CONFLICT DO NOTHING
+		`, personal.ID, personal.OwnerID, personal.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	return toDomain(personal)
+}
+
+// ListByMember returns the projects the user is a member of, the personal one
+// first.
+func (r *ProjectRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]domain.Project, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.owner_id, p.name, p.personal, p.created_at
+		FROM projects p
+		JOIN project_members m ON m.project_id = p.id
+		WHERE m.user_id = $1
+		ORDER BY p.personal DESC, lower(p.name), p.created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Project{}
+	for rows.Next() {
+		m, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		project, err := toDomain(m)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, project)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project domain.Project) (domain.Project, error) {
+	m := toModel(project)
+
+	query, args, err := sq.
+		Update("projects").
+		Set("name", m.Name).
+		Where(sq.Eq{"id": m.ID}).
+		Suffix("RETURNING id, owner_id, name, personal, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	updated, err := scanProject(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Project{}, ErrProjectNotFound
+	}
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	return toDomain(updated)
+}
+
+// Delete removes the project; its tasks and memberships go with it.
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) Member(ctx context.Context, projectID, userID uuid.UUID) (domain.ProjectMember, error) {
+	var m MemberModel
+
+	err := r.db.QueryRow(ctx, `
+		SELECT project_id, user_id, role, joined_at
+		FROM project_members
+		WHERE project_id = $1 AND user_id = $2
+	`, projectID, userID).Scan(&m.ProjectID, &m.UserID, &m.Role, &m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ProjectMember{}, ErrMemberNotFound
+	}
+	if err != nil {
+		return domain.ProjectMember{}, err
+	}
+
+	return memberToDomain(m), nil
+}
+
+// ListMembers returns the members of the project in the order they joined.
+func (r *ProjectRepository) ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT project_id, user_id, role, joined_at
+		FROM project_members
+		WHERE project_id = $1
+		ORDER BY joined_at, user_id
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := pgx.CollectRows(rows, pgx.RowToStructByName[MemberModel])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.ProjectMember, 0, len(models))
+	for _, m := range models {
+		result = append(result, memberToDomain(m))
+	}
+
+	return result, nil
+}
+
+// SaveMember stores a membership, replacing the role of an existing one.
+func (r *ProjectRepository) SaveMember(ctx context.Context, member domain.ProjectMember) (domain.ProjectMember, error) {
+	m := memberToModel(member)
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO project_members (project_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`, m.ProjectID, m.UserID, m.Role, m.JoinedAt)
+	if err != nil {
+		return domain.ProjectMember{}, err
+	}
+
+	return r.Member(ctx, member.ProjectID, member.UserID)
+}
+
+// RemoveMember takes the user out of the project and unassigns them from its
+// tasks. The new versions of those tasks are returned.
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) (map[uuid.UUID]int64, error) {
+	unassigned := make(map[uuid.UUID]int64)
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, `
+			DELETE FROM project_members WHERE project_id = $1 AND user_id = $2
+		`, projectID, userID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrMemberNotFound
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE tasks
+			SET assignee_id = NULL, version = version + 1
+			WHERE project_id = $1 AND assignee_id = $2
+			RETURNING id, version
+		`, projectID, userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				id      uuid.UUID
+				version int64
+			)
+			if err := rows.Scan(&id, &version); err != nil {
+				return err
+			}
+			unassigned[id] = version
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unassigned, nil
+}
+
+func scanProject(row pgx.Row) (ProjectModel, error) {
+	var m ProjectModel
+	err := row.Scan(
+		&m.ID,
+		&m.OwnerID,
+		&m.Name,
+		&m.Personal,
+		&m.CreatedAt,
+	)
+	return m, err
+}
@@ -0,0 +1,29 @@
+package refreshtoken
+
+import "taskflow/internal/domain"
+
+func toModel(t domain.RefreshToken) RefreshTokenModel {
+	return RefreshTokenModel{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		RotatedAt: t.RotatedAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+func toDomain(m RefreshTokenModel) domain.RefreshToken {
+	return domain.RefreshToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		FamilyID:  m.FamilyID,
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+		RotatedAt: m.RotatedAt,
+		RevokedAt: m.RevokedAt,
+	}
+}
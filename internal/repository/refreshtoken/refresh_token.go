@@ -0,0 +1,130 @@
+package refreshtoken
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+type RefreshTokenModel struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	FamilyID  uuid.UUID  `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+type RefreshTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) error {
+	return insert(ctx, r.db, toModel(token))
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	query, args, err := sq.
+		Select("id", "user_id", "family_id", "token_hash", "expires_at", "created_at", "rotated_at", "revoked_at").
+		From("refresh_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	var m RefreshTokenModel
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&m.ID,
+		&m.UserID,
+		&m.FamilyID,
+		&m.TokenHash,
+		&m.ExpiresAt,
+		&m.CreatedAt,
+		&m.RotatedAt,
+		&m.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	return toDomain(m), nil
+}
+
+// Rotate marks the used token rotated only if nobody has rotated or revoked it
+// first, so of two concurrent refreshes with the same token one wins and the
+// other is reported as reuse.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, used, next domain.RefreshToken) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, `
+			UPDATE refresh_tokens
+			SET rotated_at = $2
+			WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL
+		`, used.ID, used.RotatedAt)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return domain.ErrRefreshTokenReused
+		}
+
+		return insert(ctx, tx, toModel(next))
+	})
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
+
+func insert(ctx context.Context, e execer, m RefreshTokenModel) error {
+	query, args, err := sq.
+		Insert("refresh_tokens").
+		Columns("id", "user_id", "family_id", "token_hash", "expires_at", "created_at").
+		Values(m.ID, m.UserID, m.FamilyID, m.TokenHash, m.ExpiresAt, m.CreatedAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, query, args...)
+	return err
+}
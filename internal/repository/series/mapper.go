@@ -0,0 +1,44 @@
+package series
+
+import "taskflow/internal/domain"
+
+func toModel(s domain.TaskSeries) SeriesModel {
+	return SeriesModel{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		RRule:       s.Rule.String(),
+		Title:       s.Title,
+		Description: s.Description,
+		Priority:    string(s.Priority),
+		StartsAt:    s.StartsAt,
+		LastDueAt:   s.LastDueAt,
+		StoppedAt:   s.StoppedAt,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+func toDomain(m SeriesModel) (domain.TaskSeries, error) {
+	rule, err := domain.ParseRecurrence(m.RRule)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	s := domain.TaskSeries{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Rule:        rule,
+		Description: m.Description,
+		StartsAt:    m.StartsAt,
+		LastDueAt:   m.LastDueAt,
+		StoppedAt:   m.StoppedAt,
+		CreatedAt:   m.CreatedAt,
+	}
+	if err := s.Rename(m.Title); err != nil {
+		return domain.TaskSeries{}, err
+	}
+	if err := s.ChangePriority(domain.Priority(m.Priority)); err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,127 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"taskflow/internal/domain"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrSeriesNotFound = errors.New("task series not found")
+
+type SeriesModel struct {
+	ID          uuid.UUID  `db:"id"`
+	UserID      uuid.UUID  `db:"user_id"`
+	RRule       string     `db:"rrule"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	Priority    string     `db:"priority"`
+	StartsAt    time.Time  `db:"starts_at"`
+	LastDueAt   time.Time  `db:"last_due_at"`
+	StoppedAt   *time.Time `db:"stopped_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+var seriesColumns = []string{
+	"id", "user_id", "rrule", "title", "description", "priority", "starts_at", "last_due_at", "stopped_at", "created_at",
+}
+
+type SeriesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSeriesRepository(db *pgxpool.Pool) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+func (r *SeriesRepository) Get(ctx context.Context, id, userID uuid.UUID) (domain.TaskSeries, error) {
+	query, args, err := sq.
+		Select(seriesColumns...).
+		From("task_series").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	m, err := scanSeries(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TaskSeries{}, ErrSeriesNotFound
+	}
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	return toDomain(m)
+}
+
+// Update stores the template, the rule and the stop marker of the series. The
+// newest occurrence is owned by Advance and left untouched.
+func (r *SeriesRepository) Update(ctx context.Context, series domain.TaskSeries) (domain.TaskSeries, error) {
+	m := toModel(series)
+
+	query, args, err := sq.
+		Update("task_series").
+		Set("rrule", m.RRule).
+		Set("title", m.Title).
+		Set("description", m.Description).
+		Set("priority", m.Priority).
+		Set("starts_at", m.StartsAt).
+		Set("stopped_at", m.StoppedAt).
+		Where(sq.Eq{"id": m.ID, "user_id": m.UserID}).
+		Suffix("RETURNING " + strings.Join(seriesColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	updated, err := scanSeries(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TaskSeries{}, ErrSeriesNotFound
+	}
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	return toDomain(updated)
+}
+
+// Advance moves the newest occurrence of an active series from previousDueAt
+// to series.LastDueAt. It reports false when another request has advanced or
+// stopped the series first, so every occurrence gets one successor at most.
+func (r *SeriesRepository) Advance(ctx context.Context, series domain.TaskSeries, previousDueAt time.Time) (bool, error) {
+	res, err := r.db.Exec(ctx, `
+		UPDATE task_series SET last_due_at = $1
+		WHERE id = $2 AND user_id = $3 AND last_due_at = $4 AND stopped_at IS NULL
+	`, series.LastDueAt, series.ID, series.UserID, previousDueAt)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() == 1, nil
+}
+
+func scanSeries(row pgx.Row) (SeriesModel, error) {
+	var m SeriesModel
+	err := row.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.RRule,
+		&m.Title,
+		&m.Description,
+		&m.Priority,
+		&m.StartsAt,
+		&m.LastDueAt,
+		&m.StoppedAt,
+		&m.CreatedAt,
+	)
+	return m, err
+}
@@ -8,21 +8,50 @@ func toModel(t domain.Task) TaskModel {
 	return TaskModel{
 		ID:          t.ID,
 		UserID:      t.UserID,
+		ProjectID:   t.ProjectID,
+		AssigneeID:  t.AssigneeID,
 		Title:       t.Title,
 		Description: t.Description,
 		Status:      string(t.Status),
+		Category:    string(t.Category),
+		Priority:    string(t.Priority),
+		DueAt:       t.DueAt,
 		CreatedAt:   t.CreatedAt,
 		CompletedAt: t.CompletedAt,
+		CompletedBy: t.CompletedBy,
+		ParentID:    t.ParentID,
+		SeriesID:    t.SeriesID,
+		Version:     t.Version,
 	}
 }
+
 func toDomain(m TaskModel) (domain.Task, error) {
-	return domain.NewTaskFromStorage(
+	task, err := domain.NewTaskFromStorage(
 		m.ID,
 		m.UserID,
 		m.Title,
 		m.Description,
 		domain.Status(m.Status),
+		domain.StatusCategory(m.Category),
 		m.CreatedAt,
 		m.CompletedAt,
 	)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := task.ChangePriority(domain.Priority(m.Priority)); err != nil {
+		return domain.Task{}, err
+	}
+	task.Reschedule(m.DueAt)
+	task.ProjectID = m.ProjectID
+	task.AssigneeID = m.AssigneeID
+	task.CompletedBy = m.CompletedBy
+	task.ParentID = m.ParentID
+	task.SeriesID = m.SeriesID
+	task.Subtasks = domain.SubtaskProgress{Total: m.SubtasksTotal, Done: m.SubtasksDone}
+	task.ChecklistProgress = domain.ChecklistProgress{Total: m.ChecklistTotal, Done: m.ChecklistDone}
+	task.Version = m.Version
+
+	return task, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"taskflow/internal/domain"
 	"time"
 
@@ -18,22 +19,96 @@ var ErrTaskNotFound = errors.New("task not found")
 type TaskModel struct {
 	ID          uuid.UUID  `db:"id"`
 	UserID      uuid.UUID  `db:"user_id"`
+	ProjectID   uuid.UUID  `db:"project_id"`
+	AssigneeID  *uuid.UUID `db:"assignee_id"`
 	Title       string     `db:"title"`
 	Description string     `db:"description"`
 	Status      string     `db:"status"`
+	Category    string     `db:"category"`
+	Priority    string     `db:"priority"`
+	DueAt       *time.Time `db:"due_at"`
 	CreatedAt   time.Time  `db:"created_at"`
 	CompletedAt *time.Time `db:"completed_at"`
+	CompletedBy *uuid.UUID `db:"completed_by"`
+	ParentID    *uuid.UUID `db:"parent_id"`
+	SeriesID    *uuid.UUID `db:"series_id"`
+	Version     int64      `db:"version"`
+
+	SubtasksTotal  int `db:"subtasks_total"`
+	SubtasksDone   int `db:"subtasks_done"`
+	ChecklistTotal int `db:"checklist_total"`
+	ChecklistDone  int `db:"checklist_done"`
+}
+
+// labelModel is the subset of the labels table embedded into tasks.
+type labelModel struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt time.Time
+}
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 type TaskRepository struct {
 	db *pgxpool.Pool
 }
 
+var taskColumns = []string{
+	"id", "user_id", "project_id", "assignee_id", "title", "description", "status", "category", "priority", "due_at", "created_at", "completed_at", "completed_by", "parent_id", "series_id", "version",
+	fmt.Sprintf(
+		"(SELECT count(*) FROM tasks c WHERE c.parent_id = tasks.id AND c.category <> '%s') AS subtasks_total",
+		domain.CategoryCanceled,
+	),
+	fmt.Sprintf(
+		"(SELECT count(*) FROM tasks c WHERE c.parent_id = tasks.id AND c.category = '%s') AS subtasks_done",
+		domain.CategoryDone,
+	),
+	"(SELECT count(*) FROM task_checklist_items i WHERE i.task_id = tasks.id) AS checklist_total",
+	"(SELECT count(*) FROM task_checklist_items i WHERE i.task_id = tasks.id AND i.checked) AS checklist_done",
+}
+
+// subtreeQuery walks every descendant of the task in $1 and reports how many
+// levels below the task it sits.
+const subtreeQuery = `
+	WITH RECURSIVE subtree AS (
+		SELECT id, 1 AS depth FROM tasks WHERE parent_id = $1
+		UNION ALL
+		SELECT t.id, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+	)
+`
+
 var allowedSortColumns = map[string]string{
-	"created_at":   "created_at",
-	"title":        "title",
-	"status":       "status",
+	"created_at": "created_at",
+	"title":      "title",
+	// Statuses sort by category, in workflow stage order.
+	"status":       "category",
 	"completed_at": "completed_at",
+	"priority":     "priority",
+	"due_at":       "due_at",
+}
+
+// openBlockersQuery selects the open blockers of the outer task; it expects
+// the closed categories as arguments.
+const openBlockersQuery = `
+	SELECT 1 FROM task_dependencies d
+	JOIN tasks b ON b.id = d.blocker_id
+	WHERE d.task_id = tasks.id AND b.category NOT IN (?, ?)
+`
+
+// overdueCondition matches open tasks past their due date; it expects the
+// closed categories as arguments.
+const overdueCondition = "due_at < now() AND category NOT IN (?, ?)"
+
+// nullableSortColumns keep tasks without a value at the end in both directions.
+var nullableSortColumns = map[string]bool{
+	"completed_at": true,
+	"due_at":       true,
 }
 
 func NewTaskRepository(db *pgxpool.Pool) *TaskRepository {
@@ -46,14 +121,48 @@ func (r *TaskRepository) Create(
 	ctx context.Context,
 	task domain.Task,
 ) (domain.Task, error) {
+	return r.create(ctx, r.db, task)
+}
+
+// CreateInSeries stores a new series together with the task as its first
+// occurrence, so that a failed task leaves no series without occurrences.
+func (r *TaskRepository) CreateInSeries(
+	ctx context.Context,
+	task domain.Task,
+	series domain.TaskSeries,
+) (domain.Task, error) {
+
+	var created domain.Task
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO task_series (id, user_id, rrule, title, description, priority, starts_at, last_due_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, series.ID, series.UserID, series.Rule.String(), series.Title, series.Description,
+			string(series.Priority), series.StartsAt, series.LastDueAt)
+		if err != nil {
+			return err
+		}
+
+		task.SeriesID = &series.ID
+		created, err = r.create(ctx, tx, task)
+		return err
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return created, nil
+}
 
+func (r *TaskRepository) create(ctx context.Context, q querier, task domain.Task) (domain.Task, error) {
 	m := toModel(task)
 
 	query, args, err := sq.
 		Insert("tasks").
-		Columns("id", "user_id", "title", "description", "status", "completed_at").
-		Values(m.ID, m.UserID, m.Title, m.Description, m.Status, m.CompletedAt).
-		Suffix("RETURNING id, user_id, title, description, status, created_at, completed_at").
+		Columns("id", "user_id", "project_id", "assignee_id", "title", "description", "status", "category", "priority", "due_at", "completed_at", "completed_by", "parent_id", "series_id", "version").
+		Values(m.ID, m.UserID, m.ProjectID, m.AssigneeID, m.Title, m.Description, m.Status, m.Category, m.Priority, m.DueAt, m.CompletedAt, m.CompletedBy, m.ParentID, m.SeriesID, m.Version).
+		Suffix("RETURNING " + strings.Join(taskColumns, ", ")).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -61,18 +170,7 @@ func (r *TaskRepository) Create(
 		return domain.Task{}, err
 	}
 
-	var created TaskModel
-
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&created.ID,
-		&created.UserID,
-		&created.Title,
-		&created.Description,
-		&created.Status,
-		&created.CreatedAt,
-		&created.CompletedAt,
-	)
-
+	created, err := scanTask(q.QueryRow(ctx, query, args...))
 	if err != nil {
 		return domain.Task{}, err
 	}
@@ -82,13 +180,13 @@ func (r *TaskRepository) Create(
 
 func (r *TaskRepository) Get(
 	ctx context.Context,
-	id, userID uuid.UUID,
+	id uuid.UUID,
 ) (domain.Task, error) {
 
 	query, args, err := sq.
-		Select("id", "user_id", "title", "description", "status", "created_at", "completed_at").
+		Select(taskColumns...).
 		From("tasks").
-		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -96,18 +194,7 @@ func (r *TaskRepository) Get(
 		return domain.Task{}, err
 	}
 
-	var m TaskModel
-
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&m.ID,
-		&m.UserID,
-		&m.Title,
-		&m.Description,
-		&m.Status,
-		&m.CreatedAt,
-		&m.CompletedAt,
-	)
-
+	m, err := scanTask(r.db.QueryRow(ctx, query, args...))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.Task{}, ErrTaskNotFound
 	}
@@ -115,24 +202,35 @@ func (r *TaskRepository) Get(
 		return domain.Task{}, err
 	}
 
-	return toDomain(m)
+	return r.withRelations(ctx, r.db, m)
 }
 
 func (r *TaskRepository) Update(
 	ctx context.Context,
 	task domain.Task,
 ) (domain.Task, error) {
+	return r.update(ctx, r.db, task)
+}
 
+func (r *TaskRepository) update(ctx context.Context, q querier, task domain.Task) (domain.Task, error) {
 	m := toModel(task)
 
 	query, args, err := sq.
 		Update("tasks").
+		Set("assignee_id", m.AssigneeID).
 		Set("title", m.Title).
 		Set("description", m.Description).
 		Set("status", m.Status).
+		Set("category", m.Category).
+		Set("priority", m.Priority).
+		Set("due_at", m.DueAt).
 		Set("completed_at", m.CompletedAt).
-		Where(sq.Eq{"id": m.ID, "user_id": m.UserID}).
-		Suffix("RETURNING id, user_id, title, description, status, created_at, completed_at").
+		Set("completed_by", m.CompletedBy).
+		Set("parent_id", m.ParentID).
+		Set("series_id", m.SeriesID).
+		Set("version", sq.Expr("version + 1")).
+		Where(sq.Eq{"id": m.ID, "version": m.Version}).
+		Suffix("RETURNING " + strings.Join(taskColumns, ", ")).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -140,55 +238,78 @@ func (r *TaskRepository) Update(
 		return domain.Task{}, err
 	}
 
-	var updated TaskModel
-
-	err = r.db.QueryRow(ctx, query, args...).Scan(
-		&updated.ID,
-		&updated.UserID,
-		&updated.Title,
-		&updated.Description,
-		&updated.Status,
-		&updated.CreatedAt,
-		&updated.CompletedAt,
-	)
-
+	updated, err := scanTask(q.QueryRow(ctx, query, args...))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.Task{}, ErrTaskNotFound
+		return domain.Task{}, r.missingOrConflict(ctx, m.ID)
 	}
 	if err != nil {
 		return domain.Task{}, err
 	}
 
-	return toDomain(updated)
+	return r.withRelations(ctx, q, updated)
 }
 
+// Delete removes the task together with its subtasks, provided it still has
+// the version it was loaded with. Tasks that were blocked by any of them get
+// their version bumped in the same transaction; their new versions are
+// returned.
 func (r *TaskRepository) Delete(
 	ctx context.Context,
-	id, userID uuid.UUID,
-) error {
+	task domain.Task,
+) (map[uuid.UUID]int64, error) {
 
+	id := task.ID
 	query, args, err := sq.
 		Delete("tasks").
-		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.Eq{"id": id, "version": task.Version}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	res, err := r.db.Exec(ctx, query, args...)
-	if err != nil {
-		return err
-	}
+	var touched map[uuid.UUID]int64
+
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, subtreeQuery+`
+			UPDATE tasks SET version = version + 1
+			WHERE id <> $1
+				AND id NOT IN (SELECT id FROM subtree)
+				AND id IN (
+					SELECT task_id FROM task_dependencies
+					WHERE blocker_id = $1 OR blocker_id IN (SELECT id FROM subtree)
+				)
+			RETURNING id, version
+		`, id)
+		if err != nil {
+			return err
+		}
 
-	if res.RowsAffected() == 0 {
-		return ErrTaskNotFound
+		touched, err = collectVersions(rows)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if res.RowsAffected() == 0 {
+			return r.missingOrConflict(ctx, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return touched, nil
 }
 
+// List returns the tasks of every project the user is a member of.
 func (r *TaskRepository) List(
 	ctx context.Context,
 	userID uuid.UUID,
@@ -198,9 +319,9 @@ func (r *TaskRepository) List(
 	filter.Normalize()
 
 	builder := sq.
-		Select("id", "user_id", "title", "description", "status", "created_at", "completed_at").
+		Select(taskColumns...).
 		From("tasks").
-		Where(sq.Eq{"user_id": userID}).
+		Where("project_id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID).
 		Limit(uint64(filter.Limit)).
 		Offset(uint64(filter.Offset)).
 		PlaceholderFormat(sq.Dollar)
@@ -209,16 +330,89 @@ func (r *TaskRepository) List(
 		builder = builder.Where(sq.Eq{"status": string(*filter.Status)})
 	}
 
+	if filter.Category != nil {
+		builder = builder.Where(sq.Eq{"category": string(*filter.Category)})
+	}
+
+	if filter.Priority != nil {
+		builder = builder.Where(sq.Eq{"priority": string(*filter.Priority)})
+	}
+
+	if filter.ProjectID != nil {
+		builder = builder.Where(sq.Eq{"project_id": *filter.ProjectID})
+	}
+
+	if filter.AssigneeID != nil {
+		builder = builder.Where(sq.Eq{"assignee_id": *filter.AssigneeID})
+	}
+
+	if filter.Unassigned {
+		builder = builder.Where(sq.Eq{"assignee_id": nil})
+	}
+
+	if filter.ParentID != nil {
+		builder = builder.Where(sq.Eq{"parent_id": *filter.ParentID})
+	}
+
+	if filter.SeriesID != nil {
+		builder = builder.Where(sq.Eq{"series_id": *filter.SeriesID})
+	}
+
 	if filter.Search != nil {
 		builder = builder.Where("title ILIKE ?", "%"+*filter.Search+"%")
 	}
 
+	if filter.DueBefore != nil {
+		builder = builder.Where(sq.Lt{"due_at": *filter.DueBefore})
+	}
+
+	if filter.DueAfter != nil {
+		builder = builder.Where(sq.Gt{"due_at": *filter.DueAfter})
+	}
+
+	if len(filter.LabelsAny) > 0 {
+		builder = builder.Where(
+			"EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id = ANY(?))",
+			filter.LabelsAny,
+		)
+	}
+
+	if len(filter.LabelsAll) > 0 {
+		builder = builder.Where(
+			"(SELECT count(DISTINCT tl.label_id) FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id = ANY(?)) = ?",
+			filter.LabelsAll,
+			len(uniqueIDs(filter.LabelsAll)),
+		)
+	}
+
+	if filter.Blocked != nil {
+		condition := fmt.Sprintf("EXISTS (%s)", openBlockersQuery)
+		if !*filter.Blocked {
+			condition = "NOT " + condition
+		}
+		builder = builder.Where(condition, string(domain.CategoryDone), string(domain.CategoryCanceled))
+	}
+
+	if filter.Overdue != nil {
+		closed := []any{string(domain.CategoryDone), string(domain.CategoryCanceled)}
+		if *filter.Overdue {
+			builder = builder.Where(overdueCondition, closed...)
+		} else {
+			builder = builder.Where("NOT COALESCE("+overdueCondition+", false)", closed...)
+		}
+	}
+
 	sortColumn := allowedSortColumns[filter.SortBy]
 	if sortColumn == "" {
 		sortColumn = allowedSortColumns["created_at"]
 	}
 
-	builder = builder.OrderBy(fmt.Sprintf("%s %s", sortColumn, filter.SortDir))
+	orderBy := fmt.Sprintf("%s %s", sortColumn, filter.SortDir)
+	if nullableSortColumns[sortColumn] {
+		orderBy += " NULLS LAST"
+	}
+
+	builder = builder.OrderBy(orderBy)
 
 	query, args, err := builder.ToSql()
 	if err != nil {
@@ -231,29 +425,551 @@ func (r *TaskRepository) List(
 	}
 	defer rows.Close()
 
-	var result []domain.Task
+	var models []TaskModel
 
 	for rows.Next() {
-		var m TaskModel
-		if err := rows.Scan(
-			&m.ID,
-			&m.UserID,
-			&m.Title,
-			&m.Description,
-			&m.Status,
-			&m.CreatedAt,
-			&m.CompletedAt,
-		); err != nil {
+		m, err := scanTask(rows)
+		if err != nil {
 			return nil, err
 		}
 
+		models = append(models, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	ids := make([]uuid.UUID, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+
+	labels, err := r.labelsByTask(ctx, r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	blockers, err := r.blockersByTask(ctx, r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []domain.Task
+
+	for _, m := range models {
 		task, err := toDomain(m)
 		if err != nil {
 			return nil, err
 		}
 
+		task.Labels = labels[m.ID]
+		task.BlockedBy = blockers[m.ID]
 		result = append(result, task)
 	}
 
+	return result, nil
+}
+
+// AttachLabel links a label to the task and bumps the task version. The caller
+// checks who owns the label. Attaching an already attached label is a no-op.
+func (r *TaskRepository) AttachLabel(ctx context.Context, task domain.Task, labelID uuid.UUID) (domain.Task, error) {
+	return r.changeRelation(ctx, task, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT $1, id FROM labels WHERE id = $2
+		ON CONFLICT DO NOTHING
+	`, labelID)
+}
+
+// DetachLabel unlinks a label from the task and bumps the task version.
+// Detaching a label the task does not carry is a no-op.
+func (r *TaskRepository) DetachLabel(ctx context.Context, task domain.Task, labelID uuid.UUID) (domain.Task, error) {
+	return r.changeRelation(ctx, task, `
+		DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2
+	`, labelID)
+}
+
+// AddBlocker makes the task depend on another task of the same project and
+// bumps the task version. Adding an existing blocker is a no-op.
+func (r *TaskRepository) AddBlocker(ctx context.Context, task domain.Task, blockerID uuid.UUID) (domain.Task, error) {
+	return r.changeRelation(ctx, task, `
+		INSERT INTO task_dependencies (task_id, blocker_id)
+		SELECT $1, id FROM tasks WHERE id = $2 AND project_id = $3
+		ON CONFLICT DO NOTHING
+	`, blockerID, task.ProjectID)
+}
+
+// RemoveBlocker drops a dependency of the task and bumps the task version.
+// Removing a blocker the task does not have is a no-op.
+func (r *TaskRepository) RemoveBlocker(ctx context.Context, task domain.Task, blockerID uuid.UUID) (domain.Task, error) {
+	return r.changeRelation(ctx, task, `
+		DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2
+	`, blockerID)
+}
+
+// TransitiveBlockers returns every task the given one depends on, directly or
+// through other blockers.
+func (r *TaskRepository) TransitiveBlockers(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT blocker_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.blocker_id FROM task_dependencies d JOIN chain c ON d.task_id = c.blocker_id
+		)
+		SELECT blocker_id FROM chain
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// TouchDependents bumps the version of every task blocked by one of the given
+// tasks and returns the new versions.
+func (r *TaskRepository) TouchDependents(ctx context.Context, blockerIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	if len(blockerIDs) == 0 {
+		return map[uuid.UUID]int64{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		UPDATE tasks SET version = version + 1
+		WHERE id IN (SELECT task_id FROM task_dependencies WHERE blocker_id = ANY($1))
+		RETURNING id, version
+	`, blockerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectVersions(rows)
+}
+
+// changeRelation runs statement, which links or unlinks the task in $1 and
+// another row, and bumps the task version when a link has changed. args fill
+// the remaining placeholders.
+func (r *TaskRepository) changeRelation(ctx context.Context, task domain.Task, statement string, args ...any) (domain.Task, error) {
+	var result domain.Task
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, statement, append([]any{task.ID}, args...)...)
+		if err != nil {
+			return err
+		}
+
+		var m TaskModel
+		if res.RowsAffected() == 0 {
+			m, err = scanTask(tx.QueryRow(ctx,
+				"SELECT "+strings.Join(taskColumns, ", ")+" FROM tasks WHERE id = $1",
+				task.ID,
+			))
+		} else {
+			m, err = scanTask(tx.QueryRow(ctx,
+				"UPDATE tasks SET version = version + 1 WHERE id = $1 AND version = $2 RETURNING "+strings.Join(taskColumns, ", "),
+				task.ID, task.Version,
+			))
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.missingOrConflict(ctx, task.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err = r.withRelations(ctx, tx, m)
+		return err
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return result, nil
+}
+
+// SaveChecklist replaces the stored checklist of the task with task.Checklist
+// and bumps the task version.
+func (r *TaskRepository) SaveChecklist(ctx context.Context, task domain.Task) (domain.Task, error) {
+	ids := make([]uuid.UUID, 0, len(task.Checklist))
+	texts := make([]string, 0, len(task.Checklist))
+	checked := make([]bool, 0, len(task.Checklist))
+	positions := make([]int32, 0, len(task.Checklist))
+	for _, item := range task.Checklist {
+		ids = append(ids, item.ID)
+		texts = append(texts, item.Text)
+		checked = append(checked, item.Checked)
+		positions = append(positions, int32(item.Position))
+	}
+
+	var result domain.Task
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM task_checklist_items WHERE task_id = $1 AND NOT (id = ANY($2))
+		`, task.ID, ids); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO task_checklist_items (id, task_id, text, checked, position)
+			SELECT i.id, $1, i.text, i.checked, i.position
+			FROM unnest($2::uuid[], $3::text[], $4::bool[], $5::int[]) AS i(id, text, checked, position)
+			ON CONFLICT (id) DO UPDATE
+			SET text = EXCLUDED.text, checked = EXCLUDED.checked, position = EXCLUDED.position
+			WHERE task_checklist_items.task_id = EXCLUDED.task_id
+		`, task.ID, ids, texts, checked, positions); err != nil {
+			return err
+		}
+
+		m, err := scanTask(tx.QueryRow(ctx,
+			"UPDATE tasks SET version = version + 1 WHERE id = $1 AND version = $2 RETURNING "+strings.Join(taskColumns, ", "),
+			task.ID, task.Version,
+		))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.missingOrConflict(ctx, task.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err = r.withRelations(ctx, tx, m)
+		return err
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return result, nil
+}
+
+// Touch bumps the task version without changing its fields. It is used when
+// something the task representation is derived from, like its subtask
+// progress, changes.
+func (r *TaskRepository) Touch(ctx context.Context, id uuid.UUID) (domain.Task, error) {
+	m, err := scanTask(r.db.QueryRow(ctx,
+		"UPDATE tasks SET version = version + 1 WHERE id = $1 RETURNING "+strings.Join(taskColumns, ", "),
+		id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Task{}, ErrTaskNotFound
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return r.withRelations(ctx, r.db, m)
+}
+
+// Ancestors returns the IDs of the task's parent, grandparent and so on up to
+// the root, nearest first.
+func (r *TaskRepository) Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH RECURSIVE lineage AS (
+			SELECT parent_id, 1 AS depth FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT t.parent_id, l.depth + 1 FROM tasks t JOIN lineage l ON t.id = l.parent_id
+		)
+		SELECT parent_id FROM lineage WHERE parent_id IS NOT NULL ORDER BY depth
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// Descendants maps every task below the given one to its distance from it:
+// 1 for direct subtasks, 2 for their subtasks and so on.
+func (r *TaskRepository) Descendants(ctx context.Context, id uuid.UUID) (map[uuid.UUID]int, error) {
+	rows, err := r.db.Query(ctx, subtreeQuery+"SELECT id, depth FROM subtree", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var (
+			descendantID uuid.UUID
+			depth        int
+		)
+		if err := rows.Scan(&descendantID, &depth); err != nil {
+			return nil, err
+		}
+		result[descendantID] = depth
+	}
+
 	return result, rows.Err()
 }
+
+// CompleteWithSubtasks stores the task, already moved to a done status, and
+// marks every open task below it done in the same transaction, completed by
+// the same user. It returns the
+// stored task and the new versions of the subtasks it changed. It fails with
+// domain.ErrBlocked when one of those subtasks still waits for an open task
+// outside of the tree, and like Update when the task has changed meanwhile.
+func (r *TaskRepository) CompleteWithSubtasks(
+	ctx context.Context,
+	task domain.Task,
+	now time.Time,
+) (domain.Task, map[uuid.UUID]int64, error) {
+
+	var (
+		updated domain.Task
+		touched map[uuid.UUID]int64
+	)
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		updated, err = r.update(ctx, tx, task)
+		if err != nil {
+			return err
+		}
+
+		var blocked bool
+		err = tx.QueryRow(ctx, subtreeQuery+`
+			SELECT EXISTS (
+				SELECT 1
+				FROM task_dependencies d
+				JOIN tasks t ON t.id = d.task_id
+				JOIN tasks b ON b.id = d.blocker_id
+				WHERE d.task_id IN (SELECT id FROM subtree)
+					AND t.category NOT IN ($2, $3)
+					AND b.category NOT IN ($2, $3)
+					AND b.id <> $1
+					AND b.id NOT IN (SELECT id FROM subtree)
+			)
+		`, task.ID, string(domain.CategoryDone), string(domain.CategoryCanceled)).Scan(&blocked)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return domain.ErrBlocked
+		}
+
+		rows, err := tx.Query(ctx, subtreeQuery+`
+			UPDATE tasks
+			SET status = $2, category = $3, completed_at = $4, completed_by = $6, version = version + 1
+			WHERE id IN (SELECT id FROM subtree) AND category NOT IN ($3, $5)
+			RETURNING id, version
+		`, task.ID, string(task.Status), string(domain.CategoryDone), now, string(domain.CategoryCanceled), task.CompletedBy)
+		if err != nil {
+			return err
+		}
+
+		touched, err = collectVersions(rows)
+		return err
+	})
+	if err != nil {
+		return domain.Task{}, nil, err
+	}
+
+	return updated, touched, nil
+}
+
+// withRelations loads the labels, blockers and checklist embedded into a task.
+func (r *TaskRepository) withRelations(ctx context.Context, q querier, m TaskModel) (domain.Task, error) {
+	task, err := toDomain(m)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	labels, err := r.labelsByTask(ctx, q, []uuid.UUID{m.ID})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	blockers, err := r.blockersByTask(ctx, q, []uuid.UUID{m.ID})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	checklist, err := r.checklist(ctx, q, m.ID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	task.Labels = labels[m.ID]
+	task.BlockedBy = blockers[m.ID]
+	task.Checklist = checklist
+	return task, nil
+}
+
+// checklist loads the checklist items of a task in order.
+func (r *TaskRepository) checklist(ctx context.Context, q querier, taskID uuid.UUID) ([]domain.ChecklistItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, text, checked, position
+		FROM task_checklist_items
+		WHERE task_id = $1
+		ORDER BY position, created_at
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.ChecklistItem
+	for rows.Next() {
+		var item domain.ChecklistItem
+		if err := rows.Scan(&item.ID, &item.Text, &item.Checked, &item.Position); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+// blockersByTask loads the blockers of a whole page of tasks in one query.
+func (r *TaskRepository) blockersByTask(ctx context.Context, q querier, taskIDs []uuid.UUID) (map[uuid.UUID][]domain.Blocker, error) {
+	result := make(map[uuid.UUID][]domain.Blocker, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT d.task_id, b.id, b.status, b.category
+		FROM task_dependencies d
+		JOIN tasks b ON b.id = d.blocker_id
+		WHERE d.task_id = ANY($1)
+		ORDER BY b.created_at
+	`, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID   uuid.UUID
+			id       uuid.UUID
+			status   string
+			category string
+		)
+		if err := rows.Scan(&taskID, &id, &status, &category); err != nil {
+			return nil, err
+		}
+
+		result[taskID] = append(result[taskID], domain.Blocker{
+			ID:       id,
+			Status:   domain.NormalizeStatus(domain.Status(status)),
+			Category: domain.StatusCategory(category),
+		})
+	}
+
+	return result, rows.Err()
+}
+
+// labelsByTask loads the labels of a whole page of tasks in one query.
+func (r *TaskRepository) labelsByTask(ctx context.Context, q querier, taskIDs []uuid.UUID) (map[uuid.UUID][]domain.Label, error) {
+	result := make(map[uuid.UUID][]domain.Label, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT tl.task_id, l.id, l.user_id, l.name, l.color, l.created_at
+		FROM task_labels tl
+		JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id = ANY($1)
+		ORDER BY lower(l.name)
+	`, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID uuid.UUID
+			m      labelModel
+		)
+		if err := rows.Scan(&taskID, &m.ID, &m.UserID, &m.Name, &m.Color, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		label, err := domain.NewLabelFromStorage(m.ID, m.UserID, m.Name, m.Color, m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result[taskID] = append(result[taskID], label)
+	}
+
+	return result, rows.Err()
+}
+
+// missingOrConflict explains why a versioned update matched no rows: either
+// the task is gone or somebody else has already bumped its version.
+func (r *TaskRepository) missingOrConflict(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1)
+	`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTaskNotFound
+	}
+
+	return domain.ErrVersionConflict
+}
+
+func scanTask(row pgx.Row) (TaskModel, error) {
+	var m TaskModel
+	err := row.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.ProjectID,
+		&m.AssigneeID,
+		&m.Title,
+		&m.Description,
+		&m.Status,
+		&m.Category,
+		&m.Priority,
+		&m.DueAt,
+		&m.CreatedAt,
+		&m.CompletedAt,
+		&m.CompletedBy,
+		&m.ParentID,
+		&m.SeriesID,
+		&m.Version,
+		&m.SubtasksTotal,
+		&m.SubtasksDone,
+		&m.ChecklistTotal,
+		&m.ChecklistDone,
+	)
+	return m, err
+}
+
+// collectVersions reads the (id, version) rows returned by a bulk update.
+func collectVersions(rows pgx.Rows) (map[uuid.UUID]int64, error) {
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var (
+			id      uuid.UUID
+			version int64
+		)
+		if err := rows.Scan(&id, &version); err != nil {
+			return nil, err
+		}
+		result[id] = version
+	}
+
+	return result, rows.Err()
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+
+	return result
+}
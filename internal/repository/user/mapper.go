@@ -4,10 +4,11 @@ import "taskflow/internal/domain"
 
 func toModel(u domain.User) UserModel {
 	return UserModel{
-		ID:           u.ID,
-		Email:        u.Email,
-		PasswordHash: u.PasswordHash,
-		CreatedAt:    u.CreatedAt,
+		ID:              u.ID,
+		Email:           u.Email,
+		PasswordHash:    u.PasswordHash,
+		CreatedAt:       u.CreatedAt,
+		EmailVerifiedAt: u.EmailVerifiedAt,
 	}
 }
 
@@ -17,5 +18,6 @@ func toDomain(m UserModel) (domain.User, error) {
 		m.Email,
 		m.PasswordHash,
 		m.CreatedAt,
+		m.EmailVerifiedAt,
 	)
 }
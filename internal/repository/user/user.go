@@ -15,10 +15,11 @@ import (
 var ErrUserNotFound = errors.New("user not found")
 
 type UserModel struct {
-	ID           uuid.UUID `db:"id"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	CreatedAt    time.Time `db:"created_at"`
+	ID              uuid.UUID  `db:"id"`
+	Email           string     `db:"email"`
+	PasswordHash    string     `db:"password_hash"`
+	CreatedAt       time.Time  `db:"created_at"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
 }
 
 type UserRepository struct {
@@ -34,9 +35,9 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.U
 
 	query, args, err := sq.
 		Insert("users").
-		Columns("id", "email", "password_hash").
-		Values(m.ID, m.Email, m.PasswordHash).
-		Suffix("RETURNING id, email, password_hash, created_at").
+		Columns("id", "email", "password_hash", "email_verified_at").
+		Values(m.ID, m.Email, m.PasswordHash, m.EmailVerifiedAt).
+		Suffix("RETURNING id, email, password_hash, created_at, email_verified_at").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
@@ -49,6 +50,7 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.U
 		&created.Email,
 		&created.PasswordHash,
 		&created.CreatedAt,
+		&created.EmailVerifiedAt,
 	)
 	if err != nil {
 		return domain.User{}, err
@@ -59,7 +61,7 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.U
 
 func (r *UserRepository) Get(ctx context.Context, id uuid.UUID) (domain.User, error) {
 	query, args, err := sq.
-		Select("id", "email", "password_hash", "created_at").
+		Select("id", "email", "password_hash", "created_at", "email_verified_at").
 		From("users").
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar).
@@ -74,6 +76,7 @@ func (r *UserRepository) Get(ctx context.Context, id uuid.UUID) (domain.User, er
 		&m.Email,
 		&m.PasswordHash,
 		&m.CreatedAt,
+		&m.EmailVerifiedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.User{}, ErrUserNotFound
@@ -87,7 +90,7 @@ func (r *UserRepository) Get(ctx context.Context, id uuid.UUID) (domain.User, er
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	query, args, err := sq.
-		Select("id", "email", "password_hash", "created_at").
+		Select("id", "email", "password_hash", "created_at", "email_verified_at").
 		From("users").
 		Where(sq.Eq{"email": domain.NormalizeUserEmail(email)}).
 		PlaceholderFormat(sq.Dollar).
@@ -102,6 +105,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.U
 		&m.Email,
 		&m.PasswordHash,
 		&m.CreatedAt,
+		&m.EmailVerifiedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.User{}, ErrUserNotFound
@@ -116,9 +120,27 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.U
 func (r *UserRepository) Ensure(ctx context.Context, user domain.User) error {
 	m := toModel(user)
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO users (id, email, password_hash)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (id, email, password_hash, email_verified_at)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (id) DO NOTHING
-	`, m.ID, m.Email, m.PasswordHash)
+	`, m.ID, m.Email, m.PasswordHash, m.EmailVerifiedAt)
 	return err
 }
+
+// VerifyEmail records when the user verified the email unless it already
+// was verified.
+func (r *UserRepository) VerifyEmail(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, $2)
+		WHERE id = $1
+	`, id, verifiedAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
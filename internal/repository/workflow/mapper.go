@@ -0,0 +1,46 @@
+package workflow
+
+import "taskflow/internal/domain"
+
+func toModel(w domain.Workflow) WorkflowModel {
+	m := WorkflowModel{UserID: w.UserID}
+
+	for i, s := range w.Statuses {
+		m.Statuses = append(m.Statuses, StatusModel{
+			Key:      string(s.Key),
+			Name:     s.Name,
+			Category: string(s.Category),
+			Position: i,
+		})
+	}
+
+	for _, t := range w.Transitions {
+		m.Transitions = append(m.Transitions, TransitionModel{
+			FromKey: string(t.From),
+			ToKey:   string(t.To),
+		})
+	}
+
+	return m
+}
+
+func toDomain(m WorkflowModel) (domain.Workflow, error) {
+	statuses := make([]domain.WorkflowStatus, 0, len(m.Statuses))
+	for _, s := range m.Statuses {
+		statuses = append(statuses, domain.WorkflowStatus{
+			Key:      domain.Status(s.Key),
+			Name:     s.Name,
+			Category: domain.StatusCategory(s.Category),
+		})
+	}
+
+	transitions := make([]domain.Transition, 0, len(m.Transitions))
+	for _, t := range m.Transitions {
+		transitions = append(transitions, domain.Transition{
+			From: domain.Status(t.FromKey),
+			To:   domain.Status(t.ToKey),
+		})
+	}
+
+	return domain.NewWorkflowFromStorage(m.UserID, statuses, transitions)
+}
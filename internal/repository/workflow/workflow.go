@@ -0,0 +1,192 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+type StatusModel struct {
+	Key      string `db:"key"`
+	Name     string `db:"name"`
+	Category string `db:"category"`
+	Position int    `db:"position"`
+}
+
+type TransitionModel struct {
+	FromKey string `db:"from_key"`
+	ToKey   string `db:"to_key"`
+}
+
+type WorkflowModel struct {
+	ID          uuid.UUID  `db:"id"`
+	UserID      *uuid.UUID `db:"user_id"`
+	Statuses    []StatusModel
+	Transitions []TransitionModel
+}
+
+type WorkflowRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowRepository(db *pgxpool.Pool) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// Get returns the workflow of the user, falling back to the seeded default.
+func (r *WorkflowRepository) Get(ctx context.Context, userID uuid.UUID) (domain.Workflow, error) {
+	var m WorkflowModel
+
+	err := r.db.QueryRow(ctx, `
+		SELECT id, user_id
+		FROM workflows
+		WHERE user_id = $1 OR user_id IS NULL
+		ORDER BY user_id NULLS LAST
+		LIMIT 1
+	`, userID).Scan(&m.ID, &m.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Workflow{}, ErrWorkflowNotFound
+	}
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	statuses, err := r.db.Query(ctx, `
+		SELECT key, name, category, position
+		FROM workflow_statuses
+		WHERE workflow_id = $1
+		ORDER BY position
+	`, m.ID)
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+	m.Statuses, err = pgx.CollectRows(statuses, pgx.RowToStructByName[StatusModel])
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	transitions, err := r.db.Query(ctx, `
+		SELECT from_key, to_key
+		FROM workflow_transitions
+		WHERE workflow_id = $1
+		ORDER BY from_key, to_key
+	`, m.ID)
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+	m.Transitions, err = pgx.CollectRows(transitions, pgx.RowToStructByName[TransitionModel])
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	return toDomain(m)
+}
+
+// Save replaces the user's workflow with the given one. It fails with
+// domain.ErrStatusInUse when the user's tasks sit in a status the workflow
+// drops or gives another category.
+func (r *WorkflowRepository) Save(ctx context.Context, workflow domain.Workflow) (domain.Workflow, error) {
+	if workflow.UserID == nil {
+		return domain.Workflow{}, domain.ErrInvalidWorkflow
+	}
+
+	m := toModel(workflow)
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := checkReplaces(ctx, tx, *workflow.UserID, workflow); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM workflows WHERE user_id = $1`, m.UserID); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO workflows (user_id) VALUES ($1) RETURNING id`,
+			m.UserID,
+		).Scan(&m.ID); err != nil {
+			return err
+		}
+
+		for _, s := range m.Statuses {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO workflow_statuses (workflow_id, key, name, category, position)
+				VALUES ($1, $2, $3, $4, $5)
+			`, m.ID, s.Key, s.Name, s.Category, s.Position); err != nil {
+				return err
+			}
+		}
+
+		for _, t := range m.Transitions {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO workflow_transitions (workflow_id, from_key, to_key)
+				VALUES ($1, $2, $3)
+			`, m.ID, t.FromKey, t.ToKey); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	return toDomain(m)
+}
+
+// Delete removes the user's own workflow; the default one applies afterwards.
+// Like Save, it fails with domain.ErrStatusInUse when the default workflow
+// cannot hold the statuses of the user's tasks.
+func (r *WorkflowRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := checkReplaces(ctx, tx, userID, domain.DefaultWorkflow()); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `DELETE FROM workflows WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// checkReplaces checks that workflow can take the place of the user's one
+// while the tasks are in their current statuses. It locks the projects the
+// user owns and their tasks until the transaction ends, so that no task is
+// created in or moved to a status the check has not seen.
+func checkReplaces(ctx context.Context, tx pgx.Tx, userID uuid.UUID, workflow domain.Workflow) error {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM projects WHERE owner_id = $1 FOR UPDATE`, userID); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.status, t.category
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE p.owner_id = $1
+		FOR UPDATE OF t
+	`, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	inUse := make(map[domain.Status]domain.StatusCategory)
+	for rows.Next() {
+		var status, category string
+		if err := rows.Scan(&status, &category); err != nil {
+			return err
+		}
+		inUse[domain.Status(status)] = domain.StatusCategory(category)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return workflow.CheckReplaces(inUse)
+}
@@ -14,14 +14,22 @@ type TaskEventType string
 const (
 	TaskEventCreated   TaskEventType = "task_created"
 	TaskEventCompleted TaskEventType = "task_completed"
-	TaskEventDeleted   TaskEventType = "task_deleted"
+	// TaskEventReopened takes back an earlier task_completed.
+	TaskEventReopened     TaskEventType = "task_reopened"
+	TaskEventUpdated      TaskEventType = "task_updated"
+	TaskEventDeleted      TaskEventType = "task_deleted"
+	TaskEventCommentAdded TaskEventType = "comment_added"
+	TaskEventAssigned     TaskEventType = "task_assigned"
 )
 
 type TaskEvent struct {
-	Type      TaskEventType `json:"type"`
-	UserID    uuid.UUID     `json:"user_id"`
-	TaskID    uuid.UUID     `json:"task_id"`
-	CreatedAt time.Time     `json:"created_at"`
+	Type   TaskEventType `json:"type"`
+	UserID uuid.UUID     `json:"user_id"`
+	TaskID uuid.UUID     `json:"task_id"`
+	// AssigneeID is set on task_assigned and on completion events of assigned
+	// tasks.
+	AssigneeID *uuid.UUID `json:"assignee_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 type AnalyticsPublisher interface {
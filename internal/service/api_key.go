@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// API keys read tf_<prefix>_<secret>. The fixed marker tells them apart from
+// access tokens in the same Authorization header; the prefix finds the stored
+// key, the secret proves possession.
+const (
+	apiKeyMarker       = "tf_"
+	apiKeyPrefixLength = 8
+)
+
+var apiKeyPrefixEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key domain.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error)
+	// List returns the user's keys that have not been revoked.
+	List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id, userID uuid.UUID, revokedAt time.Time) error
+	// Touch records that the key was used. It may skip uses shortly after the
+	// recorded one.
+	Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+type APIKeyService struct {
+	APIKeyRepository APIKeyRepository
+	tokenService     *TokenService
+}
+
+func NewAPIKeyService(repository APIKeyRepository, tokenService *TokenService) *APIKeyService {
+	return &APIKeyService{
+		APIKeyRepository: repository,
+		tokenService:     tokenService,
+	}
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than an
+// access token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyMarker)
+}
+
+// CreateAPIKey stores a new key for the user and returns it together with the
+// full key, which is not kept and cannot be shown again.
+func (s *APIKeyService) CreateAPIKey(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	scopeNames []string,
+	expiresAt *time.Time,
+) (domain.APIKey, string, error) {
+	scopes, err := domain.ParseScopes(scopeNames)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	prefix, err := newAPIKeyPrefix()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	secret, err := s.tokenService.NewOpaqueToken()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	key, err := domain.NewAPIKey(userID, name, prefix, s.tokenService.HashOpaqueToken(secret), scopes, expiresAt)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	if err := s.APIKeyRepository.Create(ctx, key); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, apiKeyMarker + prefix + "_" + secret, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	return s.APIKeyRepository.List(ctx, userID)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	if err := s.APIKeyRepository.Revoke(ctx, keyID, userID, time.Now()); err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the stored key a request presented. Recording the use
+// is best effort; failing to do so does not reject the request.
+func (s *APIKeyService) Authenticate(ctx context.Context, credential string) (domain.APIKey, error) {
+	prefix, secret, ok := splitAPIKey(credential)
+	if !ok {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+
+	key, err := s.APIKeyRepository.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+
+	hash := s.tokenService.HashOpaqueToken(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(key.SecretHash)) != 1 {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if err := key.CheckUsable(now); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	_ = s.APIKeyRepository.Touch(ctx, key.ID, now)
+	key.LastUsedAt = &now
+
+	return key, nil
+}
+
+func splitAPIKey(credential string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(credential, apiKeyMarker)
+	if !ok || len(rest) <= apiKeyPrefixLength+1 || rest[apiKeyPrefixLength] != '_' {
+		return "", "", false
+	}
+
+	return rest[:apiKeyPrefixLength], rest[apiKeyPrefixLength+1:], true
+}
+
+func newAPIKeyPrefix() (string, error) {
+	b := make([]byte, apiKeyPrefixLength*5/8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate api key prefix: %w", err)
+	}
+
+	return strings.ToLower(apiKeyPrefixEncoding.EncodeToString(b)), nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIKeyService(t *testing.T) (*APIKeyService, *mocks.APIKeyRepository) {
+	repo := mocks.NewAPIKeyRepository(t)
+	return NewAPIKeyService(repo, NewTokenService("test-secret", mockTTL())), repo
+}
+
+func TestAPIKeyServiceCreatedKeyAuthenticates(t *testing.T) {
+	t.Parallel()
+
+	svc, repo := newTestAPIKeyService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var stored domain.APIKey
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(key domain.APIKey) bool {
+			stored = key
+			return key.UserID == userID && key.Name == "CI deploy"
+		})).
+		Return(nil).
+		Once()
+
+	key, secret, err := svc.CreateAPIKey(ctx, userID, " CI deploy ", []string{"tasks:read", "tasks:read"}, nil)
+	require.NoError(t, err)
+	require.True(t, IsAPIKey(secret))
+	require.True(t, strings.HasPrefix(secret, "tf_"+key.Prefix+"_"))
+	require.Equal(t, []domain.Scope{domain.ScopeTasksRead}, key.Scopes)
+	require.NotContains(t, stored.SecretHash, strings.TrimPrefix(secret, "tf_"+key.Prefix+"_"))
+
+	repo.On("GetByPrefix", ctx, key.Prefix).Return(stored, nil).Once()
+	repo.On("Touch", ctx, key.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	authenticated, err := svc.Authenticate(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, userID, authenticated.UserID)
+	require.NotNil(t, authenticated.LastUsedAt)
+}
+
+func TestAPIKeyServiceCreateRejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		err       error
+	}{
+		{name: "unknown scope", keyName: "ci", scopes: []string{"tasks:delete"}, err: domain.ErrUnknownScope},
+		{name: "no scopes", keyName: "ci", err: domain.ErrNoAPIKeyScopes},
+		{name: "account scope", keyName: "ci", scopes: []string{"tasks:read", "account"}, err: domain.ErrScopeNotGrantable},
+		{name: "empty name", keyName: " ", scopes: []string{"tasks:read"}, err: domain.ErrEmptyAPIKeyName},
+		{name: "expired", keyName: "ci", scopes: []string{"tasks:read"}, expiresAt: &past, err: domain.ErrInvalidAPIKeyTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, _ := newTestAPIKeyService(t)
+
+			_, _, err := svc.CreateAPIKey(context.Background(), uuid.New(), tt.keyName, tt.scopes, tt.expiresAt)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAPIKeyServiceAuthenticateRejectsUnusableKeys(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		modify func(key *domain.APIKey)
+		secret string
+		err    error
+	}{
+		{name: "wrong secret", modify: func(*domain.APIKey) {}, secret: "other", err: ErrInvalidAPIKey},
+		{name: "expired", modify: func(key *domain.APIKey) { key.ExpiresAt = &past }, err: domain.ErrAPIKeyExpired},
+		{name: "revoked", modify: func(key *domain.APIKey) { key.RevokedAt = &past }, err: domain.ErrAPIKeyRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, repo := newTestAPIKeyService(t)
+			ctx := context.Background()
+
+			key := domain.APIKey{
+				ID:         uuid.New(),
+				UserID:     uuid.New(),
+				Prefix:     "abcdefgh",
+				SecretHash: svc.tokenService.HashOpaqueToken("secret"),
+				Scopes:     []domain.Scope{domain.ScopeTasksRead},
+			}
+			tt.modify(&key)
+			secret := tt.secret
+			if secret == "" {
+				secret = "secret"
+			}
+
+			repo.On("GetByPrefix", ctx, "abcdefgh").Return(key, nil).Once()
+
+			_, err := svc.Authenticate(ctx, "tf_abcdefgh_"+secret)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAPIKeyServiceAuthenticateRejectsMalformedKeys(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newTestAPIKeyService(t)
+
+	for _, credential := range []string{"tf_", "tf_abcdefgh", "tf_abcdefgh_", "tf_abcdefghsecret", "abcdefgh_secret"} {
+		_, err := svc.Authenticate(context.Background(), credential)
+		require.ErrorIs(t, err, ErrInvalidAPIKey, credential)
+	}
+}
+
+func TestAPIKeyServiceRevokeReportsMissingKey(t *testing.T) {
+	t.Parallel()
+
+	svc, repo := newTestAPIKeyService(t)
+	ctx := context.Background()
+	userID, keyID := uuid.New(), uuid.New()
+
+	repo.
+		On("Revoke", ctx, keyID, userID, mock.AnythingOfType("time.Time")).
+		Return(errors.New("api key not found")).
+		Once()
+
+	require.ErrorIs(t, svc.RevokeAPIKey(ctx, userID, keyID), ErrAPIKeyNotFound)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssignTask hands the task to a member of its project. Assigning the current
+// assignee again is a no-op.
+func (s *TaskService) AssignTask(
+	ctx context.Context,
+	userID, taskID, assigneeID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if task.AssigneeID != nil && *task.AssigneeID == assigneeID {
+		return task, nil
+	}
+
+	member, err := s.assignee(ctx, task, assigneeID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := task.Assign(member); err != nil {
+		return domain.Task{}, err
+	}
+
+	updatedTask, err := s.TaskRepository.Update(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+	s.publishAssigned(ctx, userID, updatedTask)
+
+	return updatedTask, nil
+}
+
+// UnassignTask leaves the task without an assignee.
+func (s *TaskService) UnassignTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if task.AssigneeID == nil {
+		return task, nil
+	}
+
+	task.Unassign()
+
+	updatedTask, err := s.TaskRepository.Update(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+
+	return updatedTask, nil
+}
+
+// assignee looks up the membership of the user the task is being assigned to.
+// Without a project repository only the creator may be assigned.
+func (s *TaskService) assignee(ctx context.Context, task domain.Task, assigneeID uuid.UUID) (domain.ProjectMember, error) {
+	if s.ProjectRepository == nil {
+		if assigneeID != task.UserID {
+			return domain.ProjectMember{}, domain.ErrAssigneeNotMember
+		}
+		return domain.ProjectMember{ProjectID: task.ProjectID, UserID: assigneeID}, nil
+	}
+
+	member, err := s.ProjectRepository.Member(ctx, task.ProjectID, assigneeID)
+	if err != nil {
+		return domain.ProjectMember{}, domain.ErrAssigneeNotMember
+	}
+
+	return member, nil
+}
+
+// publishAssigned counts the task for its new assignee. AssignTask skips it
+// when the task goes to the member who already has it, so that reassigning
+// does not count the task twice.
+func (s *TaskService) publishAssigned(ctx context.Context, userID uuid.UUID, task domain.Task) {
+	_ = s.Analytics.PublishTaskEvent(ctx, TaskEvent{
+		Type:       TaskEventAssigned,
+		UserID:     userID,
+		TaskID:     task.ID,
+		AssigneeID: task.AssigneeID,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
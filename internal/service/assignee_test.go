@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskServiceAssignTask(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		isMember bool
+		wantErr  error
+	}{
+		{name: "member is assigned", isMember: true},
+		{name: "non-member is rejected", wantErr: domain.ErrAssigneeNotMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			projects := mocks.NewProjectRepository(t)
+			analytics := &recordingPublisher{}
+			svc := NewTaskService(repo, nil, nil, nil, nil, projects, analytics, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+			assigneeID := uuid.New()
+			task := domain.Task{ID: uuid.New(), UserID: userID, ProjectID: uuid.New(), Title: "Task", Version: 3}
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+			projects.
+				On("Member", ctx, task.ProjectID, userID).
+				Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: userID, Role: domain.RoleEditor}, nil).
+				Once()
+
+			var memberErr error
+			if !tt.isMember {
+				memberErr = errors.New("member not found")
+			}
+			projects.
+				On("Member", ctx, task.ProjectID, assigneeID).
+				Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: assigneeID, Role: domain.RoleViewer}, memberErr).
+				Once()
+
+			if tt.wantErr == nil {
+				repo.
+					On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+						return updated.AssigneeID != nil && *updated.AssigneeID == assigneeID
+					})).
+					Return(func(_ context.Context, updated domain.Task) (domain.Task, error) {
+						updated.Version++
+						return updated, nil
+					}).
+					Once()
+			}
+
+			assigned, err := svc.AssignTask(ctx, userID, task.ID, assigneeID, nil)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				require.Empty(t, analytics.events)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, assigneeID, *assigned.AssigneeID)
+			require.Len(t, analytics.events, 1)
+			require.Equal(t, TaskEventAssigned, analytics.events[0].Type)
+			require.Equal(t, userID, analytics.events[0].UserID)
+			require.Equal(t, assigneeID, *analytics.events[0].AssigneeID)
+		})
+	}
+}
+
+func TestTaskServiceAssignTaskToCurrentAssigneeIsNoop(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	analytics := &recordingPublisher{}
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, analytics, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, AssigneeID: &userID, Title: "Task"}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.AssignTask(ctx, userID, task.ID, userID, nil)
+
+	require.NoError(t, err)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	require.Empty(t, analytics.events)
+}
+
+func TestTaskServiceAssignTaskRequiresEditPermission(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	projects := mocks.NewProjectRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, projects, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: uuid.New(), ProjectID: uuid.New(), Title: "Task"}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	projects.
+		On("Member", ctx, task.ProjectID, userID).
+		Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: userID, Role: domain.RoleViewer}, nil).
+		Once()
+
+	_, err := svc.AssignTask(ctx, userID, task.ID, userID, nil)
+
+	require.ErrorIs(t, err, ErrForbidden)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceUnassignTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	assigneeID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, AssigneeID: &assigneeID, Title: "Task"}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.AssigneeID == nil
+		})).
+		Return(func(_ context.Context, updated domain.Task) (domain.Task, error) {
+			return updated, nil
+		}).
+		Once()
+
+	unassigned, err := svc.UnassignTask(ctx, userID, task.ID, nil)
+
+	require.NoError(t, err)
+	require.Nil(t, unassigned.AssigneeID)
+}
@@ -3,42 +3,325 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
+	"taskflow/internal/domain"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var ErrInvalidCredentials = errors.New("invalid credentials")
 
+// dummyPasswordHash is compared against when no user has the email, so that
+// unknown emails take as long to reject as wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("taskflow-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token domain.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	// Rotate marks the used token rotated and stores its successor. It fails
+	// with domain.ErrRefreshTokenReused when the token has been rotated or
+	// revoked in the meantime.
+	Rotate(ctx context.Context, used, next domain.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// TokenPair is what a client gets on authentication: a short-lived access
+// token and the refresh token that renews it.
+type TokenPair struct {
+	UserID       uuid.UUID
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is when the access token expires, or the MFA token when one
+	// is set.
+	ExpiresAt time.Time
+	// MFAToken is set in place of the other tokens when the user has to
+	// prove a second factor; it is exchanged with VerifyMFA.
+	MFAToken string
+}
+
 type AuthService struct {
 	userService  *UserService
 	tokenService *TokenService
+	// refreshTokens may be nil, in which case only access tokens are issued.
+	refreshTokens RefreshTokenRepository
+	refreshTTL    time.Duration
+	denylist      TokenDenylist
+	// verification may be nil, in which case no verification mail is sent on
+	// registration.
+	verification *EmailVerificationService
+	// mfa may be nil, in which case logins never ask for a second factor.
+	mfa *MFAService
+	// throttle may be nil, in which case failed logins are not limited.
+	throttle *LoginThrottle
 }
 
-func NewAuthService(userService *UserService, tokenService *TokenService) *AuthService {
+func NewAuthService(
+	userService *UserService,
+	tokenService *TokenService,
+	refreshTokens RefreshTokenRepository,
+	refreshTTL time.Duration,
+	denylist TokenDenylist,
+	verification *EmailVerificationService,
+	mfa *MFAService,
+	throttle *LoginThrottle,
+) *AuthService {
+	if denylist == nil {
+		denylist = NewMemoryTokenDenylist()
+	}
+
 	return &AuthService{
-		userService:  userService,
-		tokenService: tokenService,
+		userService:   userService,
+		tokenService:  tokenService,
+		refreshTokens: refreshTokens,
+		refreshTTL:    refreshTTL,
+		denylist:      denylist,
+		verification:  verification,
+		mfa:           mfa,
+		throttle:      throttle,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, email, password string) (string, error) {
+// Register creates an account and mails a link that verifies its email. A
+// mail that cannot be sent does not fail the registration; the user can ask
+// for another one.
+func (s *AuthService) Register(ctx context.Context, email, password string) (TokenPair, error) {
 	user, err := s.userService.CreateUser(ctx, email, password)
 	if err != nil {
-		return "", err
+		return TokenPair{}, err
+	}
+
+	if s.verification != nil {
+		_ = s.verification.Send(ctx, user)
 	}
 
-	return s.tokenService.Issue(user.ID)
+	return s.issue(ctx, user.ID)
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
+// Login checks the password. Users with two-factor authentication get only an
+// MFA token, which VerifyMFA exchanges for a pair once the second factor is
+// proven. Every attempt is counted per email and client IP before the
+// password is checked; once too many have failed, Login fails with a
+// *LoginThrottledError until the backoff passes.
+func (s *AuthService) Login(ctx context.Context, email, password, clientIP string) (TokenPair, error) {
+	if s.throttle != nil {
+		if err := s.throttle.Reserve(ctx, email, clientIP); err != nil {
+			return TokenPair{}, err
+		}
+	}
+
 	user, err := s.userService.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return TokenPair{}, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", ErrInvalidCredentials
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	if s.throttle != nil {
+		_ = s.throttle.Release(ctx, clientIP)
+	}
+
+	// With a second factor pending, the attempt stays counted against the
+	// email until VerifyMFA succeeds.
+	if s.mfa != nil {
+		token, expiresAt, err := s.mfa.challenge(ctx, user.ID)
+		if err != nil {
+			return TokenPair{}, err
+		}
+		if token != "" {
+			return TokenPair{UserID: user.ID, MFAToken: token, ExpiresAt: expiresAt}, nil
+		}
+	}
+
+	if s.throttle != nil {
+		_ = s.throttle.Reset(ctx, user.Email)
+	}
+
+	return s.issue(ctx, user.ID)
+}
+
+// unlock forgets the failed logins of the user's email once the user has
+// proven to own the account, by a password reset or a second factor.
+func (s *AuthService) unlock(ctx context.Context, userID uuid.UUID) error {
+	if s.throttle == nil {
+		return nil
+	}
+
+	user, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.throttle.Reset(ctx, user.Email)
+}
+
+// VerifyMFA completes a login with the MFA token it returned and a TOTP or
+// recovery code. Codes are counted per user like passwords, and a token is
+// burned once it has been tried too often or has completed its login.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code string) (TokenPair, error) {
+	if s.mfa == nil {
+		return TokenPair{}, ErrInvalidMFAToken
+	}
+
+	claims, err := s.mfa.pending(mfaToken)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	denied, err := s.denylist.IsDenied(ctx, claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if denied {
+		return TokenPair{}, ErrInvalidMFAToken
+	}
+
+	if s.throttle != nil {
+		err := s.throttle.ReserveMFA(ctx, claims.Subject, claims.ID)
+		if errors.Is(err, ErrInvalidMFAToken) {
+			_ = s.denylist.Deny(ctx, claims.ID, s.tokenService.acceptedUntil(claims.ExpiresAt))
+		}
+		if err != nil {
+			return TokenPair{}, err
+		}
+	}
+
+	if err := s.mfa.verify(ctx, claims.Subject, code); err != nil {
+		return TokenPair{}, err
+	}
+
+	// Burning the token is best effort: the denylist copy of this instance
+	// keeps it even when Redis cannot.
+	_ = s.denylist.Deny(ctx, claims.ID, s.tokenService.acceptedUntil(claims.ExpiresAt))
+	if s.throttle != nil {
+		_ = s.throttle.ResetMFA(ctx, claims.Subject)
+		_ = s.unlock(ctx, claims.Subject)
+	}
+
+	return s.issue(ctx, claims.Subject)
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token is
+// rotated out; presenting it again revokes every token descended from the
+// same login, since either the client or an attacker holds a stolen copy.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	if s.refreshTokens == nil {
+		return TokenPair{}, ErrInvalidToken
+	}
+
+	used, err := s.refreshTokens.GetByHash(ctx, s.tokenService.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return TokenPair{}, ErrInvalidToken
+	}
+
+	token, err := s.tokenService.NewOpaqueToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	next, err := used.Rotate(s.tokenService.HashOpaqueToken(token), s.refreshTTL, time.Now())
+	if err == nil {
+		err = s.refreshTokens.Rotate(ctx, used, next)
+	}
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		if err := s.refreshTokens.RevokeFamily(ctx, used.FamilyID); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, domain.ErrRefreshTokenReused
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	pair, err := s.accessToken(used.UserID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	pair.RefreshToken = token
+
+	return pair, nil
+}
+
+// Logout revokes the access token the claims were read from and, when given,
+// the refresh token issued with it together with its family. Refresh tokens
+// of other users are ignored.
+func (s *AuthService) Logout(ctx context.Context, claims TokenClaims, refreshToken string) error {
+	if err := s.denylist.Deny(ctx, claims.ID, s.tokenService.acceptedUntil(claims.ExpiresAt)); err != nil {
+		return err
+	}
+
+	if refreshToken == "" || s.refreshTokens == nil {
+		return nil
+	}
+
+	token, err := s.refreshTokens.GetByHash(ctx, s.tokenService.HashOpaqueToken(refreshToken))
+	if err != nil || token.UserID != claims.Subject {
+		return nil
+	}
+
+	return s.refreshTokens.RevokeFamily(ctx, token.FamilyID)
+}
+
+// LogoutAll revokes every access and refresh token the user holds.
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.denylist.DenyUser(ctx, userID, time.Now(), s.tokenService.ttl+s.tokenService.validation.Leeway); err != nil {
+		return err
+	}
+
+	if s.refreshTokens == nil {
+		return nil
+	}
+
+	return s.refreshTokens.RevokeUser(ctx, userID)
+}
+
+// VerificationKeys returns the public keys access tokens may be verified with.
+func (s *AuthService) VerificationKeys() []VerificationKey {
+	return s.tokenService.VerificationKeys()
+}
+
+// issue starts a new refresh token family for the user.
+func (s *AuthService) issue(ctx context.Context, userID uuid.UUID) (TokenPair, error) {
+	pair, err := s.accessToken(userID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if s.refreshTokens == nil {
+		return pair, nil
+	}
+
+	token, err := s.tokenService.NewOpaqueToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := s.refreshTokens.Create(
+		ctx,
+		domain.NewRefreshToken(userID, s.tokenService.HashOpaqueToken(token), s.refreshTTL),
+	); err != nil {
+		return TokenPair{}, err
+	}
+	pair.RefreshToken = token
+
+	return pair, nil
+}
+
+func (s *AuthService) accessToken(userID uuid.UUID) (TokenPair, error) {
+	expiresAt := time.Now().Add(s.tokenService.ttl)
+
+	accessToken, err := s.tokenService.Issue(userID)
+	if err != nil {
+		return TokenPair{}, err
 	}
 
-	return s.tokenService.Issue(user.ID)
+	return TokenPair{UserID: userID, AccessToken: accessToken, ExpiresAt: expiresAt}, nil
 }
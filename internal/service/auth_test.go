@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"taskflow/internal/domain"
 	"taskflow/mocks"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -18,7 +21,7 @@ func TestAuthServiceRegisterReturnsToken(t *testing.T) {
 	repo := mocks.NewUserRepository(t)
 	userService := NewUserService(repo)
 	tokenService := NewTokenService("test-secret", mockTTL())
-	authService := NewAuthService(userService, tokenService)
+	authService := NewAuthService(userService, tokenService, nil, 0, nil, nil, nil, nil)
 	ctx := context.Background()
 	createdUser := domain.User{
 		ID:           uuid.New(),
@@ -31,11 +34,12 @@ func TestAuthServiceRegisterReturnsToken(t *testing.T) {
 		Return(createdUser, nil).
 		Once()
 
-	token, err := authService.Register(ctx, "user@example.com", "secret")
+	tokens, err := authService.Register(ctx, "user@example.com", "secret")
 
 	require.NoError(t, err)
+	require.Equal(t, createdUser.ID, tokens.UserID)
 
-	parsedUserID, err := tokenService.Parse(token)
+	parsedUserID, err := tokenService.Parse(tokens.AccessToken)
 	require.NoError(t, err)
 	require.Equal(t, createdUser.ID, parsedUserID)
 	repo.AssertExpectations(t)
@@ -47,7 +51,7 @@ func TestAuthServiceRegisterPropagatesCreateUserError(t *testing.T) {
 	repo := mocks.NewUserRepository(t)
 	userService := NewUserService(repo)
 	tokenService := NewTokenService("test-secret", mockTTL())
-	authService := NewAuthService(userService, tokenService)
+	authService := NewAuthService(userService, tokenService, nil, 0, nil, nil, nil, nil)
 
 	_, err := authService.Register(context.Background(), "user@example.com", "")
 
@@ -60,7 +64,7 @@ func TestAuthServiceLoginReturnsToken(t *testing.T) {
 	repo := mocks.NewUserRepository(t)
 	userService := NewUserService(repo)
 	tokenService := NewTokenService("test-secret", mockTTL())
-	authService := NewAuthService(userService, tokenService)
+	authService := NewAuthService(userService, tokenService, nil, 0, nil, nil, nil, nil)
 	ctx := context.Background()
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
 	require.NoError(t, err)
@@ -75,11 +79,11 @@ func TestAuthServiceLoginReturnsToken(t *testing.T) {
 		Return(user, nil).
 		Once()
 
-	token, err := authService.Login(ctx, "user@example.com", "secret")
+	tokens, err := authService.Login(ctx, "user@example.com", "secret", "")
 
 	require.NoError(t, err)
 
-	parsedUserID, err := tokenService.Parse(token)
+	parsedUserID, err := tokenService.Parse(tokens.AccessToken)
 	require.NoError(t, err)
 	require.Equal(t, user.ID, parsedUserID)
 	repo.AssertExpectations(t)
@@ -91,7 +95,7 @@ func TestAuthServiceLoginRejectsUnknownUser(t *testing.T) {
 	repo := mocks.NewUserRepository(t)
 	userService := NewUserService(repo)
 	tokenService := NewTokenService("test-secret", mockTTL())
-	authService := NewAuthService(userService, tokenService)
+	authService := NewAuthService(userService, tokenService, nil, 0, nil, nil, nil, nil)
 	ctx := context.Background()
 
 	repo.
@@ -99,7 +103,7 @@ func TestAuthServiceLoginRejectsUnknownUser(t *testing.T) {
 		Return(domain.User{}, assertErrUserNotFound()).
 		Once()
 
-	_, err := authService.Login(ctx, "user@example.com", "secret")
+	_, err := authService.Login(ctx, "user@example.com", "secret", "")
 
 	require.ErrorIs(t, err, ErrInvalidCredentials)
 	repo.AssertExpectations(t)
@@ -111,7 +115,7 @@ func TestAuthServiceLoginRejectsWrongPassword(t *testing.T) {
 	repo := mocks.NewUserRepository(t)
 	userService := NewUserService(repo)
 	tokenService := NewTokenService("test-secret", mockTTL())
-	authService := NewAuthService(userService, tokenService)
+	authService := NewAuthService(userService, tokenService, nil, 0, nil, nil, nil, nil)
 	ctx := context.Background()
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
 	require.NoError(t, err)
@@ -125,8 +129,296 @@ func TestAuthServiceLoginRejectsWrongPassword(t *testing.T) {
 		}, nil).
 		Once()
 
-	_, err = authService.Login(ctx, "user@example.com", "wrong-password")
+	_, err = authService.Login(ctx, "user@example.com", "wrong-password", "")
 
 	require.ErrorIs(t, err, ErrInvalidCredentials)
 	repo.AssertExpectations(t)
 }
+
+func TestAuthServiceLoginThrottlesFailedAttempts(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewUserRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	authService := NewAuthService(NewUserService(repo), tokenService, nil, 0, nil, nil, nil, throttle)
+	ctx := context.Background()
+
+	repo.
+		On("GetByEmail", ctx, "user@example.com").
+		Return(domain.User{}, assertErrUserNotFound()).
+		Times(3)
+
+	for range 3 {
+		_, err := authService.Login(ctx, "user@example.com", "secret", "10.0.0.1")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	_, err := authService.Login(ctx, "user@example.com", "secret", "10.0.0.1")
+
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	require.Positive(t, throttled.RetryAfter)
+	repo.AssertExpectations(t)
+}
+
+func TestAuthServiceLoginResetsFailuresOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewUserRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	authService := NewAuthService(NewUserService(repo), tokenService, nil, 0, nil, nil, nil, throttle)
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := domain.User{ID: uuid.New(), Email: "user@example.com", PasswordHash: string(passwordHash)}
+
+	repo.On("GetByEmail", ctx, user.Email).Return(user, nil)
+
+	for range 2 {
+		_, err = authService.Login(ctx, user.Email, "wrong-password", "")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+		_, err = authService.Login(ctx, user.Email, "wrong-password", "")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+
+		_, err = authService.Login(ctx, user.Email, "secret", "")
+		require.NoError(t, err)
+	}
+}
+
+func TestAuthServiceLoginStoresHashedRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewUserRepository(t)
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	authService := NewAuthService(NewUserService(repo), tokenService, refreshTokens, time.Hour, nil, nil, nil, nil)
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := domain.User{ID: uuid.New(), Email: "user@example.com", PasswordHash: string(passwordHash)}
+
+	var stored domain.RefreshToken
+	repo.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+	refreshTokens.
+		On("Create", ctx, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(domain.RefreshToken) }).
+		Return(nil).
+		Once()
+
+	tokens, err := authService.Login(ctx, user.Email, "secret", "")
+
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.RefreshToken)
+	require.Equal(t, user.ID, stored.UserID)
+	require.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+	require.Equal(t, tokenService.HashOpaqueToken(tokens.RefreshToken), stored.TokenHash)
+}
+
+func TestAuthServiceRefreshRotatesToken(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, nil, nil, nil, nil)
+	ctx := context.Background()
+	used := domain.NewRefreshToken(uuid.New(), tokenService.HashOpaqueToken("old-token"), time.Hour)
+
+	var next domain.RefreshToken
+	refreshTokens.On("GetByHash", ctx, used.TokenHash).Return(used, nil).Once()
+	refreshTokens.
+		On("Rotate", ctx, mock.MatchedBy(func(token domain.RefreshToken) bool {
+			return token.ID == used.ID && token.RotatedAt != nil
+		}), mock.Anything).
+		Run(func(args mock.Arguments) { next = args.Get(2).(domain.RefreshToken) }).
+		Return(nil).
+		Once()
+
+	tokens, err := authService.Refresh(ctx, "old-token")
+
+	require.NoError(t, err)
+	require.Equal(t, used.UserID, tokens.UserID)
+	require.Equal(t, used.FamilyID, next.FamilyID)
+	require.Equal(t, tokenService.HashOpaqueToken(tokens.RefreshToken), next.TokenHash)
+
+	userID, err := tokenService.Parse(tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, used.UserID, userID)
+}
+
+func TestAuthServiceRefreshRevokesFamilyOnReuse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// rotated marks the presented token as already exchanged.
+		rotated bool
+		// rotateErr is what storing the rotation returns, as when a
+		// concurrent request rotated the token first.
+		rotateErr error
+	}{
+		{name: "token rotated earlier", rotated: true},
+		{name: "token rotated concurrently", rotateErr: domain.ErrRefreshTokenReused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			refreshTokens := mocks.NewRefreshTokenRepository(t)
+			tokenService := NewTokenService("test-secret", mockTTL())
+			authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, nil, nil, nil, nil)
+			ctx := context.Background()
+			used := domain.NewRefreshToken(uuid.New(), tokenService.HashOpaqueToken("old-token"), time.Hour)
+			if tt.rotated {
+				rotatedAt := time.Now().Add(-time.Minute)
+				used.RotatedAt = &rotatedAt
+			}
+
+			refreshTokens.On("GetByHash", ctx, used.TokenHash).Return(used, nil).Once()
+			if !tt.rotated {
+				refreshTokens.On("Rotate", ctx, mock.Anything, mock.Anything).Return(tt.rotateErr).Once()
+			}
+			refreshTokens.On("RevokeFamily", ctx, used.FamilyID).Return(nil).Once()
+
+			_, err := authService.Refresh(ctx, "old-token")
+
+			require.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		})
+	}
+}
+
+func TestAuthServiceRefreshRejectsUnusableTokens(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, nil, nil, nil, nil)
+	ctx := context.Background()
+	expired := domain.NewRefreshToken(uuid.New(), tokenService.HashOpaqueToken("expired-token"), -time.Second)
+
+	refreshTokens.
+		On("GetByHash", ctx, tokenService.HashOpaqueToken("unknown-token")).
+		Return(domain.RefreshToken{}, errors.New("refresh token not found")).
+		Once()
+	refreshTokens.On("GetByHash", ctx, expired.TokenHash).Return(expired, nil).Once()
+
+	_, err := authService.Refresh(ctx, "unknown-token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = authService.Refresh(ctx, "expired-token")
+	require.ErrorIs(t, err, domain.ErrRefreshTokenExpired)
+
+	refreshTokens.AssertNotCalled(t, "RevokeFamily", mock.Anything, mock.Anything)
+}
+
+func TestAuthServiceLogoutRevokesTokens(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	denylist := NewMemoryTokenDenylist()
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, denylist, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	refresh := domain.NewRefreshToken(userID, tokenService.HashOpaqueToken("refresh-token"), time.Hour)
+
+	accessToken, err := tokenService.Issue(userID)
+	require.NoError(t, err)
+	claims, err := tokenService.ParseClaims(accessToken)
+	require.NoError(t, err)
+
+	refreshTokens.On("GetByHash", ctx, refresh.TokenHash).Return(refresh, nil).Once()
+	refreshTokens.On("RevokeFamily", ctx, refresh.FamilyID).Return(nil).Once()
+
+	require.NoError(t, authService.Logout(ctx, claims, "refresh-token"))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+}
+
+func TestAuthServiceLogoutDeniesTokenWithinLeeway(t *testing.T) {
+	t.Parallel()
+
+	tokenService := NewKeyringTokenService(newTestKeyring(t), time.Hour, TokenValidation{Leeway: time.Minute})
+	denylist := NewMemoryTokenDenylist()
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, nil, time.Hour, denylist, nil, nil, nil)
+	ctx := context.Background()
+	// The token expired a second ago but still passes validation for the leeway.
+	claims := TokenClaims{ID: uuid.NewString(), Subject: uuid.New(), IssuedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(-time.Second)}
+
+	require.NoError(t, authService.Logout(ctx, claims, ""))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+}
+
+func TestAuthServiceLogoutAllDeniesTokensWithinLeeway(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewKeyringTokenService(newTestKeyring(t), time.Hour, TokenValidation{Leeway: time.Minute})
+	denylist := &recordingDenylist{TokenDenylist: NewMemoryTokenDenylist()}
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, denylist, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	refreshTokens.On("RevokeUser", ctx, userID).Return(nil).Once()
+
+	require.NoError(t, authService.LogoutAll(ctx, userID))
+	require.Equal(t, time.Hour+time.Minute, denylist.userTTL)
+}
+
+// recordingDenylist remembers the ttl of the last DenyUser.
+type recordingDenylist struct {
+	TokenDenylist
+	userTTL time.Duration
+}
+
+func (d *recordingDenylist) DenyUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, ttl time.Duration) error {
+	d.userTTL = ttl
+	return d.TokenDenylist.DenyUser(ctx, userID, cutoff, ttl)
+}
+
+func TestAuthServiceLogoutIgnoresRefreshTokensOfOthers(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, nil, nil, nil, nil)
+	ctx := context.Background()
+	refresh := domain.NewRefreshToken(uuid.New(), tokenService.HashOpaqueToken("refresh-token"), time.Hour)
+	claims := TokenClaims{ID: uuid.NewString(), Subject: uuid.New(), IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+
+	refreshTokens.On("GetByHash", ctx, refresh.TokenHash).Return(refresh, nil).Once()
+
+	require.NoError(t, authService.Logout(ctx, claims, "refresh-token"))
+	refreshTokens.AssertNotCalled(t, "RevokeFamily", mock.Anything, mock.Anything)
+}
+
+func TestAuthServiceLogoutAllRevokesEverySession(t *testing.T) {
+	t.Parallel()
+
+	refreshTokens := mocks.NewRefreshTokenRepository(t)
+	tokenService := NewTokenService("test-secret", mockTTL())
+	denylist := NewMemoryTokenDenylist()
+	authService := NewAuthService(NewUserService(mocks.NewUserRepository(t)), tokenService, refreshTokens, time.Hour, denylist, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	accessToken, err := tokenService.Issue(userID)
+	require.NoError(t, err)
+	claims, err := tokenService.ParseClaims(accessToken)
+	require.NoError(t, err)
+
+	refreshTokens.On("RevokeUser", ctx, userID).Return(nil).Once()
+
+	require.NoError(t, authService.LogoutAll(ctx, userID))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"taskflow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ChecklistItemPatch describes a change to a checklist item. Nil fields are
+// left untouched.
+type ChecklistItemPatch struct {
+	Text    *string
+	Checked *bool
+}
+
+// AddChecklistItem appends an unchecked item to the checklist of the task.
+func (s *TaskService) AddChecklistItem(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	text string,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeChecklist(ctx, userID, taskID, expectedVersion, func(task *domain.Task) error {
+		_, err := task.AddChecklistItem(text)
+		return err
+	})
+}
+
+// UpdateChecklistItem edits the text of an item or checks and unchecks it.
+func (s *TaskService) UpdateChecklistItem(
+	ctx context.Context,
+	userID, taskID, itemID uuid.UUID,
+	patch ChecklistItemPatch,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeChecklist(ctx, userID, taskID, expectedVersion, func(task *domain.Task) error {
+		if patch.Text != nil {
+			if err := task.ChangeChecklistItemText(itemID, *patch.Text); err != nil {
+				return err
+			}
+		}
+
+		if patch.Checked != nil {
+			if err := task.CheckChecklistItem(itemID, *patch.Checked); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RemoveChecklistItem deletes an item; the items after it move up.
+func (s *TaskService) RemoveChecklistItem(
+	ctx context.Context,
+	userID, taskID, itemID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeChecklist(ctx, userID, taskID, expectedVersion, func(task *domain.Task) error {
+		return task.RemoveChecklistItem(itemID)
+	})
+}
+
+// ReorderChecklist puts the items in the given order, which must list each of
+// them once.
+func (s *TaskService) ReorderChecklist(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	itemIDs []uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeChecklist(ctx, userID, taskID, expectedVersion, func(task *domain.Task) error {
+		return task.ReorderChecklist(itemIDs)
+	})
+}
+
+func (s *TaskService) changeChecklist(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	expectedVersion *int64,
+	change func(task *domain.Task) error,
+) (domain.Task, error) {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := change(&task); err != nil {
+		return domain.Task{}, err
+	}
+
+	updatedTask, err := s.TaskRepository.SaveChecklist(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+
+	return updatedTask, nil
+}
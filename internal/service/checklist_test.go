@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func checklistTask(userID uuid.UUID, texts ...string) domain.Task {
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Release", Status: domain.StatusPending, Version: 3}
+	for i, text := range texts {
+		task.Checklist = append(task.Checklist, domain.ChecklistItem{ID: uuid.New(), Text: text, Position: i})
+	}
+	return task
+}
+
+func TestTaskServiceAddChecklistItemCachesTaskWithChecklist(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := checklistTask(userID, "Tag the build")
+
+	saved := task
+	saved.Checklist = append(saved.Checklist, domain.ChecklistItem{ID: uuid.New(), Text: "Write release notes", Position: 1})
+	saved.ChecklistProgress = domain.ChecklistProgress{Total: 2}
+	saved.Version = 4
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("SaveChecklist", ctx, mock.MatchedBy(func(t domain.Task) bool {
+			return len(t.Checklist) == 2 &&
+				t.Checklist[1].Text == "Write release notes" &&
+				t.Checklist[1].Position == 1 &&
+				!t.Checklist[1].Checked
+		})).
+		Return(saved, nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskCacheKey(task.ID), mock.MatchedBy(func(payload string) bool {
+			var cached domain.Task
+			return json.Unmarshal([]byte(payload), &cached) == nil &&
+				len(cached.Checklist) == 2 &&
+				cached.ChecklistProgress.Total == 2
+		}), taskCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(task.ID), "4", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+
+	updated, err := svc.AddChecklistItem(ctx, userID, task.ID, "  Write release notes ", nil)
+
+	require.NoError(t, err)
+	require.Len(t, updated.Checklist, 2)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceAddChecklistItemRejectsFullChecklist(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	texts := make([]string, domain.MaxChecklistItems)
+	for i := range texts {
+		texts[i] = "step " + strconv.Itoa(i)
+	}
+	task := checklistTask(userID, texts...)
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.AddChecklistItem(ctx, userID, task.ID, "one more", nil)
+
+	require.ErrorIs(t, err, domain.ErrChecklistFull)
+	repo.AssertNotCalled(t, "SaveChecklist", mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceUpdateChecklistItemTogglesItem(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := checklistTask(userID, "Tag the build", "Write release notes")
+	itemID := task.Checklist[1].ID
+	checked := true
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("SaveChecklist", ctx, mock.MatchedBy(func(t domain.Task) bool {
+			return !t.Checklist[0].Checked && t.Checklist[1].Checked
+		})).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.UpdateChecklistItem(ctx, userID, task.ID, itemID, ChecklistItemPatch{Checked: &checked}, nil)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceUpdateChecklistItemReturnsNotFound(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := checklistTask(userID, "Tag the build")
+	checked := true
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.UpdateChecklistItem(ctx, userID, task.ID, uuid.New(), ChecklistItemPatch{Checked: &checked}, nil)
+
+	require.ErrorIs(t, err, domain.ErrChecklistItemNotFound)
+	repo.AssertNotCalled(t, "SaveChecklist", mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceRemoveChecklistItemRenumbersItems(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := checklistTask(userID, "first", "second", "third")
+	removed := task.Checklist[0].ID
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("SaveChecklist", ctx, mock.MatchedBy(func(t domain.Task) bool {
+			return len(t.Checklist) == 2 &&
+				t.Checklist[0].Text == "second" && t.Checklist[0].Position == 0 &&
+				t.Checklist[1].Text == "third" && t.Checklist[1].Position == 1
+		})).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.RemoveChecklistItem(ctx, userID, task.ID, removed, nil)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceReorderChecklist(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	base := checklistTask(userID, "first", "second", "third")
+	first, second, third := base.Checklist[0].ID, base.Checklist[1].ID, base.Checklist[2].ID
+
+	tests := []struct {
+		name string
+		ids  []uuid.UUID
+		err  error
+	}{
+		{name: "new order", ids: []uuid.UUID{third, first, second}},
+		{name: "missing item", ids: []uuid.UUID{third, first}, err: domain.ErrInvalidChecklistOrder},
+		{name: "duplicate item", ids: []uuid.UUID{third, first, first}, err: domain.ErrInvalidChecklistOrder},
+		{name: "unknown item", ids: []uuid.UUID{third, first, uuid.New()}, err: domain.ErrInvalidChecklistOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+			ctx := context.Background()
+			task := base
+			task.Checklist = append([]domain.ChecklistItem(nil), base.Checklist...)
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+
+			if tt.err == nil {
+				repo.
+					On("SaveChecklist", ctx, mock.MatchedBy(func(t domain.Task) bool {
+						return t.Checklist[0].ID == third && t.Checklist[0].Position == 0 &&
+							t.Checklist[1].ID == first && t.Checklist[1].Position == 1 &&
+							t.Checklist[2].ID == second && t.Checklist[2].Position == 2
+					})).
+					Return(task, nil).
+					Once()
+			}
+
+			_, err := svc.ReorderChecklist(ctx, userID, task.ID, tt.ids, nil)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				repo.AssertNotCalled(t, "SaveChecklist", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskServiceChangeChecklistChecksPrecondition(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := checklistTask(userID, "first")
+	stale := task.Version - 1
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.AddChecklistItem(ctx, userID, task.ID, "second", &stale)
+
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	repo.AssertNotCalled(t, "SaveChecklist", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment domain.Comment) (domain.Comment, error)
+	Get(ctx context.Context, id, taskID uuid.UUID) (domain.Comment, error)
+	List(ctx context.Context, filter domain.CommentFilter) ([]domain.Comment, error)
+	Update(ctx context.Context, comment domain.Comment) (domain.Comment, error)
+	Delete(ctx context.Context, id, taskID uuid.UUID) error
+}
+
+// CommentPage is one page of a task's thread. NextCursor is empty on the last
+// page.
+type CommentPage struct {
+	Comments   []domain.Comment
+	NextCursor string
+}
+
+type CommentService struct {
+	CommentRepository CommentRepository
+	taskService       *TaskService
+}
+
+func NewCommentService(repository CommentRepository, taskService *TaskService) *CommentService {
+	return &CommentService{
+		CommentRepository: repository,
+		taskService:       taskService,
+	}
+}
+
+func (s *CommentService) AddComment(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	body string,
+) (domain.Comment, error) {
+	if _, err := s.taskService.readTask(ctx, userID, taskID, domain.PermissionComment); err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment, err := domain.NewComment(taskID, userID, body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return s.CommentRepository.Create(ctx, comment)
+}
+
+// ListComments returns the thread oldest first, a page at a time. cursor is
+// the NextCursor of the previous page, or empty for the first one.
+func (s *CommentService) ListComments(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	cursor string,
+	limit int,
+) (CommentPage, error) {
+	if _, err := s.taskService.GetTask(ctx, userID, taskID); err != nil {
+		return CommentPage{}, err
+	}
+
+	filter := domain.CommentFilter{TaskID: taskID, Limit: limit}
+	if cursor != "" {
+		after, err := domain.ParseCommentCursor(cursor)
+		if err != nil {
+			return CommentPage{}, err
+		}
+		filter.After = &after
+	}
+	filter.Normalize()
+
+	// One extra row tells whether another page follows.
+	pageSize := filter.Limit
+	filter.Limit++
+
+	comments, err := s.CommentRepository.List(ctx, filter)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	page := CommentPage{Comments: comments}
+	if len(comments) > pageSize {
+		page.Comments = comments[:pageSize]
+		page.NextCursor = page.Comments[pageSize-1].CursorAfter().String()
+	}
+
+	return page, nil
+}
+
+// EditComment replaces the body of a comment; only its author may do so.
+func (s *CommentService) EditComment(
+	ctx context.Context,
+	userID, taskID, commentID uuid.UUID,
+	body string,
+) (domain.Comment, error) {
+	comment, err := s.getComment(ctx, userID, taskID, commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	if err := comment.Edit(userID, body, time.Now()); err != nil {
+		return domain.Comment{}, err
+	}
+
+	return s.CommentRepository.Update(ctx, comment)
+}
+
+// DeleteComment removes a comment; only its author may do so.
+func (s *CommentService) DeleteComment(ctx context.Context, userID, taskID, commentID uuid.UUID) error {
+	comment, err := s.getComment(ctx, userID, taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if err := comment.CheckAuthor(userID); err != nil {
+		return err
+	}
+
+	return s.CommentRepository.Delete(ctx, commentID, taskID)
+}
+
+func (s *CommentService) getComment(
+	ctx context.Context,
+	userID, taskID, commentID uuid.UUID,
+) (domain.Comment, error) {
+	if _, err := s.taskService.readTask(ctx, userID, taskID, domain.PermissionComment); err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment, err := s.CommentRepository.Get(ctx, commentID, taskID)
+	if err != nil {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
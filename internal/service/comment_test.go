@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentServiceAddCommentKeepsMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	body := "    go test ./...\n\nfails on **CI**"
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(comment domain.Comment) bool {
+			return comment.TaskID == taskID &&
+				comment.AuthorID == userID &&
+				comment.Body == body &&
+				comment.EditedAt == nil
+		})).
+		Return(domain.Comment{ID: uuid.New(), TaskID: taskID, AuthorID: userID, Body: body}, nil).
+		Once()
+
+	comment, err := svc.AddComment(ctx, userID, taskID, "\n"+body+"  \n")
+
+	require.NoError(t, err)
+	require.Equal(t, body, comment.Body)
+	repo.AssertExpectations(t)
+}
+
+func TestCommentServiceAddCommentValidatesBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{name: "blank", body: " \n\t", err: domain.ErrEmptyComment},
+		{name: "too long", body: strings.Repeat("ж", domain.MaxCommentBodyLength+1), err: domain.ErrCommentTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tasks := mocks.NewTaskRepository(t)
+			repo := mocks.NewCommentRepository(t)
+			svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+			ctx := context.Background()
+			userID := uuid.New()
+			taskID := uuid.New()
+
+			tasks.
+				On("Get", ctx, taskID).
+				Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+				Once()
+
+			_, err := svc.AddComment(ctx, userID, taskID, tt.body)
+
+			require.ErrorIs(t, err, tt.err)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCommentServiceAddCommentReturnsTaskNotFound(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(domain.Task{}, errors.New("not found")).
+		Once()
+
+	_, err := svc.AddComment(ctx, userID, taskID, "hello")
+
+	require.ErrorIs(t, err, ErrTaskNotFound)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCommentServiceListCommentsPaginatesWithCursor(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	task := domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}
+
+	comments := make([]domain.Comment, 3)
+	for i := range comments {
+		comments[i] = domain.Comment{
+			ID:        uuid.New(),
+			TaskID:    taskID,
+			AuthorID:  userID,
+			Body:      "comment",
+			CreatedAt: mockTime().Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(task, nil).
+		Twice()
+	repo.
+		On("List", ctx, domain.CommentFilter{TaskID: taskID, Limit: 3}).
+		Return(comments, nil).
+		Once()
+
+	first, err := svc.ListComments(ctx, userID, taskID, "", 2)
+
+	require.NoError(t, err)
+	require.Equal(t, comments[:2], first.Comments)
+	require.NotEmpty(t, first.NextCursor)
+
+	after := comments[1].CursorAfter()
+	repo.
+		On("List", ctx, mock.MatchedBy(func(filter domain.CommentFilter) bool {
+			return filter.After != nil &&
+				filter.After.ID == after.ID &&
+				filter.After.CreatedAt.Equal(after.CreatedAt) &&
+				filter.Limit == 3
+		})).
+		Return(comments[2:], nil).
+		Once()
+
+	second, err := svc.ListComments(ctx, userID, taskID, first.NextCursor, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, comments[2:], second.Comments)
+	require.Empty(t, second.NextCursor)
+	repo.AssertExpectations(t)
+}
+
+func TestCommentServiceListCommentsRejectsInvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+
+	_, err := svc.ListComments(ctx, userID, taskID, "not-a-cursor", 0)
+
+	require.ErrorIs(t, err, domain.ErrInvalidCursor)
+	repo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestCommentServiceEditCommentRecordsEditTime(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	commentID := uuid.New()
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+	repo.
+		On("Get", ctx, commentID, taskID).
+		Return(domain.Comment{ID: commentID, TaskID: taskID, AuthorID: userID, Body: "old", CreatedAt: mockTime()}, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(comment domain.Comment) bool {
+			return comment.ID == commentID && comment.Body == "new" && comment.EditedAt != nil
+		})).
+		Return(domain.Comment{ID: commentID, TaskID: taskID, AuthorID: userID, Body: "new", EditedAt: new(time.Time)}, nil).
+		Once()
+
+	comment, err := svc.EditComment(ctx, userID, taskID, commentID, "new")
+
+	require.NoError(t, err)
+	require.NotNil(t, comment.EditedAt)
+	repo.AssertExpectations(t)
+}
+
+func TestCommentServiceRejectsChangesByOtherUsers(t *testing.T) {
+	t.Parallel()
+
+	tasks := mocks.NewTaskRepository(t)
+	repo := mocks.NewCommentRepository(t)
+	svc := NewCommentService(repo, NewTaskService(tasks, nil, nil, nil, nil, nil, nil, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	commentID := uuid.New()
+	comment := domain.Comment{ID: commentID, TaskID: taskID, AuthorID: uuid.New(), Body: "theirs"}
+
+	tasks.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Twice()
+	repo.
+		On("Get", ctx, commentID, taskID).
+		Return(comment, nil).
+		Twice()
+
+	_, err := svc.EditComment(ctx, userID, taskID, commentID, "mine now")
+	require.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+
+	err = svc.DeleteComment(ctx, userID, taskID, commentID)
+	require.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	deniedTokenKeyPrefix = "auth:denied:token:"
+	deniedUserKeyPrefix  = "auth:denied:user:"
+)
+
+// TokenDenylist rejects access tokens before they expire. Entries live only
+// as long as the tokens they reject could, so the list stays small.
+type TokenDenylist interface {
+	// Deny rejects the token with the id until expiresAt, which should be when
+	// the token stops passing validation, leeway included.
+	Deny(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// DenyUser rejects every token of the user issued up to the cutoff. Issue
+	// times are compared in milliseconds, so a token issued right after the
+	// cutoff, as when the user signs in again after a password reset, is
+	// accepted. Tokens that carry their issue time in whole seconds only are
+	// rejected throughout the cutoff's second. ttl is the longest an access
+	// token is accepted, leeway included.
+	DenyUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, ttl time.Duration) error
+	IsDenied(ctx context.Context, claims TokenClaims) (bool, error)
+}
+
+type memoryTokenDenylist struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	users  map[uuid.UUID]deniedUser
+}
+
+type deniedUser struct {
+	cutoff    time.Time
+	expiresAt time.Time
+}
+
+// redisTokenDenylist shares revocations through Redis: a token revoked on one
+// API instance must not keep working on the others.
+type redisTokenDenylist struct {
+	redisFallback[*memoryTokenDenylist]
+}
+
+func NewMemoryTokenDenylist() TokenDenylist {
+	return newMemoryTokenDenylist()
+}
+
+func newMemoryTokenDenylist() *memoryTokenDenylist {
+	return &memoryTokenDenylist{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]deniedUser),
+	}
+}
+
+func (d *memoryTokenDenylist) Deny(_ context.Context, tokenID string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(time.Now())
+	d.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (d *memoryTokenDenylist) DenyUser(_ context.Context, userID uuid.UUID, cutoff time.Time, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(time.Now())
+	d.users[userID] = deniedUser{cutoff: cutoff, expiresAt: cutoff.Add(ttl)}
+	return nil
+}
+
+func (d *memoryTokenDenylist) IsDenied(_ context.Context, claims TokenClaims) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := d.tokens[claims.ID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if user, ok := d.users[claims.Subject]; ok && now.Before(user.expiresAt) {
+		return claims.IssuedAt.UnixMilli() <= user.cutoff.UnixMilli(), nil
+	}
+	return false, nil
+}
+
+// prune drops the entries of tokens that have expired anyway.
+func (d *memoryTokenDenylist) prune(now time.Time) {
+	for id, expiresAt := range d.tokens {
+		if !now.Before(expiresAt) {
+			delete(d.tokens, id)
+		}
+	}
+	for id, user := range d.users {
+		if !now.Before(user.expiresAt) {
+			delete(d.users, id)
+		}
+	}
+}
+
+// NewRedisTokenDenylist keeps the denylist in memory when there is no Redis
+// client; a token revoked there stays valid on other instances.
+func NewRedisTokenDenylist(client redis.Cmdable) TokenDenylist {
+	if client == nil {
+		return NewMemoryTokenDenylist()
+	}
+
+	return &redisTokenDenylist{newRedisFallback(client, newMemoryTokenDenylist())}
+}
+
+func (d *redisTokenDenylist) Deny(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return d.write(
+		func(local *memoryTokenDenylist) error { return local.Deny(ctx, tokenID, expiresAt) },
+		func(client redis.Cmdable) error {
+			ttl := time.Until(expiresAt)
+			if ttl <= 0 {
+				return nil
+			}
+
+			return client.Set(ctx, deniedTokenKeyPrefix+tokenID, "1", ttl).Err()
+		},
+	)
+}
+
+func (d *redisTokenDenylist) DenyUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, ttl time.Duration) error {
+	return d.write(
+		func(local *memoryTokenDenylist) error { return local.DenyUser(ctx, userID, cutoff, ttl) },
+		func(client redis.Cmdable) error {
+			return client.Set(ctx, deniedUserKeyPrefix+userID.String(), formatCutoff(cutoff), ttl).Err()
+		},
+	)
+}
+
+func (d *redisTokenDenylist) IsDenied(ctx context.Context, claims TokenClaims) (bool, error) {
+	return query(d.redisFallback,
+		func(local *memoryTokenDenylist) (bool, error) { return local.IsDenied(ctx, claims) },
+		func(client redis.Cmdable) (bool, error) {
+			values, err := client.MGet(ctx, deniedTokenKeyPrefix+claims.ID, deniedUserKeyPrefix+claims.Subject.String()).Result()
+			if err != nil {
+				return false, err
+			}
+
+			if values[0] != nil {
+				return true, nil
+			}
+			if value, ok := values[1].(string); ok {
+				cutoff, err := parseCutoff(value)
+				if err != nil {
+					return true, nil
+				}
+				return claims.IssuedAt.UnixMilli() <= cutoff.UnixMilli(), nil
+			}
+
+			return false, nil
+		},
+	)
+}
+
+// formatCutoff writes the cutoff as Unix seconds with milliseconds after the
+// point, so that cutoffs stored in whole seconds before still parse.
+func formatCutoff(cutoff time.Time) string {
+	return strconv.FormatFloat(float64(cutoff.UnixMilli())/1000, 'f', 3, 64)
+}
+
+func parseCutoff(value string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(int64(math.Round(seconds * 1000))), nil
+}
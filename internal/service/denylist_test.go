@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTokenDenylistDeniesToken(t *testing.T) {
+	t.Parallel()
+
+	denylist := NewMemoryTokenDenylist()
+	ctx := context.Background()
+	claims := TokenClaims{ID: uuid.NewString(), Subject: uuid.New(), IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+	other := TokenClaims{ID: uuid.NewString(), Subject: claims.Subject, IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+
+	require.NoError(t, denylist.Deny(ctx, claims.ID, claims.ExpiresAt))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+
+	denied, err = denylist.IsDenied(ctx, other)
+	require.NoError(t, err)
+	require.False(t, denied)
+}
+
+func TestMemoryTokenDenylistDeniesTokensIssuedBeforeUserCutoff(t *testing.T) {
+	t.Parallel()
+
+	denylist := NewMemoryTokenDenylist()
+	ctx := context.Background()
+	userID := uuid.New()
+	cutoff := time.Now()
+
+	require.NoError(t, denylist.DenyUser(ctx, userID, cutoff, time.Hour))
+
+	denied, err := denylist.IsDenied(ctx, TokenClaims{ID: "old", Subject: userID, IssuedAt: cutoff.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.True(t, denied)
+
+	denied, err = denylist.IsDenied(ctx, TokenClaims{ID: "new", Subject: userID, IssuedAt: cutoff.Add(time.Minute)})
+	require.NoError(t, err)
+	require.False(t, denied)
+
+	denied, err = denylist.IsDenied(ctx, TokenClaims{ID: "other", Subject: uuid.New(), IssuedAt: cutoff.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.False(t, denied)
+}
+
+func TestTokenDenylistAcceptsTokenIssuedRightAfterUserCutoff(t *testing.T) {
+	t.Parallel()
+
+	denylist := NewMemoryTokenDenylist()
+	tokens := NewTokenService("test-secret", time.Hour)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	parse := func() TokenClaims {
+		token, err := tokens.Issue(userID)
+		require.NoError(t, err)
+		claims, err := tokens.ParseClaims(token)
+		require.NoError(t, err)
+		return claims
+	}
+
+	before := parse()
+	require.NoError(t, denylist.DenyUser(ctx, userID, time.Now(), time.Hour))
+	time.Sleep(2 * time.Millisecond)
+	// Most likely within the same second as the cutoff.
+	after := parse()
+
+	denied, err := denylist.IsDenied(ctx, before)
+	require.NoError(t, err)
+	require.True(t, denied)
+
+	denied, err = denylist.IsDenied(ctx, after)
+	require.NoError(t, err)
+	require.False(t, denied)
+}
+
+func TestTokenDenylistDeniesWholeSecondTokensThroughoutCutoffSecond(t *testing.T) {
+	t.Parallel()
+
+	denylist := NewMemoryTokenDenylist()
+	ctx := context.Background()
+	userID := uuid.New()
+	second := time.Now().Truncate(time.Second)
+
+	require.NoError(t, denylist.DenyUser(ctx, userID, second.Add(500*time.Millisecond), time.Hour))
+
+	// A token without a millisecond issue time may have been issued anywhere
+	// in its second, so it is not trusted before the next one.
+	denied, err := denylist.IsDenied(ctx, TokenClaims{ID: "legacy", Subject: userID, IssuedAt: second})
+	require.NoError(t, err)
+	require.True(t, denied)
+
+	denied, err = denylist.IsDenied(ctx, TokenClaims{ID: "next", Subject: userID, IssuedAt: second.Add(time.Second)})
+	require.NoError(t, err)
+	require.False(t, denied)
+}
+
+func TestTokenDenylistCutoffFormat(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.UnixMilli(1_760_700_000_123)
+
+	value := formatCutoff(cutoff)
+	require.Equal(t, "1760700000.123", value)
+
+	parsed, err := parseCutoff(value)
+	require.NoError(t, err)
+	require.True(t, parsed.Equal(cutoff))
+
+	// Cutoffs stored in whole seconds before keep working.
+	parsed, err = parseCutoff("1760700000")
+	require.NoError(t, err)
+	require.True(t, parsed.Equal(time.Unix(1_760_700_000, 0)))
+}
+
+func TestMemoryTokenDenylistForgetsExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	denylist := NewMemoryTokenDenylist()
+	ctx := context.Background()
+	claims := TokenClaims{ID: uuid.NewString(), Subject: uuid.New(), IssuedAt: time.Now().Add(-time.Hour)}
+
+	require.NoError(t, denylist.Deny(ctx, claims.ID, time.Now().Add(-time.Second)))
+	require.NoError(t, denylist.DenyUser(ctx, claims.Subject, time.Now().Add(-time.Minute), time.Second))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.False(t, denied)
+}
+
+func TestRedisTokenDenylistFallsBackToMemory(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() { _ = client.Close() })
+	denylist := NewRedisTokenDenylist(client)
+	ctx := context.Background()
+	claims := TokenClaims{ID: uuid.NewString(), Subject: uuid.New(), IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+
+	require.Error(t, denylist.Deny(ctx, claims.ID, claims.ExpiresAt))
+
+	denied, err := denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+}
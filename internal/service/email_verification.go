@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	ErrVerificationTokenExpired = errors.New("verification token expired")
+	ErrEmailAlreadyVerified     = errors.New("email already verified")
+)
+
+// emailVerificationTokenPurpose keeps verification tokens apart from access
+// tokens signed with the same keys.
+const emailVerificationTokenPurpose = "email_verification"
+
+type EmailVerificationService struct {
+	userService  *UserService
+	tokenService *TokenService
+	mailer       Mailer
+	// verifyURL is the page the verification mail links to; the token is
+	// appended as a query parameter.
+	verifyURL string
+	ttl       time.Duration
+	// throttle may be nil, in which case resending is not limited.
+	throttle *LoginThrottle
+}
+
+func NewEmailVerificationService(
+	userService *UserService,
+	tokenService *TokenService,
+	mailer Mailer,
+	verifyURL string,
+	ttl time.Duration,
+	throttle *LoginThrottle,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userService:  userService,
+		tokenService: tokenService,
+		mailer:       mailer,
+		verifyURL:    verifyURL,
+		ttl:          ttl,
+		throttle:     throttle,
+	}
+}
+
+// Send mails the user a link that verifies their email.
+func (s *EmailVerificationService) Send(ctx context.Context, user domain.User) error {
+	token, err := s.tokenService.IssueFor(emailVerificationTokenPurpose, user.ID, s.ttl)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, s.verificationMail(user, token)); err != nil {
+		return fmt.Errorf("send email verification: %w", err)
+	}
+
+	return nil
+}
+
+// Resend mails a new verification link to a user who has not verified yet.
+// Requests are throttled per user and fail with a *LoginThrottledError while
+// the backoff runs.
+func (s *EmailVerificationService) Resend(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	if s.throttle != nil {
+		if err := s.throttle.ReserveVerification(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.Send(ctx, user)
+}
+
+// Verify marks the email of the user the token was issued for verified.
+// Verifying an already verified email again succeeds.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	userID, err := s.tokenService.ParseFor(emailVerificationTokenPurpose, token)
+	if errors.Is(err, ErrTokenExpired) {
+		return ErrVerificationTokenExpired
+	}
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	user.VerifyEmail(time.Now())
+	return s.userService.UserRepository.VerifyEmail(ctx, user.ID, *user.EmailVerifiedAt)
+}
+
+func (s *EmailVerificationService) verificationMail(user domain.User, token string) Mail {
+	return Mail{
+		To:      user.Email,
+		Subject: "Verify your email for Taskflow",
+		Body: fmt.Sprintf(
+			"Confirm that %s is your email address: %s?token=%s\n\n"+
+				"The link expires on %s. If you did not sign up for Taskflow, ignore this mail.",
+			user.Email,
+			s.verifyURL,
+			token,
+			time.Now().Add(s.ttl).UTC().Format(time.RFC1123),
+		),
+	}
+}
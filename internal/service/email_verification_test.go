@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type emailVerificationFixture struct {
+	users  *mocks.UserRepository
+	tokens *TokenService
+	mailer *recordingMailer
+	svc    *EmailVerificationService
+}
+
+func newEmailVerificationFixture(t *testing.T) emailVerificationFixture {
+	f := emailVerificationFixture{
+		users:  mocks.NewUserRepository(t),
+		tokens: NewTokenService("test-secret", mockTTL()),
+		mailer: &recordingMailer{},
+	}
+	f.svc = NewEmailVerificationService(
+		NewUserService(f.users),
+		f.tokens,
+		f.mailer,
+		"https://taskflow.test/verify-email",
+		time.Hour,
+		nil,
+	)
+
+	return f
+}
+
+// mailedToken returns the token from the link in the only mail sent.
+func (f emailVerificationFixture) mailedToken(t *testing.T) string {
+	require.Len(t, f.mailer.sent, 1)
+
+	body := f.mailer.sent[0].Body
+	link, err := url.Parse(strings.Fields(body[strings.Index(body, "https://"):])[0])
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestAuthServiceRegisterMailsVerification(t *testing.T) {
+	t.Parallel()
+
+	f := newEmailVerificationFixture(t)
+	authService := NewAuthService(NewUserService(f.users), f.tokens, nil, 0, nil, f.svc, nil, nil)
+	ctx := context.Background()
+	created := domain.User{ID: uuid.New(), Email: "user@example.com", PasswordHash: "hash"}
+
+	f.users.
+		On("Create", ctx, mock.MatchedBy(func(user domain.User) bool {
+			return user.Email == created.Email && !user.EmailVerified()
+		})).
+		Return(created, nil).
+		Once()
+
+	_, err := authService.Register(ctx, created.Email, "secret")
+
+	require.NoError(t, err)
+	require.Equal(t, created.Email, f.mailer.sent[0].To)
+	userID, err := f.tokens.ParseFor(emailVerificationTokenPurpose, f.mailedToken(t))
+	require.NoError(t, err)
+	require.Equal(t, created.ID, userID)
+}
+
+func TestEmailVerificationServiceVerifyMarksEmailVerified(t *testing.T) {
+	t.Parallel()
+
+	f := newEmailVerificationFixture(t)
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com"}
+	require.NoError(t, f.svc.Send(ctx, user))
+
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Once()
+	f.users.On("VerifyEmail", ctx, user.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	require.NoError(t, f.svc.Verify(ctx, f.mailedToken(t)))
+}
+
+func TestEmailVerificationServiceVerifyRejectsUnusableTokens(t *testing.T) {
+	t.Parallel()
+
+	f := newEmailVerificationFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	expired, err := f.tokens.IssueFor(emailVerificationTokenPurpose, userID, -time.Second)
+	require.NoError(t, err)
+	accessToken, err := f.tokens.Issue(userID)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, f.svc.Verify(ctx, expired), ErrVerificationTokenExpired)
+	require.ErrorIs(t, f.svc.Verify(ctx, accessToken), ErrInvalidVerificationToken)
+	require.ErrorIs(t, f.svc.Verify(ctx, "broken"), ErrInvalidVerificationToken)
+	f.users.AssertNotCalled(t, "VerifyEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEmailVerificationServiceResendSkipsVerifiedUsers(t *testing.T) {
+	t.Parallel()
+
+	f := newEmailVerificationFixture(t)
+	ctx := context.Background()
+	verifiedAt := time.Now()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com", EmailVerifiedAt: &verifiedAt}
+
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Once()
+
+	require.ErrorIs(t, f.svc.Resend(ctx, user.ID), ErrEmailAlreadyVerified)
+	require.Empty(t, f.mailer.sent)
+}
+
+func TestEmailVerificationServiceResendThrottlesPerUser(t *testing.T) {
+	t.Parallel()
+
+	f := newEmailVerificationFixture(t)
+	svc := NewEmailVerificationService(NewUserService(f.users), f.tokens, f.mailer, "https://taskflow.test/verify-email", time.Hour,
+		NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3))
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com", PasswordHash: "hash"}
+	other := domain.User{ID: uuid.New(), Email: "other@example.com", PasswordHash: "hash"}
+
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Times(4)
+	f.users.On("Get", ctx, other.ID).Return(other, nil).Once()
+
+	for range 3 {
+		require.NoError(t, svc.Resend(ctx, user.ID))
+	}
+
+	err := svc.Resend(ctx, user.ID)
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	require.Positive(t, throttled.RetryAfter)
+	require.Len(t, f.mailer.sent, 3)
+
+	require.NoError(t, svc.Resend(ctx, other.ID))
+	require.Len(t, f.mailer.sent, 4)
+}
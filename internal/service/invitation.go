@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// invitationTokenPurpose keeps invitation tokens apart from access tokens
+// signed with the same secret.
+const invitationTokenPurpose = "invitation"
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation domain.Invitation) (domain.Invitation, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Invitation, error)
+	// Accept stores the answer together with the new membership. It fails
+	// with domain.ErrInvitationAnswered when the invitation has been answered
+	// in the meantime.
+	Accept(ctx context.Context, invitation domain.Invitation, member domain.ProjectMember) error
+	// AcceptAsNewUser stores the invitee's new account together with the
+	// answer and the membership, so that a failed answer leaves no account
+	// behind. It fails like Accept.
+	AcceptAsNewUser(ctx context.Context, invitation domain.Invitation, user domain.User, member domain.ProjectMember) error
+	// Decline stores the answer. It fails with domain.ErrInvitationAnswered
+	// when the invitation has been answered in the meantime.
+	Decline(ctx context.Context, invitation domain.Invitation) error
+}
+
+type InvitationService struct {
+	InvitationRepository InvitationRepository
+	projectService       *ProjectService
+	authService          *AuthService
+	tokenService         *TokenService
+	mailer               Mailer
+	// acceptURL is the page the invitation mail links to; the token is
+	// appended as a query parameter.
+	acceptURL string
+	ttl       time.Duration
+}
+
+// AcceptedInvitation is the membership created by accepting an invitation.
+// Tokens are set when the invitee had no account and one was registered.
+type AcceptedInvitation struct {
+	Member domain.ProjectMember
+	Tokens *TokenPair
+}
+
+func NewInvitationService(
+	repository InvitationRepository,
+	projectService *ProjectService,
+	authService *AuthService,
+	tokenService *TokenService,
+	mailer Mailer,
+	acceptURL string,
+	ttl time.Duration,
+) *InvitationService {
+	return &InvitationService{
+		InvitationRepository: repository,
+		projectService:       projectService,
+		authService:          authService,
+		tokenService:         tokenService,
+		mailer:               mailer,
+		acceptURL:            acceptURL,
+		ttl:                  ttl,
+	}
+}
+
+// Invite mails an invitation to join the project. As with adding members
+// directly, owners and admins may invite only with roles below their own, and
+// when email verification is required unverified users can neither invite nor
+// be invited.
+func (s *InvitationService) Invite(
+	ctx context.Context,
+	userID, projectID uuid.UUID,
+	email string,
+	role domain.ProjectRole,
+) (domain.Invitation, error) {
+	project, actor, err := s.projectService.authorizeProject(ctx, userID, projectID, domain.PermissionManageMembers)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	invitation, err := project.Invite(userID, email, role, s.ttl)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+	if !actor.Role.CanAssign("", role) {
+		return domain.Invitation{}, ErrForbidden
+	}
+
+	if s.projectService.requireVerifiedEmail {
+		inviter, err := s.authService.userService.GetUser(ctx, userID)
+		if err != nil {
+			return domain.Invitation{}, err
+		}
+		if err := s.projectService.checkVerified(inviter); err != nil {
+			return domain.Invitation{}, err
+		}
+	}
+
+	if user, err := s.authService.userService.GetUserByEmail(ctx, invitation.Email); err == nil {
+		if _, err := s.projectService.ProjectRepository.Member(ctx, projectID, user.ID); err == nil {
+			return domain.Invitation{}, domain.ErrAlreadyProjectMember
+		}
+		if err := s.projectService.checkVerified(user); err != nil {
+			return domain.Invitation{}, err
+		}
+	}
+
+	invitation, err = s.InvitationRepository.Create(ctx, invitation)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	token, err := s.tokenService.IssueFor(invitationTokenPurpose, invitation.ID, time.Until(invitation.ExpiresAt))
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	if err := s.mailer.Send(ctx, s.invitationMail(project, invitation, token)); err != nil {
+		return domain.Invitation{}, fmt.Errorf("send invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// Accept answers the invitation the token was issued for and adds the invitee
+// to the project. Invitees without an account are registered with the
+// password, their email verified by the invitation mail itself; the others
+// are expected to log in as usual.
+func (s *InvitationService) Accept(ctx context.Context, token, password string) (AcceptedInvitation, error) {
+	invitation, err := s.invitation(ctx, token)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	if err := invitation.Accept(time.Now()); err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	project, err := s.projectService.ProjectRepository.Get(ctx, invitation.ProjectID)
+	if err != nil {
+		return AcceptedInvitation{}, ErrInvitationNotFound
+	}
+
+	user, err := s.authService.userService.GetUserByEmail(ctx, invitation.Email)
+	if err != nil {
+		return s.acceptAsNewUser(ctx, project, invitation, password)
+	}
+	if _, err := s.projectService.ProjectRepository.Member(ctx, project.ID, user.ID); err == nil {
+		return AcceptedInvitation{}, domain.ErrAlreadyProjectMember
+	}
+
+	member, err := project.AddMember(user.ID, invitation.Role)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	if err := s.InvitationRepository.Accept(ctx, invitation, member); err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	return AcceptedInvitation{Member: member}, nil
+}
+
+// acceptAsNewUser registers the invitee, whose email the invitation mail has
+// proven, in the same transaction that accepts the invitation. The tokens are
+// issued only afterwards; should that fail, the new member can still log in
+// with the password.
+func (s *InvitationService) acceptAsNewUser(
+	ctx context.Context,
+	project domain.Project,
+	invitation domain.Invitation,
+	password string,
+) (AcceptedInvitation, error) {
+	now := time.Now()
+	user, err := newUser(invitation.Email, password, &now)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	member, err := project.AddMember(user.ID, invitation.Role)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	if err := s.InvitationRepository.AcceptAsNewUser(ctx, invitation, user, member); err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	tokens, err := s.authService.issue(ctx, user.ID)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+
+	return AcceptedInvitation{Member: member, Tokens: &tokens}, nil
+}
+
+// Decline answers the invitation the token was issued for; it cannot be
+// accepted afterwards.
+func (s *InvitationService) Decline(ctx context.Context, token string) error {
+	invitation, err := s.invitation(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if err := invitation.Decline(time.Now()); err != nil {
+		return err
+	}
+
+	return s.InvitationRepository.Decline(ctx, invitation)
+}
+
+// invitation loads the invitation an invitation token was issued for.
+func (s *InvitationService) invitation(ctx context.Context, token string) (domain.Invitation, error) {
+	invitationID, err := s.tokenService.ParseFor(invitationTokenPurpose, token)
+	if errors.Is(err, ErrTokenExpired) {
+		return domain.Invitation{}, domain.ErrInvitationExpired
+	}
+	if err != nil {
+		return domain.Invitation{}, ErrInvitationNotFound
+	}
+
+	invitation, err := s.InvitationRepository.Get(ctx, invitationID)
+	if err != nil {
+		return domain.Invitation{}, ErrInvitationNotFound
+	}
+
+	return invitation, nil
+}
+
+func (s *InvitationService) invitationMail(project domain.Project, invitation domain.Invitation, token string) Mail {
+	return Mail{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You are invited to %s on Taskflow", project.Name),
+		Body: fmt.Sprintf(
+			"You have been invited to join the project %q as %s.\n\n"+
+				"Accept the invitation: %s?token=%s\n\n"+
+				"The invitation expires on %s.",
+			project.Name,
+			invitation.Role,
+			s.acceptURL,
+			token,
+			invitation.ExpiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []Mail
+}
+
+func (m *recordingMailer) Send(_ context.Context, mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+type invitationFixture struct {
+	invitations *mocks.InvitationRepository
+	projects    *mocks.ProjectRepository
+	users       *mocks.UserRepository
+	tokens      *TokenService
+	mailer      *recordingMailer
+	svc         *InvitationService
+}
+
+func newInvitationFixture(t *testing.T) invitationFixture {
+	f := invitationFixture{
+		invitations: mocks.NewInvitationRepository(t),
+		projects:    mocks.NewProjectRepository(t),
+		users:       mocks.NewUserRepository(t),
+		tokens:      NewTokenService("test-secret", mockTTL()),
+		mailer:      &recordingMailer{},
+	}
+	auth := NewAuthService(NewUserService(f.users), f.tokens, nil, 0, nil, nil, nil, nil)
+	f.svc = NewInvitationService(
+		f.invitations,
+		NewProjectService(f.projects, f.users, nil, false),
+		auth,
+		f.tokens,
+		f.mailer,
+		"https://taskflow.test/invitations/accept",
+		time.Hour,
+	)
+
+	return f
+}
+
+// pendingInvitation returns a stored invitation and a token issued for it.
+func (f invitationFixture) pendingInvitation(t *testing.T, role domain.ProjectRole) (domain.Invitation, string) {
+	invitation := domain.Invitation{
+		ID:        uuid.New(),
+		ProjectID: uuid.New(),
+		InviterID: uuid.New(),
+		Email:     "new@example.com",
+		Role:      role,
+		Status:    domain.InvitationPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	token, err := f.tokens.IssueFor(invitationTokenPurpose, invitation.ID, time.Hour)
+	require.NoError(t, err)
+
+	return invitation, token
+}
+
+func TestInvitationServiceInviteMailsToken(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	project := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Platform"}
+
+	f.projects.
+		On("Member", ctx, project.ID, userID).
+		Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: domain.RoleOwner}, nil).
+		Once()
+	f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+	f.users.
+		On("GetByEmail", ctx, "teammate@example.com").
+		Return(domain.User{}, errors.New("user not found")).
+		Once()
+	f.invitations.
+		On("Create", ctx, mock.MatchedBy(func(invitation domain.Invitation) bool {
+			return invitation.ProjectID == project.ID &&
+				invitation.InviterID == userID &&
+				invitation.Email == "teammate@example.com" &&
+				invitation.Role == domain.RoleEditor &&
+				invitation.Status == domain.InvitationPending
+		})).
+		Return(func(_ context.Context, invitation domain.Invitation) (domain.Invitation, error) {
+			return invitation, nil
+		}).
+		Once()
+
+	invitation, err := f.svc.Invite(ctx, userID, project.ID, " Teammate@Example.com ", domain.RoleEditor)
+
+	require.NoError(t, err)
+	require.Len(t, f.mailer.sent, 1)
+	mail := f.mailer.sent[0]
+	require.Equal(t, "teammate@example.com", mail.To)
+	require.Contains(t, mail.Body, "Platform")
+
+	link := mail.Body[strings.Index(mail.Body, "https://"):]
+	link = link[:strings.IndexAny(link, "\n")]
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+
+	invitationID, err := f.tokens.ParseFor(invitationTokenPurpose, parsed.Query().Get("token"))
+	require.NoError(t, err)
+	require.Equal(t, invitation.ID, invitationID)
+}
+
+func TestInvitationServiceInviteChecksRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		actorRole domain.ProjectRole
+		role      domain.ProjectRole
+		wantErr   error
+	}{
+		{name: "admin cannot invite an admin", actorRole: domain.RoleAdmin, role: domain.RoleAdmin, wantErr: ErrForbidden},
+		{name: "editor cannot invite", actorRole: domain.RoleEditor, role: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "owner role cannot be granted", actorRole: domain.RoleOwner, role: domain.RoleOwner, wantErr: domain.ErrInvalidProjectRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newInvitationFixture(t)
+			ctx := context.Background()
+			userID := uuid.New()
+			project := domain.Project{ID: uuid.New(), OwnerID: uuid.New(), Name: "Platform"}
+
+			f.projects.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: tt.actorRole}, nil).
+				Once()
+			f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+
+			_, err := f.svc.Invite(ctx, userID, project.ID, "teammate@example.com", tt.role)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Empty(t, f.mailer.sent)
+			f.invitations.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestInvitationServiceInviteRejectsMembers(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	member := domain.User{ID: uuid.New(), Email: "teammate@example.com"}
+	project := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Platform"}
+
+	f.projects.
+		On("Member", ctx, project.ID, userID).
+		Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: domain.RoleOwner}, nil).
+		Once()
+	f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+	f.users.On("GetByEmail", ctx, member.Email).Return(member, nil).Once()
+	f.projects.
+		On("Member", ctx, project.ID, member.ID).
+		Return(domain.ProjectMember{ProjectID: project.ID, UserID: member.ID, Role: domain.RoleViewer}, nil).
+		Once()
+
+	_, err := f.svc.Invite(ctx, userID, project.ID, member.Email, domain.RoleEditor)
+
+	require.ErrorIs(t, err, domain.ErrAlreadyProjectMember)
+	require.Empty(t, f.mailer.sent)
+}
+
+func TestInvitationServiceInviteRequiresVerifiedEmail(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Now()
+	tests := []struct {
+		name    string
+		inviter domain.User
+		invitee domain.User
+	}{
+		{
+			name:    "unverified inviter",
+			inviter: domain.User{Email: "owner@example.com"},
+		},
+		{
+			name:    "unverified invitee",
+			inviter: domain.User{Email: "owner@example.com", EmailVerifiedAt: &verifiedAt},
+			invitee: domain.User{ID: uuid.New(), Email: "teammate@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newInvitationFixture(t)
+			f.svc.projectService.requireVerifiedEmail = true
+			ctx := context.Background()
+			userID := uuid.New()
+			tt.inviter.ID = userID
+			project := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Platform"}
+
+			f.projects.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: domain.RoleOwner}, nil).
+				Once()
+			f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+			f.users.On("Get", ctx, userID).Return(tt.inviter, nil).Once()
+			if tt.invitee.ID != uuid.Nil {
+				f.users.On("GetByEmail", ctx, tt.invitee.Email).Return(tt.invitee, nil).Once()
+				f.projects.On("Member", ctx, project.ID, tt.invitee.ID).Return(domain.ProjectMember{}, errors.New("member not found")).Once()
+			}
+
+			_, err := f.svc.Invite(ctx, userID, project.ID, "teammate@example.com", domain.RoleEditor)
+
+			require.ErrorIs(t, err, domain.ErrEmailNotVerified)
+			require.Empty(t, f.mailer.sent)
+			f.invitations.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestInvitationServiceAcceptRegistersUnknownEmail(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	invitation, token := f.pendingInvitation(t, domain.RoleViewer)
+	project := domain.Project{ID: invitation.ProjectID, OwnerID: invitation.InviterID, Name: "Platform"}
+
+	var created domain.User
+	f.invitations.On("Get", ctx, invitation.ID).Return(invitation, nil).Once()
+	f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+	f.users.On("GetByEmail", ctx, invitation.Email).Return(domain.User{}, errors.New("user not found")).Once()
+	f.invitations.
+		On("AcceptAsNewUser", ctx,
+			mock.MatchedBy(func(answered domain.Invitation) bool {
+				return answered.ID == invitation.ID &&
+					answered.Status == domain.InvitationAccepted &&
+					answered.AnsweredAt != nil
+			}),
+			mock.MatchedBy(func(user domain.User) bool {
+				// The invitation mail already proved the invitee owns the email.
+				return user.Email == invitation.Email && user.EmailVerified()
+			}),
+			mock.MatchedBy(func(member domain.ProjectMember) bool {
+				return member.ProjectID == project.ID && member.Role == domain.RoleViewer
+			}),
+		).
+		Run(func(args mock.Arguments) { created = args.Get(2).(domain.User) }).
+		Return(nil).
+		Once()
+
+	accepted, err := f.svc.Accept(ctx, token, "secret")
+
+	require.NoError(t, err)
+	require.Equal(t, created.ID, accepted.Member.UserID)
+	f.users.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	require.NotNil(t, accepted.Tokens)
+	userID, err := f.tokens.Parse(accepted.Tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, userID)
+}
+
+func TestInvitationServiceAcceptFailsWithoutTokensWhenNewUserCannotBeStored(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	invitation, token := f.pendingInvitation(t, domain.RoleViewer)
+	project := domain.Project{ID: invitation.ProjectID, OwnerID: invitation.InviterID, Name: "Platform"}
+
+	f.invitations.On("Get", ctx, invitation.ID).Return(invitation, nil).Once()
+	f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+	f.users.On("GetByEmail", ctx, invitation.Email).Return(domain.User{}, errors.New("user not found")).Once()
+	f.invitations.
+		On("AcceptAsNewUser", ctx, mock.Anything, mock.Anything, mock.Anything).
+		Return(domain.ErrInvitationAnswered).
+		Once()
+
+	accepted, err := f.svc.Accept(ctx, token, "secret")
+
+	require.ErrorIs(t, err, domain.ErrInvitationAnswered)
+	require.Nil(t, accepted.Tokens)
+	// The account is stored only in the transaction that accepts the
+	// invitation, never on its own.
+	f.users.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	f.invitations.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvitationServiceAcceptAddsExistingUser(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	invitation, token := f.pendingInvitation(t, domain.RoleEditor)
+	project := domain.Project{ID: invitation.ProjectID, OwnerID: invitation.InviterID, Name: "Platform"}
+	user := domain.User{ID: uuid.New(), Email: invitation.Email}
+
+	f.invitations.On("Get", ctx, invitation.ID).Return(invitation, nil).Once()
+	f.projects.On("Get", ctx, project.ID).Return(project, nil).Once()
+	f.users.On("GetByEmail", ctx, invitation.Email).Return(user, nil).Once()
+	f.projects.
+		On("Member", ctx, project.ID, user.ID).
+		Return(domain.ProjectMember{}, errors.New("member not found")).
+		Once()
+	f.invitations.
+		On("Accept", ctx, mock.Anything, mock.MatchedBy(func(member domain.ProjectMember) bool {
+			return member.UserID == user.ID && member.Role == domain.RoleEditor
+		})).
+		Return(nil).
+		Once()
+
+	accepted, err := f.svc.Accept(ctx, token, "")
+
+	require.NoError(t, err)
+	require.Nil(t, accepted.Tokens)
+	f.users.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestInvitationServiceAcceptRejectsUsedAndInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	t.Run("answered", func(t *testing.T) {
+		t.Parallel()
+
+		f := newInvitationFixture(t)
+		ctx := context.Background()
+		invitation, token := f.pendingInvitation(t, domain.RoleEditor)
+		invitation.Status = domain.InvitationAccepted
+
+		f.invitations.On("Get", ctx, invitation.ID).Return(invitation, nil).Once()
+
+		_, err := f.svc.Accept(ctx, token, "secret")
+
+		require.ErrorIs(t, err, domain.ErrInvitationAnswered)
+		f.invitations.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		f := newInvitationFixture(t)
+		token, err := f.tokens.IssueFor(invitationTokenPurpose, uuid.New(), -time.Second)
+		require.NoError(t, err)
+
+		_, err = f.svc.Accept(context.Background(), token, "secret")
+
+		require.ErrorIs(t, err, domain.ErrInvitationExpired)
+	})
+
+	t.Run("access token", func(t *testing.T) {
+		t.Parallel()
+
+		f := newInvitationFixture(t)
+		token, err := f.tokens.Issue(uuid.New())
+		require.NoError(t, err)
+
+		_, err = f.svc.Accept(context.Background(), token, "secret")
+
+		require.ErrorIs(t, err, ErrInvitationNotFound)
+	})
+}
+
+func TestInvitationServiceDecline(t *testing.T) {
+	t.Parallel()
+
+	f := newInvitationFixture(t)
+	ctx := context.Background()
+	invitation, token := f.pendingInvitation(t, domain.RoleEditor)
+
+	f.invitations.On("Get", ctx, invitation.ID).Return(invitation, nil).Once()
+	f.invitations.
+		On("Decline", ctx, mock.MatchedBy(func(answered domain.Invitation) bool {
+			return answered.ID == invitation.ID && answered.Status == domain.InvitationDeclined
+		})).
+		Return(nil).
+		Once()
+
+	require.NoError(t, f.svc.Decline(ctx, token))
+}
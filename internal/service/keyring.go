@@ -0,0 +1,276 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// DefaultKeyID names the key built from JWT_SECRET.
+	DefaultKeyID = "default"
+)
+
+var ErrInvalidKeyring = errors.New("invalid keyring")
+
+// SigningKey signs and verifies tokens with one algorithm. Keys loaded from a
+// public key only verify.
+type SigningKey struct {
+	id        string
+	algorithm string
+	secret    []byte
+	private   crypto.Signer
+	public    crypto.PublicKey
+}
+
+// VerificationKey is a public key published for other services to verify
+// tokens with.
+type VerificationKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+// Keyring holds every key tokens may be verified with and the one new tokens
+// are signed with. Rotating keys means adding the new key, making it active
+// once every instance knows it, and removing the old key once the tokens it
+// signed have expired.
+type Keyring struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewHMACKey(id, secret string) (SigningKey, error) {
+	if id == "" || secret == "" {
+		return SigningKey{}, ErrInvalidKeyring
+	}
+
+	return SigningKey{id: id, algorithm: AlgorithmHS256, secret: []byte(secret)}, nil
+}
+
+func NewRSAKey(id string, key *rsa.PrivateKey) SigningKey {
+	return SigningKey{id: id, algorithm: AlgorithmRS256, private: key, public: &key.PublicKey}
+}
+
+func NewEd25519Key(id string, key ed25519.PrivateKey) SigningKey {
+	return SigningKey{id: id, algorithm: AlgorithmEdDSA, private: key, public: key.Public()}
+}
+
+// ParsePEMKey reads an RS256 or EdDSA key. A private key (PKCS #8, or PKCS #1
+// for RSA) signs and verifies; a public key (PKIX) only verifies.
+func ParsePEMKey(id, algorithm string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || id == "" {
+		return SigningKey{}, fmt.Errorf("%w: key %q is not PEM encoded", ErrInvalidKeyring, id)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("%w: key %q: %v", ErrInvalidKeyring, id, err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgorithmRS256 {
+			return NewRSAKey(id, k), nil
+		}
+	case *rsa.PublicKey:
+		if algorithm == AlgorithmRS256 {
+			return SigningKey{id: id, algorithm: algorithm, public: k}, nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgorithmEdDSA {
+			return NewEd25519Key(id, k), nil
+		}
+	case ed25519.PublicKey:
+		if algorithm == AlgorithmEdDSA {
+			return SigningKey{id: id, algorithm: algorithm, public: k}, nil
+		}
+	}
+
+	return SigningKey{}, fmt.Errorf("%w: key %q does not match algorithm %q", ErrInvalidKeyring, id, algorithm)
+}
+
+func (k SigningKey) ID() string {
+	return k.id
+}
+
+func (k SigningKey) Algorithm() string {
+	return k.algorithm
+}
+
+func (k SigningKey) canSign() bool {
+	return k.secret != nil || k.private != nil
+}
+
+func (k SigningKey) sign(input string) (string, error) {
+	var (
+		signature []byte
+		err       error
+	)
+
+	switch k.algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case AlgorithmRS256:
+		digest := sha256.Sum256([]byte(input))
+		signature, err = k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgorithmEdDSA:
+		signature, err = k.private.Sign(rand.Reader, []byte(input), crypto.Hash(0))
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", k.algorithm)
+	}
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (k SigningKey) verify(input, signature string) bool {
+	switch k.algorithm {
+	case AlgorithmHS256:
+		expected, err := k.sign(input)
+		return err == nil && hmac.Equal([]byte(signature), []byte(expected))
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	switch k.algorithm {
+	case AlgorithmRS256:
+		public, ok := k.public.(*rsa.PublicKey)
+		digest := sha256.Sum256([]byte(input))
+		return ok && rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], raw) == nil
+	case AlgorithmEdDSA:
+		public, ok := k.public.(ed25519.PublicKey)
+		return ok && ed25519.Verify(public, []byte(input), raw)
+	default:
+		return false
+	}
+}
+
+// NewKeyring signs with the key named active and verifies with all of them.
+func NewKeyring(active string, keys ...SigningKey) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]*SigningKey, len(keys))}
+	for i := range keys {
+		if _, ok := keyring.keys[keys[i].id]; ok {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidKeyring, keys[i].id)
+		}
+		keyring.keys[keys[i].id] = &keys[i]
+	}
+
+	keyring.active = keyring.keys[active]
+	if keyring.active == nil || !keyring.active.canSign() {
+		return nil, fmt.Errorf("%w: active key %q cannot sign", ErrInvalidKeyring, active)
+	}
+
+	return keyring, nil
+}
+
+// keyringFile is the JSON layout of JWT_KEYRING_FILE. HS256 keys carry their
+// secret; RS256 and EdDSA keys point to a PEM file, relative to the keyring
+// file unless absolute.
+type keyringFile struct {
+	Active string `json:"active"`
+	Keys   []struct {
+		ID        string `json:"kid"`
+		Algorithm string `json:"alg"`
+		Secret    string `json:"secret"`
+		PEMFile   string `json:"pem_file"`
+	} `json:"keys"`
+}
+
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyring: %w", err)
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyring, err)
+	}
+
+	keys := make([]SigningKey, 0, len(file.Keys))
+	for _, k := range file.Keys {
+		var key SigningKey
+		if k.Algorithm == AlgorithmHS256 {
+			key, err = NewHMACKey(k.ID, k.Secret)
+		} else {
+			pemFile := k.PEMFile
+			if !filepath.IsAbs(pemFile) {
+				pemFile = filepath.Join(filepath.Dir(path), pemFile)
+			}
+
+			var pemData []byte
+			pemData, err = os.ReadFile(pemFile)
+			if err != nil {
+				return nil, fmt.Errorf("read key %q: %w", k.ID, err)
+			}
+			key, err = ParsePEMKey(k.ID, k.Algorithm, pemData)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeyring(file.Active, keys...)
+}
+
+// VerificationKeys returns the public keys of the keyring. Shared HS256
+// secrets are never published.
+func (k *Keyring) VerificationKeys() []VerificationKey {
+	result := make([]VerificationKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		if key.public == nil {
+			continue
+		}
+		result = append(result, VerificationKey{ID: key.id, Algorithm: key.algorithm, Key: key.public})
+	}
+	slices.SortFunc(result, func(a, b VerificationKey) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+// key finds the key a token names.
+func (k *Keyring) key(id string) (*SigningKey, bool) {
+	key, ok := k.keys[id]
+	return key, ok
+}
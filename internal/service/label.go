@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"taskflow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type LabelRepository interface {
+	Create(ctx context.Context, label domain.Label) (domain.Label, error)
+	Get(ctx context.Context, id, userID uuid.UUID) (domain.Label, error)
+	List(ctx context.Context, userID uuid.UUID) ([]domain.Label, error)
+	// Update and Delete return the new versions of the tasks carrying the label.
+	Update(ctx context.Context, label domain.Label) (domain.Label, map[uuid.UUID]int64, error)
+	Delete(ctx context.Context, id, userID uuid.UUID) (map[uuid.UUID]int64, error)
+}
+
+type LabelService struct {
+	LabelRepository LabelRepository
+	taskService     *TaskService
+}
+
+func NewLabelService(repository LabelRepository, taskService *TaskService) *LabelService {
+	return &LabelService{
+		LabelRepository: repository,
+		taskService:     taskService,
+	}
+}
+
+func (s *LabelService) CreateLabel(ctx context.Context, userID uuid.UUID, name, color string) (domain.Label, error) {
+	label, err := domain.NewLabel(userID, name, color)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	return s.LabelRepository.Create(ctx, label)
+}
+
+func (s *LabelService) ListLabels(ctx context.Context, userID uuid.UUID) ([]domain.Label, error) {
+	return s.LabelRepository.List(ctx, userID)
+}
+
+func (s *LabelService) UpdateLabel(
+	ctx context.Context,
+	userID, labelID uuid.UUID,
+	name, color *string,
+) (domain.Label, error) {
+	label, err := s.LabelRepository.Get(ctx, labelID, userID)
+	if err != nil {
+		return domain.Label{}, ErrLabelNotFound
+	}
+
+	if name != nil {
+		if err := label.Rename(*name); err != nil {
+			return domain.Label{}, err
+		}
+	}
+
+	if color != nil {
+		if err := label.ChangeColor(*color); err != nil {
+			return domain.Label{}, err
+		}
+	}
+
+	updated, touched, err := s.LabelRepository.Update(ctx, label)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	s.invalidateTasks(ctx, touched)
+
+	return updated, nil
+}
+
+func (s *LabelService) DeleteLabel(ctx context.Context, userID, labelID uuid.UUID) error {
+	if _, err := s.LabelRepository.Get(ctx, labelID, userID); err != nil {
+		return ErrLabelNotFound
+	}
+
+	touched, err := s.LabelRepository.Delete(ctx, labelID, userID)
+	if err != nil {
+		return err
+	}
+
+	s.invalidateTasks(ctx, touched)
+
+	return nil
+}
+
+// invalidateTasks keeps cached tasks from showing a label as it was before.
+func (s *LabelService) invalidateTasks(ctx context.Context, versions map[uuid.UUID]int64) {
+	if s.taskService == nil {
+		return
+	}
+
+	for taskID, version := range versions {
+		s.taskService.invalidateCachedTask(ctx, taskID, version)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelServiceCreateLabelNormalizesColor(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewLabelRepository(t)
+	svc := NewLabelService(repo, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(label domain.Label) bool {
+			return label.UserID == userID &&
+				label.Name == "backend" &&
+				label.Color == "#1e88e5"
+		})).
+		Return(domain.Label{ID: uuid.New(), UserID: userID, Name: "backend", Color: "#1e88e5"}, nil).
+		Once()
+
+	label, err := svc.CreateLabel(ctx, userID, "  backend ", "#1E88E5")
+
+	require.NoError(t, err)
+	require.Equal(t, "backend", label.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestLabelServiceCreateLabelRejectsInvalidColor(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewLabelRepository(t)
+	svc := NewLabelService(repo, nil)
+
+	_, err := svc.CreateLabel(context.Background(), uuid.New(), "backend", "blue")
+
+	require.ErrorIs(t, err, domain.ErrInvalidLabelColor)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLabelServiceUpdateLabelInvalidatesTouchedTasks(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewLabelRepository(t)
+	cache := mocks.NewTaskCache(t)
+	taskService := NewTaskService(mocks.NewTaskRepository(t), cache, repo, nil, nil, nil, nil, nil)
+	svc := NewLabelService(repo, taskService)
+	ctx := context.Background()
+	userID := uuid.New()
+	labelID := uuid.New()
+	taskID := uuid.New()
+	name := "release-1.4"
+
+	repo.
+		On("Get", ctx, labelID, userID).
+		Return(domain.Label{ID: labelID, UserID: userID, Name: "release", Color: domain.DefaultLabelColor}, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(label domain.Label) bool {
+			return label.ID == labelID && label.Name == "release-1.4"
+		})).
+		Return(
+			domain.Label{ID: labelID, UserID: userID, Name: "release-1.4", Color: domain.DefaultLabelColor},
+			map[uuid.UUID]int64{taskID: 7},
+			nil,
+		).
+		Once()
+	cache.EXPECT().
+		Delete(ctx, taskService.taskCacheKey(taskID)).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, taskService.taskVersionCacheKey(taskID), "7", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+
+	label, err := svc.UpdateLabel(ctx, userID, labelID, &name, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, "release-1.4", label.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestLabelServiceDeleteLabelReturnsNotFound(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewLabelRepository(t)
+	svc := NewLabelService(repo, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	labelID := uuid.New()
+
+	repo.
+		On("Get", ctx, labelID, userID).
+		Return(domain.Label{}, errors.New("db error")).
+		Once()
+
+	err := svc.DeleteLabel(ctx, userID, labelID)
+
+	require.ErrorIs(t, err, ErrLabelNotFound)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
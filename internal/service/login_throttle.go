@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const loginFailuresKeyPrefix = "auth:login:failures:"
+
+var ErrTooManyLoginAttempts = errors.New("too many login attempts")
+
+// LoginThrottledError rejects a login attempt made before the backoff of
+// earlier failures has passed.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// LoginAttempts counts login attempts per key, such as an email or a client
+// IP. An attempt is counted before the password is checked and taken back if
+// it turns out right, so concurrent guesses cannot all pass at the same count.
+type LoginAttempts interface {
+	// Reserve counts an attempt for the key and returns zero, or, while the
+	// backoff of the attempts counted before it runs, counts nothing and
+	// returns the wait left. A key's attempts are forgotten once none has been
+	// counted for the backoff's Lockout.
+	Reserve(ctx context.Context, key string, backoff LoginBackoff, now time.Time) (time.Duration, error)
+	// Release takes back an attempt counted by Reserve.
+	Release(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginBackoff turns a number of failures into a wait: none for the free
+// attempts, then doubling from BaseDelay, and Lockout from LockoutAttempts on.
+type LoginBackoff struct {
+	FreeAttempts    int
+	LockoutAttempts int
+	BaseDelay       time.Duration
+	Lockout         time.Duration
+}
+
+func (b LoginBackoff) delay(failures int) time.Duration {
+	switch {
+	case failures <= b.FreeAttempts:
+		return 0
+	case failures >= b.LockoutAttempts:
+		return b.Lockout
+	}
+
+	delay := b.BaseDelay
+	for range failures - b.FreeAttempts - 1 {
+		delay *= 2
+		if delay >= b.Lockout {
+			return b.Lockout
+		}
+	}
+
+	return delay
+}
+
+// wait returns how long the next attempt has to wait after the failures, the
+// last of which was made at last.
+func (b LoginBackoff) wait(failures int, last, now time.Time) time.Duration {
+	return max(0, last.Add(b.delay(failures)).Sub(now))
+}
+
+// LoginThrottle slows down password guessing. Failures are counted both per
+// email, against guessing one account's password, and per client IP, against
+// trying one password on many accounts; the IP backoff should allow more
+// attempts, since many users may share an address. Codes for the second
+// factor are counted per user with the email backoff.
+type LoginThrottle struct {
+	attempts LoginAttempts
+	email    LoginBackoff
+	ip       LoginBackoff
+	// mfaTokenAttempts is how many codes one MFA token may be tried with.
+	mfaTokenAttempts int
+}
+
+func NewLoginThrottle(attempts LoginAttempts, email, ip LoginBackoff, mfaTokenAttempts int) *LoginThrottle {
+	return &LoginThrottle{attempts: attempts, email: email, ip: ip, mfaTokenAttempts: mfaTokenAttempts}
+}
+
+// Reserve counts an attempt for the email and the client IP before the
+// password is checked. It returns a *LoginThrottledError, counting nothing,
+// when either has to wait. The attempt stays counted as a failure unless
+// Release and Reset take it back.
+func (t *LoginThrottle) Reserve(ctx context.Context, email, clientIP string) error {
+	return t.reserve(ctx, "", email, clientIP)
+}
+
+// ReserveForgot counts a request for a password reset mail to the email from
+// the client IP, with the login backoffs but apart from login attempts, so
+// that nobody can flood an inbox with reset mails. Requests for unknown
+// emails count the same, and the requests are never taken back.
+func (t *LoginThrottle) ReserveForgot(ctx context.Context, email, clientIP string) error {
+	return t.reserve(ctx, "forgot:", email, clientIP)
+}
+
+// ReserveVerification counts a request to mail the user another verification
+// link, with the email backoff but apart from login attempts, so that a
+// session cannot flood an inbox with verification mails. The requests are
+// never taken back.
+func (t *LoginThrottle) ReserveVerification(ctx context.Context, userID uuid.UUID) error {
+	wait, err := t.attempts.Reserve(ctx, "verify:user:"+userID.String(), t.email, time.Now())
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (t *LoginThrottle) reserve(ctx context.Context, prefix, email, clientIP string) error {
+	now := time.Now()
+	emailKey := prefix + emailThrottleKey(email)
+
+	wait, err := t.attempts.Reserve(ctx, emailKey, t.email, now)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+
+	wait, err = t.attempts.Reserve(ctx, prefix+ipThrottleKey(clientIP), t.ip, now)
+	if err == nil && wait > 0 {
+		err = &LoginThrottledError{RetryAfter: wait}
+	}
+	if err != nil {
+		_ = t.attempts.Release(ctx, emailKey)
+		return err
+	}
+
+	return nil
+}
+
+// Release takes back the attempt reserved for the client IP once the
+// password has proven right.
+func (t *LoginThrottle) Release(ctx context.Context, clientIP string) error {
+	if clientIP == "" {
+		return nil
+	}
+
+	return t.attempts.Release(ctx, ipThrottleKey(clientIP))
+}
+
+// Reset forgets the failures of the email, after a successful login or a
+// password reset. Failures of client IPs run out on their own.
+func (t *LoginThrottle) Reset(ctx context.Context, email string) error {
+	return t.attempts.Reset(ctx, emailThrottleKey(email))
+}
+
+// ReserveMFA counts an attempt at the second factor for the user and for the
+// MFA token it is made with. It returns a *LoginThrottledError while the
+// user's backoff runs, so that logging in again for a new token buys no more
+// guesses, and ErrInvalidMFAToken once the token has been tried too often.
+// Codes checked without a token, as when a signed-in user confirms or disables
+// the second factor, count for the user only. The attempt stays counted unless
+// ResetMFA takes it back.
+func (t *LoginThrottle) ReserveMFA(ctx context.Context, userID uuid.UUID, tokenID string) error {
+	now := time.Now()
+
+	wait, err := t.attempts.Reserve(ctx, mfaUserThrottleKey(userID), t.email, now)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	if tokenID == "" {
+		return nil
+	}
+
+	token := LoginBackoff{
+		FreeAttempts:    t.mfaTokenAttempts - 1,
+		LockoutAttempts: t.mfaTokenAttempts,
+		Lockout:         t.email.Lockout,
+	}
+	wait, err = t.attempts.Reserve(ctx, "mfa:token:"+tokenID, token, now)
+	if err == nil && wait > 0 {
+		err = ErrInvalidMFAToken
+	}
+	if err != nil {
+		_ = t.attempts.Release(ctx, mfaUserThrottleKey(userID))
+		return err
+	}
+
+	return nil
+}
+
+// ResetMFA forgets the user's failed codes once the second factor is proven.
+func (t *LoginThrottle) ResetMFA(ctx context.Context, userID uuid.UUID) error {
+	return t.attempts.Reset(ctx, mfaUserThrottleKey(userID))
+}
+
+func emailThrottleKey(email string) string {
+	return "email:" + domain.NormalizeUserEmail(email)
+}
+
+func ipThrottleKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func mfaUserThrottleKey(userID uuid.UUID) string {
+	return "mfa:user:" + userID.String()
+}
+
+type memoryLoginAttempts struct {
+	mu       sync.Mutex
+	failures map[string]loginFailures
+}
+
+type loginFailures struct {
+	count     int
+	last      time.Time
+	expiresAt time.Time
+}
+
+// redisLoginAttempts counts in Redis, so that a guesser spreading attempts
+// over API instances gets no more of them.
+type redisLoginAttempts struct {
+	redisFallback[*memoryLoginAttempts]
+}
+
+func NewMemoryLoginAttempts() LoginAttempts {
+	return newMemoryLoginAttempts()
+}
+
+func newMemoryLoginAttempts() *memoryLoginAttempts {
+	return &memoryLoginAttempts{failures: make(map[string]loginFailures)}
+}
+
+func (a *memoryLoginAttempts) Reserve(_ context.Context, key string, backoff LoginBackoff, now time.Time) (time.Duration, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.prune(now)
+	f := a.failures[key]
+	if wait := backoff.wait(f.count, f.last, now); wait > 0 {
+		return wait, nil
+	}
+
+	a.failures[key] = loginFailures{count: f.count + 1, last: now, expiresAt: now.Add(backoff.Lockout)}
+	return 0, nil
+}
+
+func (a *memoryLoginAttempts) Release(_ context.Context, key string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if f, ok := a.failures[key]; ok && f.count > 0 {
+		f.count--
+		a.failures[key] = f
+	}
+	return nil
+}
+
+func (a *memoryLoginAttempts) Reset(_ context.Context, key string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.failures, key)
+	return nil
+}
+
+// prune drops the counters that have run out.
+func (a *memoryLoginAttempts) prune(now time.Time) {
+	for key, f := range a.failures {
+		if !now.Before(f.expiresAt) {
+			delete(a.failures, key)
+		}
+	}
+}
+
+// NewRedisLoginAttempts counts in memory when there is no Redis client. Every
+// instance then allows the full number of attempts on its own.
+func NewRedisLoginAttempts(client redis.Cmdable) LoginAttempts {
+	if client == nil {
+		return NewMemoryLoginAttempts()
+	}
+
+	return &redisLoginAttempts{newRedisFallback(client, newMemoryLoginAttempts())}
+}
+
+// reserveScript is LoginBackoff.wait and memoryLoginAttempts.Reserve in one
+// step on the Redis side, so that concurrent attempts on any instance are
+// judged one after another. Times are Unix milliseconds.
+//
+// KEYS[1]: the attempts hash; ARGV: now, FreeAttempts, LockoutAttempts,
+// BaseDelay and Lockout. Returns the wait left, or 0 once the attempt is
+// counted.
+var reserveScript = redis.NewScript(`
+local count = tonumber(redis.call('HGET', KEYS[1], 'count')) or 0
+local last = tonumber(redis.call('HGET', KEYS[1], 'last')) or 0
+local now = tonumber(ARGV[1])
+local free, lockoutAttempts = tonumber(ARGV[2]), tonumber(ARGV[3])
+local base, lockout = tonumber(ARGV[4]), tonumber(ARGV[5])
+
+local delay = 0
+if count > free and count >= lockoutAttempts then
+	delay = lockout
+elseif count > free then
+	delay = math.min(base * 2 ^ (count - free - 1), lockout)
+end
+
+local wait = math.floor(last + delay - now)
+if wait > 0 then
+	return wait
+end
+
+redis.call('HSET', KEYS[1], 'count', count + 1, 'last', now)
+redis.call('PEXPIRE', KEYS[1], lockout)
+return 0
+`)
+
+// releaseScript takes back one counted attempt. It never creates the hash nor
+// takes the count below zero, and the hash keeps its expiry.
+var releaseScript = redis.NewScript(`
+local count = tonumber(redis.call('HGET', KEYS[1], 'count'))
+if count and count > 0 then
+	redis.call('HINCRBY', KEYS[1], 'count', -1)
+end
+return 0
+`)
+
+func (a *redisLoginAttempts) Reserve(ctx context.Context, key string, backoff LoginBackoff, now time.Time) (time.Duration, error) {
+	return query(a.redisFallback,
+		func(local *memoryLoginAttempts) (time.Duration, error) {
+			return local.Reserve(ctx, key, backoff, now)
+		},
+		func(client redis.Cmdable) (time.Duration, error) {
+			wait, err := reserveScript.Run(ctx, client, []string{loginFailuresKeyPrefix + key},
+				now.UnixMilli(),
+				backoff.FreeAttempts,
+				backoff.LockoutAttempts,
+				backoff.BaseDelay.Milliseconds(),
+				backoff.Lockout.Milliseconds(),
+			).Int64()
+			if err != nil {
+				return 0, err
+			}
+
+			return time.Duration(wait) * time.Millisecond, nil
+		},
+	)
+}
+
+func (a *redisLoginAttempts) Release(ctx context.Context, key string) error {
+	return a.write(
+		func(local *memoryLoginAttempts) error { return local.Release(ctx, key) },
+		func(client redis.Cmdable) error {
+			return releaseScript.Run(ctx, client, []string{loginFailuresKeyPrefix + key}).Err()
+		},
+	)
+}
+
+func (a *redisLoginAttempts) Reset(ctx context.Context, key string) error {
+	return a.write(
+		func(local *memoryLoginAttempts) error { return local.Reset(ctx, key) },
+		func(client redis.Cmdable) error { return client.Del(ctx, loginFailuresKeyPrefix+key).Err() },
+	)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func testLoginBackoff() LoginBackoff {
+	return LoginBackoff{FreeAttempts: 2, LockoutAttempts: 5, BaseDelay: time.Minute, Lockout: time.Hour}
+}
+
+func TestLoginBackoffDoublesUntilLockout(t *testing.T) {
+	t.Parallel()
+
+	backoff := testLoginBackoff()
+	for failures, want := range []time.Duration{0, 0, 0, time.Minute, 2 * time.Minute, time.Hour, time.Hour} {
+		require.Equal(t, want, backoff.delay(failures), "failures %d", failures)
+	}
+
+	backoff.LockoutAttempts = 100
+	require.Equal(t, time.Hour, backoff.delay(50))
+}
+
+func TestLoginThrottleBacksOffAfterFreeAttempts(t *testing.T) {
+	t.Parallel()
+
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	ctx := context.Background()
+
+	for range 3 {
+		require.NoError(t, throttle.Reserve(ctx, "user@example.com", ""))
+	}
+
+	err := throttle.Reserve(ctx, "User@Example.com", "")
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	require.InDelta(t, time.Minute, throttled.RetryAfter, float64(time.Second))
+
+	require.NoError(t, throttle.Reserve(ctx, "other@example.com", ""))
+
+	require.NoError(t, throttle.Reset(ctx, "user@example.com"))
+	require.NoError(t, throttle.Reserve(ctx, "user@example.com", ""))
+}
+
+func TestLoginThrottleCountsClientIPAcrossEmails(t *testing.T) {
+	t.Parallel()
+
+	email := LoginBackoff{FreeAttempts: 10, LockoutAttempts: 20, BaseDelay: time.Minute, Lockout: time.Hour}
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), email, testLoginBackoff(), 3)
+	ctx := context.Background()
+
+	for _, address := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		require.NoError(t, throttle.Reserve(ctx, address, "10.0.0.1"))
+	}
+
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(throttle.Reserve(ctx, "d@example.com", "10.0.0.1"), &throttled))
+	require.InDelta(t, time.Minute, throttled.RetryAfter, float64(time.Second))
+
+	require.NoError(t, throttle.Reserve(ctx, "d@example.com", "10.0.0.2"))
+}
+
+func TestLoginThrottleReleaseTakesBackClientIPAttempt(t *testing.T) {
+	t.Parallel()
+
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	ctx := context.Background()
+
+	for range 5 {
+		require.NoError(t, throttle.Reserve(ctx, "user@example.com", "10.0.0.1"))
+		require.NoError(t, throttle.Release(ctx, "10.0.0.1"))
+		require.NoError(t, throttle.Reset(ctx, "user@example.com"))
+	}
+
+	require.NoError(t, throttle.Reserve(ctx, "user@example.com", "10.0.0.1"))
+}
+
+func TestLoginThrottleCountsConcurrentAttempts(t *testing.T) {
+	t.Parallel()
+
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int32
+	)
+	for range 20 {
+		wg.Go(func() {
+			if throttle.Reserve(ctx, "user@example.com", "") == nil {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 3, allowed.Load())
+}
+
+func TestMemoryLoginAttemptsForgetsExpiredAttempts(t *testing.T) {
+	t.Parallel()
+
+	attempts := NewMemoryLoginAttempts()
+	ctx := context.Background()
+	backoff := LoginBackoff{LockoutAttempts: 1, Lockout: time.Minute}
+
+	wait, err := attempts.Reserve(ctx, "email:user@example.com", backoff, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = attempts.Reserve(ctx, "email:user@example.com", backoff, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestRedisLoginAttemptsFallsBackToMemory(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() { _ = client.Close() })
+	attempts := NewRedisLoginAttempts(client)
+	ctx := context.Background()
+	backoff := LoginBackoff{LockoutAttempts: 1, Lockout: time.Hour}
+
+	wait, err := attempts.Reserve(ctx, "ip:10.0.0.1", backoff, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = attempts.Reserve(ctx, "ip:10.0.0.1", backoff, time.Now())
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, wait, float64(time.Second))
+}
+
+func newTestRedisLoginAttempts(t *testing.T) (LoginAttempts, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisLoginAttempts(client), server
+}
+
+func TestRedisLoginAttemptsBacksOffAfterFreeAttempts(t *testing.T) {
+	t.Parallel()
+
+	attempts, _ := newTestRedisLoginAttempts(t)
+	ctx := context.Background()
+	backoff := testLoginBackoff()
+	now := time.Now()
+
+	for range backoff.FreeAttempts + 1 {
+		wait, err := attempts.Reserve(ctx, "email:user@example.com", backoff, now)
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+
+	wait, err := attempts.Reserve(ctx, "email:user@example.com", backoff, now.Add(10*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 50*time.Second, wait)
+
+	wait, err = attempts.Reserve(ctx, "email:user@example.com", backoff, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = attempts.Reserve(ctx, "email:user@example.com", backoff, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, wait)
+}
+
+func TestRedisLoginAttemptsCountsConcurrentAttempts(t *testing.T) {
+	t.Parallel()
+
+	attempts, _ := newTestRedisLoginAttempts(t)
+	throttle := NewLoginThrottle(attempts, testLoginBackoff(), testLoginBackoff(), 3)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int32
+	)
+	for range 20 {
+		wg.Go(func() {
+			if throttle.Reserve(ctx, "user@example.com", "") == nil {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 3, allowed.Load())
+}
+
+func TestRedisLoginAttemptsReleaseKeepsCountAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	attempts, server := newTestRedisLoginAttempts(t)
+	ctx := context.Background()
+	key := loginFailuresKeyPrefix + "ip:10.0.0.1"
+
+	require.NoError(t, attempts.Release(ctx, "ip:10.0.0.1"))
+	require.False(t, server.Exists(key))
+
+	_, err := attempts.Reserve(ctx, "ip:10.0.0.1", testLoginBackoff(), time.Now())
+	require.NoError(t, err)
+	require.NoError(t, attempts.Release(ctx, "ip:10.0.0.1"))
+	require.NoError(t, attempts.Release(ctx, "ip:10.0.0.1"))
+
+	require.Equal(t, "0", server.HGet(key, "count"))
+	require.Equal(t, time.Hour, server.TTL(key))
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPMailerEncodesSubject(t *testing.T) {
+	t.Parallel()
+
+	mailer := NewSMTPMailer("localhost", 25, "", "", "taskflow@example.com")
+
+	message := string(mailer.message(Mail{
+		To:      "user@example.com",
+		Subject: "Join Platform\r\nBcc: victim@example.com",
+		Body:    "Hello",
+	}))
+
+	headers, _, ok := strings.Cut(message, "\r\n\r\n")
+	require.True(t, ok)
+	require.NotContains(t, headers, "\r\nBcc:")
+	require.Contains(t, headers, "Subject: =?utf-8?q?Join_Platform=0D=0ABcc:_victim@example.com?=\r\n")
+}
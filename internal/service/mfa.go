@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidMFAToken = errors.New("invalid mfa token")
+	ErrMFATokenExpired = errors.New("mfa token expired")
+)
+
+// mfaTokenPurpose marks the token a login hands out while the second factor
+// is pending, so that it cannot be used as an access token.
+const mfaTokenPurpose = "mfa"
+
+const (
+	mfaSecretBytes    = 20
+	recoveryCodeCount = 10
+	// recoveryCodeBytes give codes of 16 base32 characters.
+	recoveryCodeBytes = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type MFARepository interface {
+	// Get fails with domain.ErrMFANotEnabled when the user has not enrolled.
+	Get(ctx context.Context, userID uuid.UUID) (domain.MFA, error)
+	// Save stores a new enrollment in place of an unconfirmed one. It fails
+	// with domain.ErrMFAAlreadyEnabled when the user has a confirmed one.
+	Save(ctx context.Context, mfa domain.MFA) error
+	// Enable confirms the enrollment and replaces the recovery codes. It fails
+	// with domain.ErrMFAAlreadyEnabled when the enrollment has been confirmed
+	// or replaced in the meantime.
+	Enable(ctx context.Context, mfa domain.MFA, codes []domain.MFARecoveryCode) error
+	// UseStep records the time step of an accepted code. It fails with
+	// domain.ErrInvalidMFACode when a code for the same or a later step has
+	// been accepted in the meantime.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// UseRecoveryCode marks an unused recovery code used. It fails with
+	// domain.ErrInvalidMFACode when the user has no such unused code.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// MFAEnrollment is what a user adds to an authenticator app, either by typing
+// the secret or by scanning the URI as a QR code.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type MFAService struct {
+	MFARepository MFARepository
+	userService   *UserService
+	tokenService  *TokenService
+	// issuer is the name authenticator apps show next to the account.
+	issuer string
+	// pendingTTL is how long a login may wait for the second factor.
+	pendingTTL time.Duration
+	// throttle may be nil, in which case codes checked by signed-in users are
+	// not limited.
+	throttle *LoginThrottle
+}
+
+func NewMFAService(
+	repository MFARepository,
+	userService *UserService,
+	tokenService *TokenService,
+	issuer string,
+	pendingTTL time.Duration,
+	throttle *LoginThrottle,
+) *MFAService {
+	return &MFAService{
+		MFARepository: repository,
+		userService:   userService,
+		tokenService:  tokenService,
+		issuer:        issuer,
+		pendingTTL:    pendingTTL,
+		throttle:      throttle,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. It takes effect only once
+// confirmed; enrolling again before that replaces the secret.
+func (s *MFAService) Enroll(ctx context.Context, userID uuid.UUID) (MFAEnrollment, error) {
+	user, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	secret := make([]byte, mfaSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return MFAEnrollment{}, fmt.Errorf("generate totp secret: %w", err)
+	}
+
+	mfa := domain.NewMFA(user.ID, totpEncoding.EncodeToString(secret))
+	if err := s.MFARepository.Save(ctx, mfa); err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	return MFAEnrollment{Secret: mfa.Secret, URI: mfa.URI(s.issuer, user.Email)}, nil
+}
+
+// Confirm enables two-factor authentication with the first code from the
+// authenticator app and returns the recovery codes. They are shown only once.
+// Wrong codes are throttled like those at login.
+func (s *MFAService) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.MFARepository.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reserve(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := mfa.Confirm(code, time.Now()); err != nil {
+		return nil, err
+	}
+	s.reset(ctx, userID)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashed := make([]domain.MFARecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashed = append(hashed, domain.NewMFARecoveryCode(userID, s.hashRecoveryCode(code)))
+	}
+
+	if err := s.MFARepository.Enable(ctx, mfa, hashed); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. It takes a current code, so
+// that a stolen session alone cannot remove the second factor. Wrong codes
+// are throttled like those at login.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.MFARepository.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled() {
+		return domain.ErrMFANotEnabled
+	}
+
+	if err := s.reserve(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.checkCode(ctx, mfa, code); err != nil {
+		return err
+	}
+	s.reset(ctx, userID)
+
+	return s.MFARepository.Delete(ctx, userID)
+}
+
+// reserve counts a code checked by the signed-in user against the same
+// per-user backoff as codes at login, so that a stolen session cannot guess
+// codes faster than a pending login.
+func (s *MFAService) reserve(ctx context.Context, userID uuid.UUID) error {
+	if s.throttle == nil {
+		return nil
+	}
+
+	return s.throttle.ReserveMFA(ctx, userID, "")
+}
+
+// reset forgets the user's failed codes once a code has been accepted.
+func (s *MFAService) reset(ctx context.Context, userID uuid.UUID) {
+	if s.throttle != nil {
+		_ = s.throttle.ResetMFA(ctx, userID)
+	}
+}
+
+// challenge returns the token a login has to exchange together with a second
+// factor, or an empty token when the user has not enabled one.
+func (s *MFAService) challenge(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	mfa, err := s.MFARepository.Get(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnabled) {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !mfa.Enabled() {
+		return "", time.Time{}, nil
+	}
+
+	expiresAt := time.Now().Add(s.pendingTTL)
+	token, err := s.tokenService.IssueFor(mfaTokenPurpose, userID, s.pendingTTL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// pending returns the claims of the token a login is waiting on the second
+// factor with.
+func (s *MFAService) pending(token string) (TokenClaims, error) {
+	claims, err := s.tokenService.ParseClaimsFor(mfaTokenPurpose, token)
+	if errors.Is(err, ErrTokenExpired) {
+		return TokenClaims{}, ErrMFATokenExpired
+	}
+	if err != nil {
+		return TokenClaims{}, ErrInvalidMFAToken
+	}
+
+	return claims, nil
+}
+
+// verify checks the code a pending login of the user proves the second factor
+// with.
+func (s *MFAService) verify(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.MFARepository.Get(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnabled) {
+		return ErrInvalidMFAToken
+	}
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled() {
+		return ErrInvalidMFAToken
+	}
+
+	return s.checkCode(ctx, mfa, code)
+}
+
+// checkCode accepts a TOTP code once per time step, or an unused recovery
+// code once.
+func (s *MFAService) checkCode(ctx context.Context, mfa domain.MFA, code string) error {
+	if isTOTPCode(code) {
+		if err := mfa.Verify(code, time.Now()); err != nil {
+			return err
+		}
+		return s.MFARepository.UseStep(ctx, mfa.UserID, mfa.LastUsedStep)
+	}
+
+	return s.MFARepository.UseRecoveryCode(ctx, mfa.UserID, s.hashRecoveryCode(code), time.Now())
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which users add when they
+// copy a code by hand.
+func (s *MFAService) hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(strings.TrimSpace(code)))
+	return s.tokenService.HashOpaqueToken(code)
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate recovery code: %w", err)
+	}
+
+	code := strings.ToLower(totpEncoding.EncodeToString(b))
+	return code[:8] + "-" + code[8:], nil
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
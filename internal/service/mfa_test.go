@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testMFASecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+type mfaFixture struct {
+	repo   *mocks.MFARepository
+	users  *mocks.UserRepository
+	tokens *TokenService
+	svc    *MFAService
+	auth   *AuthService
+}
+
+func newMFAFixture(t *testing.T) mfaFixture {
+	f := mfaFixture{
+		repo:   mocks.NewMFARepository(t),
+		users:  mocks.NewUserRepository(t),
+		tokens: NewTokenService("test-secret", mockTTL()),
+	}
+	userService := NewUserService(f.users)
+	f.svc = NewMFAService(f.repo, userService, f.tokens, "Taskflow", 5*time.Minute, nil)
+	f.auth = NewAuthService(userService, f.tokens, nil, 0, nil, nil, f.svc, nil)
+
+	return f
+}
+
+func enabledMFA(userID uuid.UUID) domain.MFA {
+	confirmedAt := time.Now().Add(-time.Hour)
+	return domain.MFA{UserID: userID, Secret: testMFASecret, ConfirmedAt: &confirmedAt}
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+
+	return domain.TOTPCode(key, time.Now().Unix()/30)
+}
+
+// wrongTOTPCode returns a code that is not accepted for any time step within
+// the skew around now.
+func wrongTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+
+	step := time.Now().Unix() / 30
+	for _, code := range []string{"000000", "111111", "222222", "333333"} {
+		if code != domain.TOTPCode(key, step-1) && code != domain.TOTPCode(key, step) && code != domain.TOTPCode(key, step+1) {
+			return code
+		}
+	}
+
+	t.Fatal("no wrong code found")
+	return ""
+}
+
+func (f mfaFixture) expectLogin(t *testing.T, user domain.User, password string) domain.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user.PasswordHash = string(hash)
+	f.users.On("GetByEmail", context.Background(), user.Email).Return(user, nil).Once()
+
+	return user
+}
+
+func TestMFAServiceEnrollReturnsOTPAuthURI(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com"}
+
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Once()
+	f.repo.
+		On("Save", ctx, mock.MatchedBy(func(mfa domain.MFA) bool {
+			return mfa.UserID == user.ID && !mfa.Enabled() && len(mfa.Secret) == 32
+		})).
+		Return(nil).
+		Once()
+
+	enrollment, err := f.svc.Enroll(ctx, user.ID)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Taskflow:user@example.com", uri.Path)
+	require.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	require.Equal(t, "Taskflow", uri.Query().Get("issuer"))
+}
+
+func TestMFAServiceConfirmEnablesWithRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var stored []domain.MFARecoveryCode
+	f.repo.On("Get", ctx, userID).Return(domain.MFA{UserID: userID, Secret: testMFASecret}, nil).Once()
+	f.repo.
+		On("Enable", ctx, mock.MatchedBy(func(mfa domain.MFA) bool {
+			return mfa.Enabled() && mfa.LastUsedStep > 0
+		}), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]domain.MFARecoveryCode) }).
+		Return(nil).
+		Once()
+
+	codes, err := f.svc.Confirm(ctx, userID, currentTOTPCode(t, testMFASecret))
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	require.Len(t, stored, 10)
+	for i, code := range codes {
+		require.Equal(t, userID, stored[i].UserID)
+		require.Equal(t, f.svc.hashRecoveryCode(code), stored[i].CodeHash)
+		require.NotContains(t, stored[i].CodeHash, code)
+	}
+}
+
+func TestMFAServiceConfirmRejectsWrongCode(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	code := currentTOTPCode(t, testMFASecret)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	f.repo.On("Get", ctx, userID).Return(domain.MFA{UserID: userID, Secret: testMFASecret}, nil).Once()
+
+	_, err := f.svc.Confirm(ctx, userID, wrong)
+	require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+}
+
+func TestMFAServiceConfirmThrottlesWrongCodes(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	svc := NewMFAService(f.repo, NewUserService(f.users), f.tokens, "Taskflow", 5*time.Minute,
+		NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	f.repo.On("Get", ctx, userID).Return(domain.MFA{UserID: userID, Secret: testMFASecret}, nil).Times(4)
+
+	for range 3 {
+		_, err := svc.Confirm(ctx, userID, wrongTOTPCode(t, testMFASecret))
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	_, err := svc.Confirm(ctx, userID, currentTOTPCode(t, testMFASecret))
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	require.Positive(t, throttled.RetryAfter)
+}
+
+func TestMFAServiceDisableThrottlesWrongCodes(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	svc := NewMFAService(f.repo, NewUserService(f.users), f.tokens, "Taskflow", 5*time.Minute,
+		NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Times(4)
+
+	for range 3 {
+		err := svc.Disable(ctx, userID, wrongTOTPCode(t, testMFASecret))
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	err := svc.Disable(ctx, userID, currentTOTPCode(t, testMFASecret))
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	f.repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestMFAServiceDisableResetsFailuresAfterRightCode(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	svc := NewMFAService(f.repo, NewUserService(f.users), f.tokens, "Taskflow", 5*time.Minute, throttle)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Times(3)
+	f.repo.On("UseStep", ctx, userID, mock.AnythingOfType("int64")).Return(nil).Once()
+	f.repo.On("Delete", ctx, userID).Return(nil).Once()
+
+	for range 2 {
+		err := svc.Disable(ctx, userID, wrongTOTPCode(t, testMFASecret))
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+	require.NoError(t, svc.Disable(ctx, userID, currentTOTPCode(t, testMFASecret)))
+
+	for range 3 {
+		require.NoError(t, throttle.ReserveMFA(ctx, userID, ""))
+	}
+}
+
+func TestAuthServiceLoginWithMFAReturnsPendingToken(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	user := f.expectLogin(t, domain.User{ID: uuid.New(), Email: "user@example.com"}, "password123")
+
+	f.repo.On("Get", ctx, user.ID).Return(enabledMFA(user.ID), nil).Once()
+
+	pair, err := f.auth.Login(ctx, user.Email, "password123", "")
+	require.NoError(t, err)
+	require.Empty(t, pair.AccessToken)
+	require.Empty(t, pair.RefreshToken)
+	require.NotEmpty(t, pair.MFAToken)
+
+	_, err = f.tokens.Parse(pair.MFAToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthServiceLoginWithoutMFAIssuesTokens(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	user := f.expectLogin(t, domain.User{ID: uuid.New(), Email: "user@example.com"}, "password123")
+
+	f.repo.On("Get", ctx, user.ID).Return(domain.MFA{}, domain.ErrMFANotEnabled).Once()
+
+	pair, err := f.auth.Login(ctx, user.Email, "password123", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, pair.AccessToken)
+	require.Empty(t, pair.MFAToken)
+}
+
+func TestAuthServiceLoginFailsWhenMFACannotBeChecked(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	user := f.expectLogin(t, domain.User{ID: uuid.New(), Email: "user@example.com"}, "password123")
+
+	f.repo.On("Get", ctx, user.ID).Return(domain.MFA{}, errors.New("connection refused")).Once()
+
+	pair, err := f.auth.Login(ctx, user.Email, "password123", "")
+	require.Error(t, err)
+	require.Empty(t, pair.AccessToken)
+}
+
+func TestAuthServiceVerifyMFAAcceptsTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Once()
+	f.repo.On("UseStep", ctx, userID, mock.AnythingOfType("int64")).Return(nil).Once()
+
+	pair, err := f.auth.VerifyMFA(ctx, token, currentTOTPCode(t, testMFASecret))
+	require.NoError(t, err)
+
+	subject, err := f.tokens.Parse(pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, userID, subject)
+
+	_, err = f.auth.VerifyMFA(ctx, token, currentTOTPCode(t, testMFASecret))
+	require.ErrorIs(t, err, ErrInvalidMFAToken)
+}
+
+func TestAuthServiceVerifyMFARejectsReplayedCode(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	mfa := enabledMFA(userID)
+	mfa.LastUsedStep = time.Now().Unix()/30 + 1
+	f.repo.On("Get", ctx, userID).Return(mfa, nil).Once()
+
+	_, err = f.auth.VerifyMFA(ctx, token, currentTOTPCode(t, testMFASecret))
+	require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+}
+
+func TestAuthServiceVerifyMFAAcceptsRecoveryCodeOnce(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	code := "abcdefgh-ijklmnop"
+
+	token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	next, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Twice()
+	f.repo.
+		On("UseRecoveryCode", ctx, userID, f.svc.hashRecoveryCode(code), mock.AnythingOfType("time.Time")).
+		Return(nil).
+		Once()
+	f.repo.
+		On("UseRecoveryCode", ctx, userID, f.svc.hashRecoveryCode(code), mock.AnythingOfType("time.Time")).
+		Return(domain.ErrInvalidMFACode).
+		Once()
+
+	_, err = f.auth.VerifyMFA(ctx, token, strings.ToUpper(code))
+	require.NoError(t, err)
+
+	_, err = f.auth.VerifyMFA(ctx, next, code)
+	require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+}
+
+func TestAuthServiceVerifyMFARejectsAccessToken(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+
+	accessToken, err := f.tokens.Issue(uuid.New())
+	require.NoError(t, err)
+
+	_, err = f.auth.VerifyMFA(context.Background(), accessToken, "123456")
+	require.ErrorIs(t, err, ErrInvalidMFAToken)
+}
+
+func TestAuthServiceVerifyMFABurnsTokenAfterTooManyCodes(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	user := LoginBackoff{FreeAttempts: 10, LockoutAttempts: 20, BaseDelay: time.Minute, Lockout: time.Hour}
+	auth := NewAuthService(NewUserService(f.users), f.tokens, nil, 0, nil, nil, f.svc,
+		NewLoginThrottle(NewMemoryLoginAttempts(), user, user, 3))
+
+	token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Times(3)
+
+	for range 3 {
+		_, err = auth.VerifyMFA(ctx, token, wrongTOTPCode(t, testMFASecret))
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	_, err = auth.VerifyMFA(ctx, token, currentTOTPCode(t, testMFASecret))
+	require.ErrorIs(t, err, ErrInvalidMFAToken)
+}
+
+func TestAuthServiceVerifyMFAThrottlesCodesAcrossTokens(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	auth := NewAuthService(NewUserService(f.users), f.tokens, nil, 0, nil, nil, f.svc,
+		NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 10))
+
+	f.repo.On("Get", ctx, userID).Return(enabledMFA(userID), nil).Times(3)
+
+	for range 3 {
+		token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+		require.NoError(t, err)
+
+		_, err = auth.VerifyMFA(ctx, token, wrongTOTPCode(t, testMFASecret))
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	token, err := f.tokens.IssueFor(mfaTokenPurpose, userID, time.Minute)
+	require.NoError(t, err)
+
+	_, err = auth.VerifyMFA(ctx, token, currentTOTPCode(t, testMFASecret))
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+}
+
+func TestAuthServiceLoginWithMFAResetsEmailFailuresOnlyAfterSecondFactor(t *testing.T) {
+	t.Parallel()
+
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	auth := NewAuthService(NewUserService(f.users), f.tokens, nil, 0, nil, nil, f.svc, throttle)
+
+	user := f.expectLogin(t, domain.User{ID: uuid.New(), Email: "user@example.com"}, "password123")
+	f.users.On("GetByEmail", ctx, user.Email).Return(user, nil).Twice()
+	f.repo.On("Get", ctx, user.ID).Return(enabledMFA(user.ID), nil).Times(4)
+	f.repo.On("UseStep", ctx, user.ID, mock.AnythingOfType("int64")).Return(nil).Once()
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Once()
+
+	var pair TokenPair
+	for range 3 {
+		var err error
+		pair, err = auth.Login(ctx, user.Email, "password123", "")
+		require.NoError(t, err)
+	}
+
+	_, err := auth.Login(ctx, user.Email, "password123", "")
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	_, err = auth.VerifyMFA(ctx, pair.MFAToken, currentTOTPCode(t, testMFASecret))
+	require.NoError(t, err)
+
+	require.NoError(t, throttle.Reserve(ctx, user.Email, ""))
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"taskflow/internal/domain"
+	"taskflow/internal/lib/logger/logger"
+	"time"
+)
+
+var ErrPasswordResetNotFound = errors.New("password reset not found")
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset domain.PasswordReset) error
+	GetByHash(ctx context.Context, tokenHash string) (domain.PasswordReset, error)
+	// Use stores the reset as used together with the new password hash. It
+	// fails with domain.ErrPasswordResetUsed when the reset has been used in
+	// the meantime.
+	Use(ctx context.Context, reset domain.PasswordReset, passwordHash string) error
+}
+
+type PasswordResetService struct {
+	PasswordResetRepository PasswordResetRepository
+	authService             *AuthService
+	mailer                  Mailer
+	// resetURL is the page the reset mail links to; the token is appended as
+	// a query parameter.
+	resetURL string
+	ttl      time.Duration
+	logger   logger.Logger
+	// pending tracks the reset mails still being sent.
+	pending sync.WaitGroup
+}
+
+func NewPasswordResetService(
+	repository PasswordResetRepository,
+	authService *AuthService,
+	mailer Mailer,
+	resetURL string,
+	ttl time.Duration,
+	logger logger.Logger,
+) *PasswordResetService {
+	return &PasswordResetService{
+		PasswordResetRepository: repository,
+		authService:             authService,
+		mailer:                  mailer,
+		resetURL:                resetURL,
+		ttl:                     ttl,
+		logger:                  logger,
+	}
+}
+
+// Forgot mails a password reset link to the user with the email. The mail is
+// looked up, stored, and sent in the background, so that the answer comes as
+// fast, and the same, for unknown emails, which are ignored, as for accounts.
+// Requests are throttled per email and client IP before that; a throttled
+// request gets a *LoginThrottledError.
+func (s *PasswordResetService) Forgot(ctx context.Context, email, clientIP string) error {
+	if throttle := s.authService.throttle; throttle != nil {
+		if err := throttle.ReserveForgot(ctx, email, clientIP); err != nil {
+			return err
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	s.pending.Go(func() {
+		if err := s.forgot(ctx, email); err != nil {
+			s.logger.ErrorContext(ctx, "failed to send password reset", "error", err)
+		}
+	})
+
+	return nil
+}
+
+// Wait blocks until the reset mails requested so far have been sent or have
+// failed.
+func (s *PasswordResetService) Wait() {
+	s.pending.Wait()
+}
+
+func (s *PasswordResetService) forgot(ctx context.Context, email string) error {
+	user, err := s.authService.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.authService.tokenService.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	reset := domain.NewPasswordReset(user.ID, s.authService.tokenService.HashOpaqueToken(token), s.ttl)
+	if err := s.PasswordResetRepository.Create(ctx, reset); err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, s.resetMail(user, reset, token)); err != nil {
+		return fmt.Errorf("send password reset: %w", err)
+	}
+
+	return nil
+}
+
+// Reset sets a new password with the token from a reset mail and signs the
+// user out everywhere, since whoever knew the old password may hold a session.
+// It also lifts a lockout from failed logins; clearing it is best effort.
+func (s *PasswordResetService) Reset(ctx context.Context, token, password string) error {
+	reset, err := s.PasswordResetRepository.GetByHash(ctx, s.authService.tokenService.HashOpaqueToken(token))
+	if err != nil {
+		return ErrPasswordResetNotFound
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := reset.Use(time.Now()); err != nil {
+		return err
+	}
+
+	if err := s.PasswordResetRepository.Use(ctx, reset, hash); err != nil {
+		return err
+	}
+
+	_ = s.authService.unlock(ctx, reset.UserID)
+
+	return s.authService.LogoutAll(ctx, reset.UserID)
+}
+
+func (s *PasswordResetService) resetMail(user domain.User, reset domain.PasswordReset, token string) Mail {
+	return Mail{
+		To:      user.Email,
+		Subject: "Reset your Taskflow password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your Taskflow account.\n\n"+
+				"Set a new password: %s?token=%s\n\n"+
+				"The link works once and expires on %s. If you did not ask for it, ignore this mail.",
+			s.resetURL,
+			token,
+			reset.ExpiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/internal/lib/logger/logger"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type passwordResetFixture struct {
+	resets        *mocks.PasswordResetRepository
+	users         *mocks.UserRepository
+	refreshTokens *mocks.RefreshTokenRepository
+	tokens        *TokenService
+	denylist      TokenDenylist
+	mailer        *recordingMailer
+	svc           *PasswordResetService
+}
+
+func newPasswordResetFixture(t *testing.T) passwordResetFixture {
+	f := passwordResetFixture{
+		resets:        mocks.NewPasswordResetRepository(t),
+		users:         mocks.NewUserRepository(t),
+		refreshTokens: mocks.NewRefreshTokenRepository(t),
+		tokens:        NewTokenService("test-secret", mockTTL()),
+		denylist:      NewMemoryTokenDenylist(),
+		mailer:        &recordingMailer{},
+	}
+	auth := NewAuthService(NewUserService(f.users), f.tokens, f.refreshTokens, time.Hour, f.denylist, nil, nil, nil)
+	f.svc = NewPasswordResetService(f.resets, auth, f.mailer, "https://taskflow.test/password/reset", time.Hour, logger.NewSlogLogger())
+
+	return f
+}
+
+func TestPasswordResetServiceForgotMailsToken(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com"}
+
+	var stored domain.PasswordReset
+	f.users.On("GetByEmail", mock.Anything, "user@example.com").Return(user, nil).Once()
+	f.resets.
+		On("Create", mock.Anything, mock.MatchedBy(func(reset domain.PasswordReset) bool {
+			stored = reset
+			return reset.UserID == user.ID && reset.UsedAt == nil
+		})).
+		Return(nil).
+		Once()
+
+	require.NoError(t, f.svc.Forgot(ctx, "user@example.com", ""))
+	f.svc.Wait()
+
+	require.Len(t, f.mailer.sent, 1)
+	require.Equal(t, user.Email, f.mailer.sent[0].To)
+
+	link := f.mailer.sent[0].Body[strings.Index(f.mailer.sent[0].Body, "https://"):]
+	parsed, err := url.Parse(strings.Fields(link)[0])
+	require.NoError(t, err)
+	token := parsed.Query().Get("token")
+	require.Equal(t, f.tokens.HashOpaqueToken(token), stored.TokenHash)
+}
+
+func TestPasswordResetServiceForgotIgnoresUnknownEmail(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+
+	f.users.On("GetByEmail", mock.Anything, "nobody@example.com").Return(domain.User{}, assertErrUserNotFound()).Once()
+
+	require.NoError(t, f.svc.Forgot(ctx, "nobody@example.com", ""))
+	f.svc.Wait()
+	require.Empty(t, f.mailer.sent)
+	f.resets.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPasswordResetServiceForgotThrottlesRequestsForAnyEmail(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	auth := NewAuthService(NewUserService(f.users), f.tokens, f.refreshTokens, time.Hour, f.denylist, nil, nil, throttle)
+	svc := NewPasswordResetService(f.resets, auth, f.mailer, "https://taskflow.test/password/reset", time.Hour, logger.NewSlogLogger())
+
+	f.users.On("GetByEmail", mock.Anything, "nobody@example.com").Return(domain.User{}, assertErrUserNotFound()).Times(3)
+
+	for range 3 {
+		require.NoError(t, svc.Forgot(ctx, "nobody@example.com", "203.0.113.7"))
+	}
+	svc.Wait()
+
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, svc.Forgot(ctx, "nobody@example.com", "203.0.113.8"), &throttled)
+	require.Positive(t, throttled.RetryAfter)
+
+	// Reset requests are counted apart from login attempts.
+	require.NoError(t, throttle.Reserve(ctx, "nobody@example.com", "203.0.113.7"))
+}
+
+func TestPasswordResetServiceResetChangesPasswordAndRevokesSessions(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	reset := domain.NewPasswordReset(userID, f.tokens.HashOpaqueToken("reset-token"), time.Hour)
+
+	accessToken, err := f.tokens.Issue(userID)
+	require.NoError(t, err)
+	claims, err := f.tokens.ParseClaims(accessToken)
+	require.NoError(t, err)
+
+	f.resets.On("GetByHash", ctx, reset.TokenHash).Return(reset, nil).Once()
+	f.resets.
+		On("Use", ctx, mock.MatchedBy(func(used domain.PasswordReset) bool {
+			return used.ID == reset.ID && used.UsedAt != nil
+		}), mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")) == nil
+		})).
+		Return(nil).
+		Once()
+	f.refreshTokens.On("RevokeUser", ctx, userID).Return(nil).Once()
+
+	require.NoError(t, f.svc.Reset(ctx, "reset-token", "new-password"))
+
+	denied, err := f.denylist.IsDenied(ctx, claims)
+	require.NoError(t, err)
+	require.True(t, denied)
+}
+
+func TestPasswordResetServiceResetUnlocksLogin(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+	user := domain.User{ID: uuid.New(), Email: "user@example.com"}
+	reset := domain.NewPasswordReset(user.ID, f.tokens.HashOpaqueToken("reset-token"), time.Hour)
+	throttle := NewLoginThrottle(NewMemoryLoginAttempts(), testLoginBackoff(), testLoginBackoff(), 3)
+	auth := NewAuthService(NewUserService(f.users), f.tokens, f.refreshTokens, time.Hour, f.denylist, nil, nil, throttle)
+	svc := NewPasswordResetService(f.resets, auth, f.mailer, "https://taskflow.test/password/reset", time.Hour, logger.NewSlogLogger())
+
+	for range 3 {
+		require.NoError(t, throttle.Reserve(ctx, user.Email, ""))
+	}
+	require.ErrorIs(t, throttle.Reserve(ctx, user.Email, ""), ErrTooManyLoginAttempts)
+
+	f.resets.On("GetByHash", ctx, reset.TokenHash).Return(reset, nil).Once()
+	f.resets.On("Use", ctx, mock.Anything, mock.Anything).Return(nil).Once()
+	f.users.On("Get", ctx, user.ID).Return(user, nil).Once()
+	f.refreshTokens.On("RevokeUser", ctx, user.ID).Return(nil).Once()
+
+	require.NoError(t, svc.Reset(ctx, "reset-token", "new-password"))
+	require.NoError(t, throttle.Reserve(ctx, user.Email, ""))
+}
+
+func TestPasswordResetServiceResetRejectsUnusableTokens(t *testing.T) {
+	t.Parallel()
+
+	usedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name  string
+		reset func(domain.PasswordReset) domain.PasswordReset
+		err   error
+	}{
+		{
+			name: "used",
+			reset: func(r domain.PasswordReset) domain.PasswordReset {
+				r.UsedAt = &usedAt
+				return r
+			},
+			err: domain.ErrPasswordResetUsed,
+		},
+		{
+			name: "expired",
+			reset: func(r domain.PasswordReset) domain.PasswordReset {
+				r.ExpiresAt = time.Now().Add(-time.Second)
+				return r
+			},
+			err: domain.ErrPasswordResetExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newPasswordResetFixture(t)
+			ctx := context.Background()
+			reset := tt.reset(domain.NewPasswordReset(uuid.New(), f.tokens.HashOpaqueToken("reset-token"), time.Hour))
+
+			f.resets.On("GetByHash", ctx, reset.TokenHash).Return(reset, nil).Once()
+
+			err := f.svc.Reset(ctx, "reset-token", "new-password")
+
+			require.ErrorIs(t, err, tt.err)
+			f.resets.AssertNotCalled(t, "Use", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPasswordResetServiceResetRejectsUnknownToken(t *testing.T) {
+	t.Parallel()
+
+	f := newPasswordResetFixture(t)
+	ctx := context.Background()
+
+	f.resets.
+		On("GetByHash", ctx, f.tokens.HashOpaqueToken("unknown")).
+		Return(domain.PasswordReset{}, assertErrUserNotFound()).
+		Once()
+
+	require.ErrorIs(t, f.svc.Reset(ctx, "unknown", "new-password"), ErrPasswordResetNotFound)
+}
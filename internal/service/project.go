@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrMemberNotFound  = errors.New("project member not found")
+)
+
+type ProjectRepository interface {
+	// Create stores the project together with the owner's membership.
+	Create(ctx context.Context, project domain.Project) (domain.Project, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Project, error)
+	// Personal returns the user's personal project, creating it on first use.
+	Personal(ctx context.Context, userID uuid.UUID) (domain.Project, error)
+	ListByMember(ctx context.Context, userID uuid.UUID) ([]domain.Project, error)
+	Update(ctx context.Context, project domain.Project) (domain.Project, error)
+	// Delete removes the project together with its tasks and memberships.
+	Delete(ctx context.Context, id uuid.UUID) error
+	Member(ctx context.Context, projectID, userID uuid.UUID) (domain.ProjectMember, error)
+	ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error)
+	// SaveMember adds the member or updates the role of an existing one.
+	SaveMember(ctx context.Context, member domain.ProjectMember) (domain.ProjectMember, error)
+	// RemoveMember also unassigns the user from the tasks of the project and
+	// returns the new versions of those tasks.
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) (map[uuid.UUID]int64, error)
+}
+
+type ProjectService struct {
+	ProjectRepository ProjectRepository
+	UserRepository    UserRepository
+	taskService       *TaskService
+	// requireVerifiedEmail keeps users who have not verified their email out
+	// of shared projects: they can neither be added or invited nor invite.
+	requireVerifiedEmail bool
+}
+
+func NewProjectService(
+	repository ProjectRepository,
+	users UserRepository,
+	taskService *TaskService,
+	requireVerifiedEmail bool,
+) *ProjectService {
+	return &ProjectService{
+		ProjectRepository:    repository,
+		UserRepository:       users,
+		taskService:          taskService,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, userID uuid.UUID, name string) (domain.Project, error) {
+	project, err := domain.NewProject(userID, name)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	return s.ProjectRepository.Create(ctx, project)
+}
+
+// ListProjects returns the projects the user is a member of, the personal one
+// included.
+func (s *ProjectService) ListProjects(ctx context.Context, userID uuid.UUID) ([]domain.Project, error) {
+	if _, err := s.ProjectRepository.Personal(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.ProjectRepository.ListByMember(ctx, userID)
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, userID, projectID uuid.UUID) (domain.Project, error) {
+	project, _, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionViewTasks)
+	return project, err
+}
+
+// MemberRole returns the role the user holds in the project.
+func (s *ProjectService) MemberRole(ctx context.Context, userID, projectID uuid.UUID) (domain.ProjectRole, error) {
+	member, err := s.ProjectRepository.Member(ctx, projectID, userID)
+	if err != nil {
+		return "", ErrProjectNotFound
+	}
+
+	return member.Role, nil
+}
+
+func (s *ProjectService) RenameProject(ctx context.Context, userID, projectID uuid.UUID, name string) (domain.Project, error) {
+	project, _, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionUpdateProject)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	if err := project.Rename(name); err != nil {
+		return domain.Project{}, err
+	}
+
+	return s.ProjectRepository.Update(ctx, project)
+}
+
+// DeleteProject removes a shared project together with its tasks.
+func (s *ProjectService) DeleteProject(ctx context.Context, userID, projectID uuid.UUID) error {
+	project, _, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionDeleteProject)
+	if err != nil {
+		return err
+	}
+
+	if err := project.CheckDelete(); err != nil {
+		return err
+	}
+
+	return s.ProjectRepository.Delete(ctx, project.ID)
+}
+
+func (s *ProjectService) ListMembers(ctx context.Context, userID, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	if _, _, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionViewTasks); err != nil {
+		return nil, err
+	}
+
+	return s.ProjectRepository.ListMembers(ctx, projectID)
+}
+
+// SetMember shares the project with another user or changes the role of an
+// existing member. Owners and admins may grant only roles below their own.
+func (s *ProjectService) SetMember(
+	ctx context.Context,
+	userID, projectID, memberID uuid.UUID,
+	role domain.ProjectRole,
+) (domain.ProjectMember, error) {
+	project, actor, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionManageMembers)
+	if err != nil {
+		return domain.ProjectMember{}, err
+	}
+
+	member, err := s.ProjectRepository.Member(ctx, projectID, memberID)
+	if err == nil {
+		if member.Role == role {
+			return member, nil
+		}
+
+		current := member.Role
+		if err := member.ChangeRole(role); err != nil {
+			return domain.ProjectMember{}, err
+		}
+		if !actor.Role.CanAssign(current, role) {
+			return domain.ProjectMember{}, ErrForbidden
+		}
+
+		return s.ProjectRepository.SaveMember(ctx, member)
+	}
+
+	member, err = project.AddMember(memberID, role)
+	if err != nil {
+		return domain.ProjectMember{}, err
+	}
+	if !actor.Role.CanAssign("", role) {
+		return domain.ProjectMember{}, ErrForbidden
+	}
+
+	user, err := s.UserRepository.Get(ctx, memberID)
+	if err != nil {
+		return domain.ProjectMember{}, ErrUserNotFound
+	}
+	if err := s.checkVerified(user); err != nil {
+		return domain.ProjectMember{}, err
+	}
+
+	return s.ProjectRepository.SaveMember(ctx, member)
+}
+
+// RemoveMember takes a user out of the project. Owners and admins may remove
+// members ranked below them; everybody but the owner may leave.
+func (s *ProjectService) RemoveMember(ctx context.Context, userID, projectID, memberID uuid.UUID) error {
+	project, actor, err := s.authorizeProject(ctx, userID, projectID, domain.PermissionViewTasks)
+	if err != nil {
+		return err
+	}
+
+	if err := project.CheckRemoveMember(memberID); err != nil {
+		return err
+	}
+
+	member, err := s.ProjectRepository.Member(ctx, projectID, memberID)
+	if err != nil {
+		return ErrMemberNotFound
+	}
+
+	if memberID != userID && !actor.Role.CanRemove(member.Role) {
+		return ErrForbidden
+	}
+
+	unassigned, err := s.ProjectRepository.RemoveMember(ctx, projectID, memberID)
+	if err != nil {
+		return err
+	}
+
+	// Cached tasks would otherwise keep showing the former member as assignee.
+	if s.taskService != nil {
+		for taskID, version := range unassigned {
+			s.taskService.invalidateCachedTask(ctx, taskID, version)
+		}
+	}
+
+	return nil
+}
+
+// authorizeProject loads a project together with the caller's membership and
+// checks that the caller's role grants the permission. Projects the caller is
+// not a member of are reported as missing.
+func (s *ProjectService) authorizeProject(
+	ctx context.Context,
+	userID, projectID uuid.UUID,
+	permission domain.Permission,
+) (domain.Project, domain.ProjectMember, error) {
+	member, err := s.ProjectRepository.Member(ctx, projectID, userID)
+	if err != nil {
+		return domain.Project{}, domain.ProjectMember{}, ErrProjectNotFound
+	}
+
+	project, err := s.ProjectRepository.Get(ctx, projectID)
+	if err != nil {
+		return domain.Project{}, domain.ProjectMember{}, ErrProjectNotFound
+	}
+
+	if !member.Role.Can(permission) {
+		return domain.Project{}, domain.ProjectMember{}, ErrForbidden
+	}
+
+	return project, member, nil
+}
+
+// authorize checks that the user holds the permission in the task's project.
+// Tasks of projects the user is not a member of are reported as missing.
+// Without a project repository every task is private to its creator.
+func (s *TaskService) authorize(ctx context.Context, userID uuid.UUID, task domain.Task, permission domain.Permission) error {
+	if s.ProjectRepository == nil {
+		if task.UserID != userID {
+			return ErrTaskNotFound
+		}
+		return nil
+	}
+
+	member, err := s.ProjectRepository.Member(ctx, task.ProjectID, userID)
+	if err != nil {
+		return ErrTaskNotFound
+	}
+
+	if !member.Role.Can(permission) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// projectFor picks the project a new task goes to: the requested one, the
+// project of the parent task or the user's personal project. The user has to
+// be allowed to edit tasks there.
+func (s *TaskService) projectFor(ctx context.Context, userID uuid.UUID, input CreateTaskInput) (domain.Project, error) {
+	if s.ProjectRepository == nil {
+		return domain.Project{OwnerID: userID, Personal: true}, nil
+	}
+
+	projectID := input.ProjectID
+	if projectID == nil && input.ParentID != nil {
+		parent, err := s.TaskRepository.Get(ctx, *input.ParentID)
+		if err != nil || s.authorize(ctx, userID, parent, domain.PermissionViewTasks) != nil {
+			return domain.Project{}, ErrParentNotFound
+		}
+		projectID = &parent.ProjectID
+	}
+
+	if projectID == nil {
+		return s.ProjectRepository.Personal(ctx, userID)
+	}
+
+	member, err := s.ProjectRepository.Member(ctx, *projectID, userID)
+	if err != nil {
+		return domain.Project{}, ErrProjectNotFound
+	}
+	if !member.Role.Can(domain.PermissionEditTasks) {
+		return domain.Project{}, ErrForbidden
+	}
+
+	project, err := s.ProjectRepository.Get(ctx, *projectID)
+	if err != nil {
+		return domain.Project{}, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// taskWorkflow returns the workflow of the project owner, which every task of
+// the project moves through.
+func (s *TaskService) taskWorkflow(ctx context.Context, task domain.Task) (domain.Workflow, error) {
+	if s.ProjectRepository == nil {
+		return s.workflow(ctx, task.UserID)
+	}
+
+	project, err := s.ProjectRepository.Get(ctx, task.ProjectID)
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	return s.workflow(ctx, project.OwnerID)
+}
+
+// checkVerified rejects users who have not verified their email when
+// verification is required.
+func (s *ProjectService) checkVerified(user domain.User) error {
+	if s.requireVerifiedEmail && !user.EmailVerified() {
+		return domain.ErrEmailNotVerified
+	}
+
+	return nil
+}
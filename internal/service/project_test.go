@@ -0,0 +1,671 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectServiceCreateProject(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	svc := NewProjectService(repo, nil, nil, false)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(project domain.Project) bool {
+			return project.OwnerID == userID &&
+				project.Name == "Platform" &&
+				!project.Personal &&
+				project.ID != uuid.Nil
+		})).
+		Return(func(_ context.Context, project domain.Project) (domain.Project, error) {
+			return project, nil
+		}).
+		Once()
+
+	project, err := svc.CreateProject(ctx, userID, "  Platform  ")
+
+	require.NoError(t, err)
+	require.Equal(t, userID, project.OwnerID)
+}
+
+func TestProjectServiceCreateProjectRejectsEmptyName(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	svc := NewProjectService(repo, nil, nil, false)
+
+	_, err := svc.CreateProject(context.Background(), uuid.New(), "   ")
+
+	require.ErrorIs(t, err, domain.ErrEmptyProjectName)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestProjectServiceCreateProjectRejectsControlCharacters(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	svc := NewProjectService(repo, nil, nil, false)
+
+	_, err := svc.CreateProject(context.Background(), uuid.New(), "Platform\r\nBcc: victim@example.com")
+
+	require.ErrorIs(t, err, domain.ErrInvalidProjectName)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestProjectServiceGetProjectHidesProjectsOfOthers(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	svc := NewProjectService(repo, nil, nil, false)
+	ctx := context.Background()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	repo.
+		On("Member", ctx, projectID, userID).
+		Return(domain.ProjectMember{}, errors.New("member not found")).
+		Once()
+
+	_, err := svc.GetProject(ctx, userID, projectID)
+
+	require.ErrorIs(t, err, ErrProjectNotFound)
+	repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestProjectServiceSetMember(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		actorRole domain.ProjectRole
+		// current is the role of the target, empty when they are not a member yet.
+		current  domain.ProjectRole
+		role     domain.ProjectRole
+		personal bool
+		wantErr  error
+	}{
+		{name: "owner adds an admin", actorRole: domain.RoleOwner, role: domain.RoleAdmin},
+		{name: "owner adds a viewer", actorRole: domain.RoleOwner, role: domain.RoleViewer},
+		{name: "admin adds an editor", actorRole: domain.RoleAdmin, role: domain.RoleEditor},
+		{name: "admin cannot add an admin", actorRole: domain.RoleAdmin, role: domain.RoleAdmin, wantErr: ErrForbidden},
+		{name: "editor cannot add members", actorRole: domain.RoleEditor, role: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "viewer cannot add members", actorRole: domain.RoleViewer, role: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "owner promotes an editor", actorRole: domain.RoleOwner, current: domain.RoleEditor, role: domain.RoleAdmin},
+		{name: "admin demotes an editor", actorRole: domain.RoleAdmin, current: domain.RoleEditor, role: domain.RoleViewer},
+		{name: "admin cannot demote an admin", actorRole: domain.RoleAdmin, current: domain.RoleAdmin, role: domain.RoleEditor, wantErr: ErrForbidden},
+		{name: "unchanged role is kept", actorRole: domain.RoleOwner, current: domain.RoleEditor, role: domain.RoleEditor},
+		{name: "owner role cannot be changed", actorRole: domain.RoleOwner, current: domain.RoleOwner, role: domain.RoleAdmin, wantErr: domain.ErrOwnerRoleFixed},
+		{name: "owner role cannot be granted", actorRole: domain.RoleOwner, role: domain.RoleOwner, wantErr: domain.ErrInvalidProjectRole},
+		{name: "unknown role is rejected", actorRole: domain.RoleOwner, role: "guest", wantErr: domain.ErrInvalidProjectRole},
+		{name: "personal project cannot be shared", actorRole: domain.RoleOwner, role: domain.RoleEditor, personal: true, wantErr: domain.ErrPersonalProject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewProjectRepository(t)
+			users := mocks.NewUserRepository(t)
+			svc := NewProjectService(repo, users, nil, false)
+			ctx := context.Background()
+			userID := uuid.New()
+			memberID := uuid.New()
+			project := domain.Project{ID: uuid.New(), OwnerID: uuid.New(), Name: "Platform", Personal: tt.personal}
+
+			repo.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: tt.actorRole}, nil).
+				Once()
+			repo.
+				On("Get", ctx, project.ID).
+				Return(project, nil).
+				Once()
+
+			if tt.actorRole.Can(domain.PermissionManageMembers) {
+				var memberErr error
+				if tt.current == "" {
+					memberErr = errors.New("member not found")
+				}
+				repo.
+					On("Member", ctx, project.ID, memberID).
+					Return(domain.ProjectMember{ProjectID: project.ID, UserID: memberID, Role: tt.current}, memberErr).
+					Once()
+			}
+
+			if tt.wantErr == nil && tt.current == "" {
+				users.
+					On("Get", ctx, memberID).
+					Return(domain.User{ID: memberID}, nil).
+					Once()
+			}
+			if tt.wantErr == nil && tt.current != tt.role {
+				repo.
+					On("SaveMember", ctx, mock.MatchedBy(func(member domain.ProjectMember) bool {
+						return member.ProjectID == project.ID && member.UserID == memberID && member.Role == tt.role
+					})).
+					Return(func(_ context.Context, member domain.ProjectMember) (domain.ProjectMember, error) {
+						return member, nil
+					}).
+					Once()
+			}
+
+			member, err := svc.SetMember(ctx, userID, project.ID, memberID, tt.role)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "SaveMember", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, memberID, member.UserID)
+			require.Equal(t, tt.role, member.Role)
+		})
+	}
+}
+
+func TestProjectServiceSetMemberRequiresVerifiedEmail(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	users := mocks.NewUserRepository(t)
+	svc := NewProjectService(repo, users, nil, true)
+	ctx := context.Background()
+	userID := uuid.New()
+	memberID := uuid.New()
+	project := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Platform"}
+
+	repo.
+		On("Member", ctx, project.ID, userID).
+		Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: domain.RoleOwner}, nil).
+		Once()
+	repo.On("Get", ctx, project.ID).Return(project, nil).Once()
+	repo.On("Member", ctx, project.ID, memberID).Return(domain.ProjectMember{}, errors.New("member not found")).Once()
+	users.On("Get", ctx, memberID).Return(domain.User{ID: memberID}, nil).Once()
+
+	_, err := svc.SetMember(ctx, userID, project.ID, memberID, domain.RoleEditor)
+
+	require.ErrorIs(t, err, domain.ErrEmailNotVerified)
+	repo.AssertNotCalled(t, "SaveMember", mock.Anything, mock.Anything)
+}
+
+func TestProjectServiceRemoveMember(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		actorRole  domain.ProjectRole
+		targetRole domain.ProjectRole
+		self       bool
+		wantErr    error
+	}{
+		{name: "owner removes an admin", actorRole: domain.RoleOwner, targetRole: domain.RoleAdmin},
+		{name: "admin removes an editor", actorRole: domain.RoleAdmin, targetRole: domain.RoleEditor},
+		{name: "admin cannot remove an admin", actorRole: domain.RoleAdmin, targetRole: domain.RoleAdmin, wantErr: ErrForbidden},
+		{name: "editor cannot remove others", actorRole: domain.RoleEditor, targetRole: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "viewer leaves", actorRole: domain.RoleViewer, targetRole: domain.RoleViewer, self: true},
+		{name: "owner cannot leave", actorRole: domain.RoleOwner, targetRole: domain.RoleOwner, self: true, wantErr: domain.ErrOwnerCannotLeave},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewProjectRepository(t)
+			svc := NewProjectService(repo, nil, nil, false)
+			ctx := context.Background()
+			userID := uuid.New()
+			removeID := uuid.New()
+			if tt.self {
+				removeID = userID
+			}
+			ownerID := uuid.New()
+			if tt.actorRole == domain.RoleOwner {
+				ownerID = userID
+			}
+			project := domain.Project{ID: uuid.New(), OwnerID: ownerID, Name: "Platform"}
+
+			repo.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: tt.actorRole}, nil)
+			repo.
+				On("Get", ctx, project.ID).
+				Return(project, nil).
+				Once()
+
+			if !tt.self {
+				repo.
+					On("Member", ctx, project.ID, removeID).
+					Return(domain.ProjectMember{ProjectID: project.ID, UserID: removeID, Role: tt.targetRole}, nil).
+					Once()
+			}
+			if tt.wantErr == nil {
+				repo.
+					On("RemoveMember", ctx, project.ID, removeID).
+					Return(map[uuid.UUID]int64{}, nil).
+					Once()
+			}
+
+			err := svc.RemoveMember(ctx, userID, project.ID, removeID)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProjectServiceChecksProjectPermissions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		role    domain.ProjectRole
+		delete  bool
+		wantErr error
+	}{
+		{name: "owner renames", role: domain.RoleOwner},
+		{name: "admin renames", role: domain.RoleAdmin},
+		{name: "editor cannot rename", role: domain.RoleEditor, wantErr: ErrForbidden},
+		{name: "viewer cannot rename", role: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "owner deletes", role: domain.RoleOwner, delete: true},
+		{name: "admin cannot delete", role: domain.RoleAdmin, delete: true, wantErr: ErrForbidden},
+		{name: "editor cannot delete", role: domain.RoleEditor, delete: true, wantErr: ErrForbidden},
+		{name: "viewer cannot delete", role: domain.RoleViewer, delete: true, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewProjectRepository(t)
+			svc := NewProjectService(repo, nil, nil, false)
+			ctx := context.Background()
+			userID := uuid.New()
+			project := domain.Project{ID: uuid.New(), OwnerID: uuid.New(), Name: "Platform"}
+
+			repo.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: tt.role}, nil).
+				Once()
+			repo.
+				On("Get", ctx, project.ID).
+				Return(project, nil).
+				Once()
+
+			var err error
+			if tt.delete {
+				if tt.wantErr == nil {
+					repo.
+						On("Delete", ctx, project.ID).
+						Return(nil).
+						Once()
+				}
+				err = svc.DeleteProject(ctx, userID, project.ID)
+			} else {
+				if tt.wantErr == nil {
+					repo.
+						On("Update", ctx, mock.MatchedBy(func(p domain.Project) bool {
+							return p.ID == project.ID && p.Name == "Core"
+						})).
+						Return(func(_ context.Context, p domain.Project) (domain.Project, error) {
+							return p, nil
+						}).
+						Once()
+				}
+				_, err = svc.RenameProject(ctx, userID, project.ID, "Core")
+			}
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProjectServiceDeleteProjectKeepsPersonalProject(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewProjectRepository(t)
+	svc := NewProjectService(repo, nil, nil, false)
+	ctx := context.Background()
+	userID := uuid.New()
+	project := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Personal", Personal: true}
+
+	repo.
+		On("Member", ctx, project.ID, userID).
+		Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: domain.RoleOwner}, nil).
+		Once()
+	repo.
+		On("Get", ctx, project.ID).
+		Return(project, nil).
+		Once()
+
+	err := svc.DeleteProject(ctx, userID, project.ID)
+
+	require.ErrorIs(t, err, domain.ErrPersonalProject)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceCreateTaskGoesToPersonalProject(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	projects := mocks.NewProjectRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, projects, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	personal := domain.Project{ID: uuid.New(), OwnerID: userID, Name: "Personal", Personal: true}
+
+	projects.
+		On("Personal", ctx, userID).
+		Return(personal, nil).
+		Once()
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.UserID == userID && task.ProjectID == personal.ID
+		})).
+		Return(func(_ context.Context, task domain.Task) (domain.Task, error) {
+			return task, nil
+		}).
+		Once()
+
+	task, err := svc.CreateTask(ctx, userID, CreateTaskInput{Title: "Title"})
+
+	require.NoError(t, err)
+	require.Equal(t, personal.ID, task.ProjectID)
+}
+
+func TestTaskServiceCreateTaskInSharedProject(t *testing.T) {
+	t.Parallel()
+
+	ownerID := uuid.New()
+	project := domain.Project{ID: uuid.New(), OwnerID: ownerID, Name: "Platform"}
+
+	tests := []struct {
+		name string
+		// role is empty for a user who is not a member.
+		role    domain.ProjectRole
+		wantErr error
+	}{
+		{name: "editor creates a task", role: domain.RoleEditor},
+		{name: "admin creates a task", role: domain.RoleAdmin},
+		{name: "viewer is forbidden", role: domain.RoleViewer, wantErr: ErrForbidden},
+		{name: "non-member is rejected", wantErr: ErrProjectNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			projects := mocks.NewProjectRepository(t)
+			workflows := mocks.NewWorkflowRepository(t)
+			svc := NewTaskService(repo, nil, nil, nil, workflows, projects, nil, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+
+			var memberErr error
+			if tt.role == "" {
+				memberErr = errors.New("member not found")
+			}
+			projects.
+				On("Member", ctx, project.ID, userID).
+				Return(domain.ProjectMember{ProjectID: project.ID, UserID: userID, Role: tt.role}, memberErr).
+				Once()
+
+			if tt.wantErr == nil {
+				projects.
+					On("Get", ctx, project.ID).
+					Return(project, nil).
+					Once()
+				// Tasks of a project follow the workflow of its owner.
+				workflows.
+					On("Get", ctx, ownerID).
+					Return(domain.DefaultWorkflow(), nil).
+					Once()
+				repo.
+					On("Create", ctx, mock.MatchedBy(func(task domain.Task) bool {
+						return task.UserID == userID && task.ProjectID == project.ID
+					})).
+					Return(func(_ context.Context, task domain.Task) (domain.Task, error) {
+						return task, nil
+					}).
+					Once()
+			}
+
+			task, err := svc.CreateTask(ctx, userID, CreateTaskInput{Title: "Title", ProjectID: &project.ID})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, userID, task.UserID)
+		})
+	}
+}
+
+func TestTaskServiceGetTaskAuthorizesByMembership(t *testing.T) {
+	t.Parallel()
+
+	creatorID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: creatorID, ProjectID: uuid.New(), Title: "Shared"}
+
+	tests := []struct {
+		name     string
+		isMember bool
+		wantErr  error
+	}{
+		{name: "member sees a task created by someone else", isMember: true},
+		{name: "non-member gets not found", wantErr: ErrTaskNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			projects := mocks.NewProjectRepository(t)
+			svc := NewTaskService(repo, nil, nil, nil, nil, projects, nil, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+
+			var memberErr error
+			if !tt.isMember {
+				memberErr = errors.New("member not found")
+			}
+			projects.
+				On("Member", ctx, task.ProjectID, userID).
+				Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: userID, Role: domain.RoleViewer}, memberErr).
+				Once()
+
+			got, err := svc.GetTask(ctx, userID, task.ID)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, creatorID, got.UserID)
+		})
+	}
+}
+
+func TestTaskServiceAddBlockerRejectsTaskOfAnotherProject(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	projects := mocks.NewProjectRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, projects, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, ProjectID: uuid.New()}
+	blocker := domain.Task{ID: uuid.New(), UserID: userID, ProjectID: uuid.New()}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Get", ctx, blocker.ID).
+		Return(blocker, nil).
+		Once()
+	projects.
+		On("Member", ctx, mock.Anything, userID).
+		Return(domain.ProjectMember{UserID: userID, Role: domain.RoleEditor}, nil)
+
+	_, err := svc.AddBlocker(ctx, userID, task.ID, blocker.ID, nil)
+
+	require.ErrorIs(t, err, domain.ErrProjectMismatch)
+	repo.AssertNotCalled(t, "AddBlocker", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceReopenTaskPublishesActingMember(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	projects := mocks.NewProjectRepository(t)
+	analytics := &recordingPublisher{}
+	svc := NewTaskService(repo, nil, nil, nil, nil, projects, analytics, nil)
+	ctx := context.Background()
+	editorID := uuid.New()
+	completedAt := mockTime()
+	task := domain.Task{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		ProjectID:   uuid.New(),
+		Title:       "Shared",
+		Status:      domain.StatusDone,
+		Category:    domain.CategoryDone,
+		CompletedAt: &completedAt,
+	}
+	reopened := task
+	reopened.Status = domain.StatusPending
+	reopened.Category = domain.CategoryTodo
+	reopened.CompletedAt = nil
+
+	repo.On("Get", ctx, task.ID).Return(task, nil).Once()
+	projects.
+		On("Member", ctx, task.ProjectID, editorID).
+		Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: editorID, Role: domain.RoleEditor}, nil).
+		Once()
+	projects.
+		On("Get", ctx, task.ProjectID).
+		Return(domain.Project{ID: task.ProjectID, OwnerID: task.UserID}, nil).
+		Once()
+	repo.On("Update", ctx, mock.Anything).Return(reopened, nil).Once()
+	repo.On("TouchDependents", ctx, []uuid.UUID{task.ID}).Return(map[uuid.UUID]int64{}, nil).Once()
+
+	_, err := svc.ReopenTask(ctx, editorID, task.ID, "", nil)
+
+	require.NoError(t, err)
+	require.Len(t, analytics.events, 1)
+	require.Equal(t, TaskEventReopened, analytics.events[0].Type)
+	require.Equal(t, editorID, analytics.events[0].UserID)
+}
+
+func TestTaskServiceChecksTaskPermissions(t *testing.T) {
+	t.Parallel()
+
+	const (
+		view    = "view"
+		edit    = "edit"
+		comment = "comment"
+	)
+
+	tests := []struct {
+		name    string
+		role    domain.ProjectRole
+		action  string
+		wantErr error
+	}{
+		{name: "owner views", role: domain.RoleOwner, action: view},
+		{name: "viewer views", role: domain.RoleViewer, action: view},
+		{name: "owner edits", role: domain.RoleOwner, action: edit},
+		{name: "admin edits", role: domain.RoleAdmin, action: edit},
+		{name: "editor edits", role: domain.RoleEditor, action: edit},
+		{name: "viewer cannot edit", role: domain.RoleViewer, action: edit, wantErr: ErrForbidden},
+		{name: "editor comments", role: domain.RoleEditor, action: comment},
+		{name: "admin comments", role: domain.RoleAdmin, action: comment},
+		{name: "viewer cannot comment", role: domain.RoleViewer, action: comment, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			projects := mocks.NewProjectRepository(t)
+			comments := mocks.NewCommentRepository(t)
+			svc := NewTaskService(repo, nil, nil, nil, nil, projects, nil, nil)
+			commentService := NewCommentService(comments, svc)
+			ctx := context.Background()
+			userID := uuid.New()
+			blockerID := uuid.New()
+			task := domain.Task{ID: uuid.New(), UserID: uuid.New(), ProjectID: uuid.New(), Title: "Shared", Version: 1}
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+			projects.
+				On("Member", ctx, task.ProjectID, userID).
+				Return(domain.ProjectMember{ProjectID: task.ProjectID, UserID: userID, Role: tt.role}, nil).
+				Once()
+
+			var err error
+			switch tt.action {
+			case view:
+				_, err = svc.GetTask(ctx, userID, task.ID)
+			case edit:
+				if tt.wantErr == nil {
+					repo.
+						On("RemoveBlocker", ctx, task, blockerID).
+						Return(task, nil).
+						Once()
+				}
+				_, err = svc.RemoveBlocker(ctx, userID, task.ID, blockerID, nil)
+			case comment:
+				if tt.wantErr == nil {
+					comments.
+						On("Create", ctx, mock.AnythingOfType("domain.Comment")).
+						Return(domain.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: userID, Body: "LGTM"}, nil).
+						Once()
+				}
+				_, err = commentService.AddComment(ctx, userID, task.ID, "LGTM")
+			}
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "RemoveBlocker", mock.Anything, mock.Anything, mock.Anything)
+				comments.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
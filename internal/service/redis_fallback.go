@@ -0,0 +1,39 @@
+package service
+
+import "github.com/redis/go-redis/v9"
+
+// redisFallback holds state shared by every API instance in Redis, and in
+// local a copy of the part this instance has touched. Every change is applied
+// to both, so that when Redis cannot be reached the instance still remembers
+// what it did itself.
+type redisFallback[L any] struct {
+	client redis.Cmdable
+	local  L
+}
+
+func newRedisFallback[L any](client redis.Cmdable, local L) redisFallback[L] {
+	return redisFallback[L]{client: client, local: local}
+}
+
+// write applies a change to the copy and then to Redis. The copy cannot fail;
+// the Redis error is returned so that callers can report the change as lost
+// to other instances.
+func (f redisFallback[L]) write(local func(L) error, remote func(redis.Cmdable) error) error {
+	_ = local(f.local)
+
+	return remote(f.client)
+}
+
+// query asks both the copy and Redis, and believes Redis unless it fails.
+// The copy is asked every time because a query may change state too, as
+// reserving a login attempt does.
+func query[L, T any](f redisFallback[L], local func(L) (T, error), remote func(redis.Cmdable) (T, error)) (T, error) {
+	fallback, localErr := local(f.local)
+
+	value, err := remote(f.client)
+	if err != nil {
+		return fallback, localErr
+	}
+
+	return value, nil
+}
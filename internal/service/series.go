@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskflow/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrNotRecurring = errors.New("task is not recurring")
+
+// maxSeriesOccurrences caps how many occurrences a series edit looks at when
+// it carries the change over to the open ones.
+const maxSeriesOccurrences = 100
+
+// SeriesRepository reads and changes existing series; a series is stored
+// together with its first occurrence by TaskRepository.CreateInSeries.
+type SeriesRepository interface {
+	Get(ctx context.Context, id, userID uuid.UUID) (domain.TaskSeries, error)
+	Update(ctx context.Context, series domain.TaskSeries) (domain.TaskSeries, error)
+	Advance(ctx context.Context, series domain.TaskSeries, previousDueAt time.Time) (bool, error)
+}
+
+// SeriesPatch describes a change to a whole series. Nil fields are left
+// untouched.
+type SeriesPatch struct {
+	Title       *string
+	Description *string
+	Priority    *domain.Priority
+	// Rule is a new RRULE, anchored to the newest occurrence.
+	Rule *string
+}
+
+// GetSeries returns the series the task belongs to.
+func (s *TaskService) GetSeries(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+) (domain.TaskSeries, error) {
+	return s.series(ctx, userID, taskID, domain.PermissionViewTasks)
+}
+
+// series loads the series of the task once the caller is found to hold the
+// permission in the task's project.
+func (s *TaskService) series(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	permission domain.Permission,
+) (domain.TaskSeries, error) {
+	task, err := s.readTask(ctx, userID, taskID, permission)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+	if task.SeriesID == nil {
+		return domain.TaskSeries{}, ErrNotRecurring
+	}
+
+	// The series belongs to the creator of its first occurrence.
+	series, err := s.SeriesRepository.Get(ctx, *task.SeriesID, task.UserID)
+	if err != nil {
+		return domain.TaskSeries{}, ErrNotRecurring
+	}
+
+	return series, nil
+}
+
+// UpdateSeries edits the series the task belongs to. Title, description and
+// priority are carried over to every open occurrence as well; editing a single
+// occurrence goes through PatchTask instead.
+func (s *TaskService) UpdateSeries(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	patch SeriesPatch,
+) (domain.TaskSeries, error) {
+	series, err := s.series(ctx, userID, taskID, domain.PermissionEditTasks)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	if patch.Title != nil {
+		if err := series.Rename(*patch.Title); err != nil {
+			return domain.TaskSeries{}, err
+		}
+	}
+
+	if patch.Description != nil {
+		series.ChangeDescription(*patch.Description)
+	}
+
+	if patch.Priority != nil {
+		if err := series.ChangePriority(*patch.Priority); err != nil {
+			return domain.TaskSeries{}, err
+		}
+	}
+
+	if patch.Rule != nil {
+		rule, err := domain.ParseRecurrence(*patch.Rule)
+		if err != nil {
+			return domain.TaskSeries{}, err
+		}
+		if err := series.ChangeRule(rule); err != nil {
+			return domain.TaskSeries{}, err
+		}
+	}
+
+	updated, err := s.SeriesRepository.Update(ctx, series)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	if patch.Title == nil && patch.Description == nil && patch.Priority == nil {
+		return updated, nil
+	}
+
+	occurrences, err := s.TaskRepository.List(ctx, userID, domain.TaskFilter{
+		SeriesID: &updated.ID,
+		Limit:    maxSeriesOccurrences,
+		SortBy:   "due_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.Category.IsClosed() {
+			continue
+		}
+
+		if _, err := s.PatchTask(ctx, userID, occurrence.ID, TaskPatch{
+			Title:       patch.Title,
+			Description: patch.Description,
+			Priority:    patch.Priority,
+		}, nil); err != nil {
+			return domain.TaskSeries{}, err
+		}
+	}
+
+	return updated, nil
+}
+
+// StopSeries ends the recurrence of the task; no further occurrences are
+// created and the existing ones are kept.
+func (s *TaskService) StopSeries(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+) (domain.TaskSeries, error) {
+	series, err := s.series(ctx, userID, taskID, domain.PermissionEditTasks)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	if !series.IsActive() {
+		return series, nil
+	}
+
+	series.Stop(time.Now())
+
+	return s.SeriesRepository.Update(ctx, series)
+}
+
+// newSeries starts a series with task as its first occurrence. The series is
+// stored together with the task.
+func (s *TaskService) newSeries(task *domain.Task, rrule string) (domain.TaskSeries, error) {
+	rule, err := domain.ParseRecurrence(rrule)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	series, err := domain.NewTaskSeries(*task, rule)
+	if err != nil {
+		return domain.TaskSeries{}, err
+	}
+
+	task.SeriesID = &series.ID
+	return series, nil
+}
+
+// scheduleNextOccurrence creates the occurrence that follows a completed
+// recurring task. The status change has been stored already, so failures here
+// do not fail it; they are logged instead.
+func (s *TaskService) scheduleNextOccurrence(ctx context.Context, completed domain.Task) {
+	if err := s.createNextOccurrence(ctx, completed); err != nil {
+		s.logger.ErrorContext(ctx, "failed to schedule next occurrence",
+			"task_id", completed.ID, "series_id", completed.SeriesID, "error", err)
+	}
+}
+
+func (s *TaskService) createNextOccurrence(ctx context.Context, completed domain.Task) error {
+	if completed.SeriesID == nil || !completed.IsDone() {
+		return nil
+	}
+
+	series, err := s.SeriesRepository.Get(ctx, *completed.SeriesID, completed.UserID)
+	if err != nil {
+		return err
+	}
+
+	previousDueAt := series.LastDueAt
+
+	next, ok, err := series.NextOccurrence(completed)
+	if err != nil || !ok {
+		return err
+	}
+
+	workflow, err := s.taskWorkflow(ctx, completed)
+	if err != nil {
+		return err
+	}
+	next.EnterWorkflow(workflow)
+
+	advanced, err := s.SeriesRepository.Advance(ctx, series, previousDueAt)
+	if err != nil || !advanced {
+		return err
+	}
+
+	created, err := s.TaskRepository.Create(ctx, next)
+	if err != nil {
+		return err
+	}
+
+	s.cacheTask(ctx, created)
+
+	_ = s.Analytics.PublishTaskEvent(ctx, TaskEvent{
+		Type:      TaskEventCreated,
+		UserID:    created.UserID,
+		TaskID:    created.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	return nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher keeps the events it is given. The mocks package cannot
+// provide one, as AnalyticsPublisher takes a service type.
+type recordingPublisher struct {
+	events []TaskEvent
+}
+
+func (p *recordingPublisher) PublishTaskEvent(_ context.Context, event TaskEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func TestTaskServiceCreateTaskStartsSeries(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	series := mocks.NewSeriesRepository(t)
+	svc := NewTaskService(repo, nil, nil, series, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	dueAt := mockTime()
+
+	var seriesID uuid.UUID
+	repo.
+		On("CreateInSeries", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.SeriesID != nil
+		}), mock.MatchedBy(func(s domain.TaskSeries) bool {
+			seriesID = s.ID
+			return s.UserID == userID &&
+				s.Rule.String() == "FREQ=WEEKLY;BYDAY=MO" &&
+				s.Title == "Take out the trash" &&
+				s.StartsAt.Equal(dueAt) &&
+				s.LastDueAt.Equal(dueAt)
+		})).
+		Return(func(_ context.Context, task domain.Task, _ domain.TaskSeries) (domain.Task, error) {
+			return task, nil
+		}).
+		Once()
+
+	task, err := svc.CreateTask(ctx, userID, CreateTaskInput{
+		Title:      "Take out the trash",
+		DueAt:      &dueAt,
+		Recurrence: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, &seriesID, task.SeriesID)
+	repo.AssertExpectations(t)
+	series.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceCreateTaskRejectsInvalidRecurrence(t *testing.T) {
+	t.Parallel()
+
+	dueAt := mockTime()
+
+	tests := []struct {
+		name  string
+		input CreateTaskInput
+		err   error
+	}{
+		{
+			name:  "missing due date",
+			input: CreateTaskInput{Title: "Task", Recurrence: "FREQ=DAILY"},
+			err:   domain.ErrRecurrenceWithoutDueDate,
+		},
+		{
+			name:  "unsupported frequency",
+			input: CreateTaskInput{Title: "Task", DueAt: &dueAt, Recurrence: "FREQ=HOURLY"},
+			err:   domain.ErrInvalidRecurrence,
+		},
+		{
+			name:  "unsupported part",
+			input: CreateTaskInput{Title: "Task", DueAt: &dueAt, Recurrence: "FREQ=MONTHLY;BYSETPOS=-1"},
+			err:   domain.ErrInvalidRecurrence,
+		},
+		{
+			name:  "count and until",
+			input: CreateTaskInput{Title: "Task", DueAt: &dueAt, Recurrence: "FREQ=DAILY;COUNT=3;UNTIL=20260301"},
+			err:   domain.ErrInvalidRecurrence,
+		},
+		{
+			name:  "byday outside weekly rule",
+			input: CreateTaskInput{Title: "Task", DueAt: &dueAt, Recurrence: "FREQ=MONTHLY;BYDAY=MO"},
+			err:   domain.ErrInvalidRecurrence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			series := mocks.NewSeriesRepository(t)
+			svc := NewTaskService(repo, nil, nil, series, nil, nil, nil, nil)
+
+			_, err := svc.CreateTask(context.Background(), uuid.New(), tt.input)
+
+			require.ErrorIs(t, err, tt.err)
+			series.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "CreateInSeries", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTaskServiceChangeStatusSchedulesNextOccurrence(t *testing.T) {
+	t.Parallel()
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		startsAt time.Time
+		dueAt    time.Time
+		// next is the zero time when the series has ended.
+		next time.Time
+	}{
+		{
+			name:     "weekly on listed days",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,TH",
+			startsAt: at(2026, time.January, 5),
+			dueAt:    at(2026, time.January, 8),
+			next:     at(2026, time.January, 12),
+		},
+		{
+			name:     "every other week",
+			rule:     "FREQ=WEEKLY;INTERVAL=2",
+			startsAt: at(2026, time.January, 5),
+			dueAt:    at(2026, time.January, 5),
+			next:     at(2026, time.January, 19),
+		},
+		{
+			name:     "monthly skips short months",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=31",
+			startsAt: at(2026, time.January, 31),
+			dueAt:    at(2026, time.January, 31),
+			next:     at(2026, time.March, 31),
+		},
+		{
+			name:     "monthly on the last day",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			startsAt: at(2026, time.January, 31),
+			dueAt:    at(2026, time.January, 31),
+			next:     at(2026, time.February, 28),
+		},
+		{
+			name:     "yearly on leap day",
+			rule:     "FREQ=YEARLY",
+			startsAt: at(2024, time.February, 29),
+			dueAt:    at(2024, time.February, 29),
+			next:     at(2028, time.February, 29),
+		},
+		{
+			name:     "count exhausted",
+			rule:     "FREQ=DAILY;COUNT=2",
+			startsAt: at(2026, time.January, 5),
+			dueAt:    at(2026, time.January, 6),
+		},
+		{
+			name:     "until passed",
+			rule:     "FREQ=DAILY;INTERVAL=3;UNTIL=20260107T000000Z",
+			startsAt: at(2026, time.January, 5),
+			dueAt:    at(2026, time.January, 5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			seriesRepo := mocks.NewSeriesRepository(t)
+			analytics := &recordingPublisher{}
+			svc := NewTaskService(repo, nil, nil, seriesRepo, nil, nil, analytics, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+			taskID := uuid.New()
+
+			rule, err := domain.ParseRecurrence(tt.rule)
+			require.NoError(t, err)
+
+			series := domain.TaskSeries{
+				ID:        uuid.New(),
+				UserID:    userID,
+				Rule:      rule,
+				Title:     "Chore",
+				Priority:  domain.PriorityHigh,
+				StartsAt:  tt.startsAt,
+				LastDueAt: tt.dueAt,
+			}
+			task := domain.Task{
+				ID:        taskID,
+				UserID:    userID,
+				Title:     "Chore",
+				Status:    domain.StatusPending,
+				DueAt:     &tt.dueAt,
+				SeriesID:  &series.ID,
+				CreatedAt: mockTime(),
+			}
+			completed := task
+			completed.Status = domain.StatusDone
+			completed.Category = domain.CategoryDone
+
+			repo.
+				On("Get", ctx, taskID).
+				Return(task, nil).
+				Once()
+			repo.
+				On("Update", ctx, mock.Anything).
+				Return(completed, nil).
+				Once()
+			repo.
+				On("TouchDependents", ctx, []uuid.UUID{taskID}).
+				Return(map[uuid.UUID]int64{}, nil).
+				Once()
+			seriesRepo.
+				On("Get", ctx, series.ID, userID).
+				Return(series, nil).
+				Once()
+
+			if !tt.next.IsZero() {
+				nextID := uuid.New()
+
+				seriesRepo.
+					On("Advance", ctx, mock.MatchedBy(func(s domain.TaskSeries) bool {
+						return s.LastDueAt.Equal(tt.next)
+					}), tt.dueAt).
+					Return(true, nil).
+					Once()
+				repo.
+					On("Create", ctx, mock.MatchedBy(func(next domain.Task) bool {
+						return next.DueAt != nil && next.DueAt.Equal(tt.next) &&
+							next.SeriesID != nil && *next.SeriesID == series.ID &&
+							next.Priority == domain.PriorityHigh &&
+							next.Status == domain.StatusPending
+					})).
+					Return(domain.Task{ID: nextID, UserID: userID}, nil).
+					Once()
+				t.Cleanup(func() {
+					require.Len(t, analytics.events, 1)
+					require.Equal(t, TaskEventCreated, analytics.events[0].Type)
+					require.Equal(t, nextID, analytics.events[0].TaskID)
+				})
+			}
+
+			_, err = svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, nil)
+
+			require.NoError(t, err)
+			repo.AssertExpectations(t)
+			seriesRepo.AssertExpectations(t)
+			if tt.next.IsZero() {
+				require.Empty(t, analytics.events)
+				seriesRepo.AssertNotCalled(t, "Advance", mock.Anything, mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTaskServiceChangeStatusSkipsOccurrenceWhenSeriesAdvancedConcurrently(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	seriesRepo := mocks.NewSeriesRepository(t)
+	svc := NewTaskService(repo, nil, nil, seriesRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	dueAt := mockTime()
+
+	rule, err := domain.ParseRecurrence("FREQ=DAILY")
+	require.NoError(t, err)
+
+	series := domain.TaskSeries{ID: uuid.New(), UserID: userID, Rule: rule, Title: "Chore", Priority: domain.PriorityMedium, StartsAt: dueAt, LastDueAt: dueAt}
+	task := domain.Task{ID: taskID, UserID: userID, Title: "Chore", Status: domain.StatusPending, DueAt: &dueAt, SeriesID: &series.ID}
+	completed := task
+	completed.Status = domain.StatusDone
+	completed.Category = domain.CategoryDone
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.Anything).
+		Return(completed, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{taskID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+	seriesRepo.
+		On("Get", ctx, series.ID, userID).
+		Return(series, nil).
+		Once()
+	seriesRepo.
+		On("Advance", ctx, mock.Anything, dueAt).
+		Return(false, nil).
+		Once()
+
+	_, err = svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, nil)
+
+	require.NoError(t, err)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+	seriesRepo.AssertExpectations(t)
+}
+
+func TestTaskServiceUpdateSeriesCarriesChangesToOpenOccurrences(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	seriesRepo := mocks.NewSeriesRepository(t)
+	svc := NewTaskService(repo, nil, nil, seriesRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	dueAt := mockTime()
+	title := "Water the plants"
+
+	rule, err := domain.ParseRecurrence("FREQ=WEEKLY")
+	require.NoError(t, err)
+
+	series := domain.TaskSeries{ID: uuid.New(), UserID: userID, Rule: rule, Title: "Water plants", Priority: domain.PriorityMedium, StartsAt: dueAt, LastDueAt: dueAt}
+	open := domain.Task{ID: uuid.New(), UserID: userID, Title: "Water plants", Status: domain.StatusPending, DueAt: &dueAt, SeriesID: &series.ID}
+	done := domain.Task{ID: uuid.New(), UserID: userID, Title: "Water plants", Status: domain.StatusDone, Category: domain.CategoryDone, SeriesID: &series.ID}
+
+	repo.
+		On("Get", ctx, open.ID).
+		Return(open, nil).
+		Twice()
+	seriesRepo.
+		On("Get", ctx, series.ID, userID).
+		Return(series, nil).
+		Once()
+	seriesRepo.
+		On("Update", ctx, mock.MatchedBy(func(s domain.TaskSeries) bool {
+			return s.Title == title
+		})).
+		Return(domain.TaskSeries{ID: series.ID, UserID: userID, Title: title}, nil).
+		Once()
+	repo.
+		On("List", ctx, userID, mock.MatchedBy(func(filter domain.TaskFilter) bool {
+			return filter.SeriesID != nil && *filter.SeriesID == series.ID
+		})).
+		Return([]domain.Task{open, done}, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.ID == open.ID && task.Title == title
+		})).
+		Return(open, nil).
+		Once()
+
+	updated, err := svc.UpdateSeries(ctx, userID, open.ID, SeriesPatch{Title: &title})
+
+	require.NoError(t, err)
+	require.Equal(t, title, updated.Title)
+	repo.AssertExpectations(t)
+	seriesRepo.AssertExpectations(t)
+}
+
+func TestTaskServiceStopSeriesStopsRecurrence(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	seriesRepo := mocks.NewSeriesRepository(t)
+	svc := NewTaskService(repo, nil, nil, seriesRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	seriesID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Chore", Status: domain.StatusPending, SeriesID: &seriesID}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	seriesRepo.
+		On("Get", ctx, seriesID, userID).
+		Return(domain.TaskSeries{ID: seriesID, UserID: userID, Title: "Chore"}, nil).
+		Once()
+	seriesRepo.
+		On("Update", ctx, mock.MatchedBy(func(s domain.TaskSeries) bool {
+			return !s.IsActive()
+		})).
+		Return(domain.TaskSeries{ID: seriesID, UserID: userID, StoppedAt: new(time.Time)}, nil).
+		Once()
+
+	series, err := svc.StopSeries(ctx, userID, task.ID)
+
+	require.NoError(t, err)
+	require.False(t, series.IsActive())
+	seriesRepo.AssertExpectations(t)
+}
+
+func TestTaskServiceStopSeriesRejectsSingleTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+
+	_, err := svc.StopSeries(ctx, userID, taskID)
+
+	require.ErrorIs(t, err, ErrNotRecurring)
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"taskflow/internal/domain"
+	"taskflow/internal/lib/logger/logger"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,18 +16,48 @@ import (
 )
 
 var (
-	ErrTaskNotFound = errors.New("task not found")
-	ErrForbidden    = errors.New("forbidden")
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrLabelNotFound      = errors.New("label not found")
+	ErrForbidden          = errors.New("forbidden")
+	ErrPreconditionFailed = errors.New("task version does not match")
+	ErrParentNotFound     = errors.New("parent task not found")
+	ErrBlockerNotFound    = errors.New("blocking task not found")
 )
 
-const taskCacheTTL = 5 * time.Minute
+const (
+	taskCacheTTL = 5 * time.Minute
+	// taskVersionCacheTTL outlives cached payloads so a payload written back by
+	// a slow reader is still checked against the newest known version.
+	taskVersionCacheTTL = 2 * taskCacheTTL
+	// deletedTaskVersion marks a deleted task so no payload is trusted again.
+	deletedTaskVersion int64 = math.MaxInt64
+)
 
 type TaskRepository interface {
 	Create(ctx context.Context, task domain.Task) (domain.Task, error)
-	Get(ctx context.Context, id, userID uuid.UUID) (domain.Task, error)
+	// CreateInSeries stores the series and the task, its first occurrence, in
+	// one transaction.
+	CreateInSeries(ctx context.Context, task domain.Task, series domain.TaskSeries) (domain.Task, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Task, error)
+	// List returns the tasks of the projects the user is a member of.
 	List(ctx context.Context, userID uuid.UUID, filter domain.TaskFilter) ([]domain.Task, error)
 	Update(ctx context.Context, task domain.Task) (domain.Task, error)
-	Delete(ctx context.Context, id, userID uuid.UUID) error
+	// Delete fails with domain.ErrVersionConflict when the task no longer has
+	// the version it was loaded with.
+	Delete(ctx context.Context, task domain.Task) (map[uuid.UUID]int64, error)
+	AttachLabel(ctx context.Context, task domain.Task, labelID uuid.UUID) (domain.Task, error)
+	DetachLabel(ctx context.Context, task domain.Task, labelID uuid.UUID) (domain.Task, error)
+	Touch(ctx context.Context, id uuid.UUID) (domain.Task, error)
+	Ancestors(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	Descendants(ctx context.Context, id uuid.UUID) (map[uuid.UUID]int, error)
+	// CompleteWithSubtasks stores the task and completes its open subtasks in
+	// one transaction.
+	CompleteWithSubtasks(ctx context.Context, task domain.Task, now time.Time) (domain.Task, map[uuid.UUID]int64, error)
+	AddBlocker(ctx context.Context, task domain.Task, blockerID uuid.UUID) (domain.Task, error)
+	RemoveBlocker(ctx context.Context, task domain.Task, blockerID uuid.UUID) (domain.Task, error)
+	TransitiveBlockers(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	TouchDependents(ctx context.Context, blockerIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	SaveChecklist(ctx context.Context, task domain.Task) (domain.Task, error)
 }
 
 type TaskCache interface {
@@ -33,13 +66,56 @@ type TaskCache interface {
 	Delete(ctx context.Context, key string) error
 }
 
+// CreateTaskInput carries the fields a client may set when creating a task.
+type CreateTaskInput struct {
+	Title       string
+	Description string
+	// Priority defaults to medium when nil.
+	Priority *domain.Priority
+	DueAt    *time.Time
+	// ProjectID defaults to the project of the parent task, or to the user's
+	// personal project.
+	ProjectID *uuid.UUID
+	// ParentID makes the new task a subtask of another task of the project.
+	ParentID *uuid.UUID
+	// Recurrence is an RRULE that makes the task the first occurrence of a
+	// series; it needs DueAt.
+	Recurrence string
+}
+
+// maxSubtasks caps how many subtasks ListSubtasks returns.
+const maxSubtasks = 100
+
+// TaskPatch describes a partial task update. Nil fields are left untouched.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	Status      *domain.Status
+	Priority    *domain.Priority
+	DueAt       *time.Time
+	// ClearDueAt removes the due date; it wins over DueAt.
+	ClearDueAt bool
+}
+
 type redisTaskCache struct {
 	client redis.Cmdable
 }
 
 type TaskService struct {
-	TaskRepository TaskRepository
-	TaskCache      TaskCache
+	TaskRepository   TaskRepository
+	TaskCache        TaskCache
+	LabelRepository  LabelRepository
+	SeriesRepository SeriesRepository
+	// WorkflowRepository may be nil, in which case every user gets the default
+	// workflow.
+	WorkflowRepository WorkflowRepository
+	// ProjectRepository may be nil, in which case every task stays private to
+	// its creator.
+	ProjectRepository ProjectRepository
+	Analytics         AnalyticsPublisher
+	// logger reports failures of side effects that do not fail the request,
+	// such as scheduling the next occurrence of a series.
+	logger logger.Logger
 }
 
 func NewRedisTaskCache(client redis.Cmdable) TaskCache {
@@ -62,29 +138,89 @@ func (c *redisTaskCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
 
-func NewTaskService(repository TaskRepository, cache TaskCache) *TaskService {
+func NewTaskService(
+	repository TaskRepository,
+	cache TaskCache,
+	labels LabelRepository,
+	series SeriesRepository,
+	workflows WorkflowRepository,
+	projects ProjectRepository,
+	analytics AnalyticsPublisher,
+	log logger.Logger,
+) *TaskService {
+	if analytics == nil {
+		analytics = NewNoopAnalyticsPublisher()
+	}
+	if log == nil {
+		log = logger.NewSlogLogger()
+	}
+
 	return &TaskService{
-		TaskRepository: repository,
-		TaskCache:      cache,
+		TaskRepository:     repository,
+		TaskCache:          cache,
+		LabelRepository:    labels,
+		SeriesRepository:   series,
+		WorkflowRepository: workflows,
+		ProjectRepository:  projects,
+		Analytics:          analytics,
+		logger:             log,
 	}
 }
 
 func (s *TaskService) CreateTask(
 	ctx context.Context,
 	userID uuid.UUID,
-	title, description string,
+	input CreateTaskInput,
 ) (domain.Task, error) {
-	task, err := domain.NewTask(userID, title, description)
+	task, err := domain.NewTask(userID, input.Title, input.Description)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	project, err := s.projectFor(ctx, userID, input)
 	if err != nil {
 		return domain.Task{}, err
 	}
+	task.ProjectID = project.ID
 
-	createdTask, err := s.TaskRepository.Create(ctx, task)
+	workflow, err := s.workflow(ctx, project.OwnerID)
 	if err != nil {
 		return domain.Task{}, err
 	}
+	task.EnterWorkflow(workflow)
+
+	if input.Priority != nil {
+		if err := task.ChangePriority(*input.Priority); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	task.Reschedule(input.DueAt)
+
+	if input.ParentID != nil {
+		if err := s.placeUnder(ctx, userID, &task, *input.ParentID, 0); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	var createdTask domain.Task
+	if input.Recurrence != "" {
+		series, err := s.newSeries(&task, input.Recurrence)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		createdTask, err = s.TaskRepository.CreateInSeries(ctx, task, series)
+		if err != nil {
+			return domain.Task{}, err
+		}
+	} else {
+		createdTask, err = s.TaskRepository.Create(ctx, task)
+		if err != nil {
+			return domain.Task{}, err
+		}
+	}
 
 	s.cacheTask(ctx, createdTask)
+	s.touchParent(ctx, createdTask.ParentID)
 
 	return createdTask, nil
 }
@@ -93,43 +229,247 @@ func (s *TaskService) GetTask(
 	ctx context.Context,
 	userID, taskID uuid.UUID,
 ) (domain.Task, error) {
-	if task, ok := s.getCachedTask(ctx, userID, taskID); ok {
+	return s.readTask(ctx, userID, taskID, domain.PermissionViewTasks)
+}
+
+// readTask loads the task through the cache and checks that the caller holds
+// the permission in the task's project.
+func (s *TaskService) readTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	permission domain.Permission,
+) (domain.Task, error) {
+	if task, ok := s.getCachedTask(ctx, taskID); ok {
+		if err := s.authorize(ctx, userID, task, permission); err != nil {
+			return domain.Task{}, err
+		}
 		return task, nil
 	}
 
-	task, err := s.TaskRepository.Get(ctx, taskID, userID)
+	task, err := s.TaskRepository.Get(ctx, taskID)
 	if err != nil {
 		return domain.Task{}, ErrTaskNotFound
 	}
 
-	s.cacheTask(ctx, task)
+	s.fillTaskCache(ctx, task)
+
+	if err := s.authorize(ctx, userID, task, permission); err != nil {
+		return domain.Task{}, err
+	}
 
 	return task, nil
 }
 
+// ChangeStatus moves the task to a new status. When expectedVersion is set the
+// change is applied only if the stored task still has that version.
 func (s *TaskService) ChangeStatus(
 	ctx context.Context,
 	userID, taskID uuid.UUID,
 	status domain.Status,
-) error {
+	expectedVersion *int64,
+) (domain.Task, error) {
 
-	task, err := s.TaskRepository.Get(ctx, taskID, userID)
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
 	if err != nil {
-		return ErrTaskNotFound
+		return domain.Task{}, err
 	}
 
-	if err := task.ChangeStatus(status, time.Now()); err != nil {
-		return err
+	workflow, err := s.taskWorkflow(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	previous := task
+	if err := task.ChangeStatus(workflow, status, userID, time.Now()); err != nil {
+		return domain.Task{}, err
 	}
 
 	updatedTask, err := s.TaskRepository.Update(ctx, task)
 	if err != nil {
-		return err
+		return domain.Task{}, err
 	}
 
 	s.cacheTask(ctx, updatedTask)
+	s.statusChanged(ctx, updatedTask)
+	s.publishReopened(ctx, userID, previous, updatedTask)
 
-	return err
+	return updatedTask, nil
+}
+
+// ReopenTask moves a done task back to an open status, the initial status of
+// the project's workflow when status is empty.
+func (s *TaskService) ReopenTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	status domain.Status,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.takeBack(ctx, userID, taskID, expectedVersion, func(task *domain.Task, workflow domain.Workflow) error {
+		return task.Reopen(workflow, status)
+	})
+}
+
+// RestoreTask moves a canceled task back to the initial status of the
+// project's workflow.
+func (s *TaskService) RestoreTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.takeBack(ctx, userID, taskID, expectedVersion, func(task *domain.Task, workflow domain.Workflow) error {
+		return task.Restore(workflow)
+	})
+}
+
+func (s *TaskService) takeBack(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	expectedVersion *int64,
+	change func(task *domain.Task, workflow domain.Workflow) error,
+) (domain.Task, error) {
+
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	workflow, err := s.taskWorkflow(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	previous := task
+	if err := change(&task, workflow); err != nil {
+		return domain.Task{}, err
+	}
+
+	updatedTask, err := s.TaskRepository.Update(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+	s.statusChanged(ctx, updatedTask)
+	s.publishReopened(ctx, userID, previous, updatedTask)
+
+	return updatedTask, nil
+}
+
+// CompleteWithSubtasks moves the task to status and, when that is a done
+// status of the project's workflow, completes every open task below it with the
+// same status. It returns the IDs of the subtasks it completed.
+func (s *TaskService) CompleteWithSubtasks(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	status domain.Status,
+	expectedVersion *int64,
+) (domain.Task, []uuid.UUID, error) {
+
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, nil, err
+	}
+
+	workflow, err := s.taskWorkflow(ctx, task)
+	if err != nil {
+		return domain.Task{}, nil, err
+	}
+
+	target, ok := workflow.Status(status)
+	if !ok {
+		return domain.Task{}, nil, domain.ErrInvalidStatus
+	}
+	if target.Category != domain.CategoryDone {
+		task, err := s.ChangeStatus(ctx, userID, taskID, status, expectedVersion)
+		return task, nil, err
+	}
+
+	now := time.Now()
+
+	// Validate the parent's own transition before any subtask is touched.
+	task.MarkSubtasksDone()
+	if err := task.ChangeStatus(workflow, target.Key, userID, now); err != nil {
+		return domain.Task{}, nil, err
+	}
+
+	updatedTask, touched, err := s.TaskRepository.CompleteWithSubtasks(ctx, task, now)
+	if err != nil {
+		return domain.Task{}, nil, err
+	}
+
+	completed := make([]uuid.UUID, 0, len(touched))
+	for id, version := range touched {
+		s.invalidateCachedTask(ctx, id, version)
+		completed = append(completed, id)
+	}
+
+	s.cacheTask(ctx, updatedTask)
+	s.statusChanged(ctx, updatedTask, completed...)
+
+	return updatedTask, completed, nil
+}
+
+// MoveTask makes the task a subtask of parentID, or a top level task when
+// parentID is nil.
+func (s *TaskService) MoveTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	parentID *uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	previousParentID := task.ParentID
+
+	if parentID == nil {
+		task.MoveToRoot()
+	} else {
+		descendants, err := s.TaskRepository.Descendants(ctx, task.ID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+
+		height := 0
+		for _, depth := range descendants {
+			height = max(height, depth)
+		}
+
+		if err := s.placeUnder(ctx, userID, &task, *parentID, height); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	updatedTask, err := s.TaskRepository.Update(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+	s.touchParent(ctx, previousParentID)
+	s.touchParent(ctx, updatedTask.ParentID)
+
+	return updatedTask, nil
+}
+
+// ListSubtasks returns the direct subtasks of a task, oldest first.
+func (s *TaskService) ListSubtasks(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+) ([]domain.Task, error) {
+	if _, err := s.GetTask(ctx, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.TaskRepository.List(ctx, userID, domain.TaskFilter{
+		ParentID: &taskID,
+		Limit:    maxSubtasks,
+		SortBy:   "created_at",
+		SortDir:  "asc",
+	})
 }
 
 func (s *TaskService) UpdateTask(
@@ -137,20 +477,63 @@ func (s *TaskService) UpdateTask(
 	userID, taskID uuid.UUID,
 	title, description *string,
 ) (domain.Task, error) {
+	return s.PatchTask(ctx, userID, taskID, TaskPatch{
+		Title:       title,
+		Description: description,
+	}, nil)
+}
+
+// PatchTask applies every field of the patch to the stored task through the
+// domain methods and persists the result with a single repository update, so
+// either all changes are written or none of them are.
+func (s *TaskService) PatchTask(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	patch TaskPatch,
+	expectedVersion *int64,
+) (domain.Task, error) {
 
-	task, err := s.TaskRepository.Get(ctx, taskID, userID)
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
 	if err != nil {
-		return domain.Task{}, ErrTaskNotFound
+		return domain.Task{}, err
+	}
+
+	if patch.Title != nil {
+		if err := task.Rename(*patch.Title); err != nil {
+			return domain.Task{}, err
+		}
 	}
 
-	if title != nil {
-		if err := task.Rename(*title); err != nil {
+	if patch.Description != nil {
+		task.ChangeDescription(*patch.Description)
+	}
+
+	if patch.Priority != nil {
+		if err := task.ChangePriority(*patch.Priority); err != nil {
 			return domain.Task{}, err
 		}
 	}
 
-	if description != nil {
-		task.ChangeDescription(*description)
+	if patch.ClearDueAt {
+		task.Reschedule(nil)
+	} else if patch.DueAt != nil {
+		task.Reschedule(patch.DueAt)
+	}
+
+	statusChanged := false
+	previous := task
+	if patch.Status != nil {
+		status := domain.NormalizeStatus(*patch.Status)
+		if status != task.Status {
+			workflow, err := s.taskWorkflow(ctx, task)
+			if err != nil {
+				return domain.Task{}, err
+			}
+			if err := task.ChangeStatus(workflow, status, userID, time.Now()); err != nil {
+				return domain.Task{}, err
+			}
+			statusChanged = true
+		}
 	}
 
 	updatedTask, err := s.TaskRepository.Update(ctx, task)
@@ -158,6 +541,122 @@ func (s *TaskService) UpdateTask(
 		return domain.Task{}, err
 	}
 
+	s.cacheTask(ctx, updatedTask)
+	if statusChanged {
+		s.statusChanged(ctx, updatedTask)
+		s.publishReopened(ctx, userID, previous, updatedTask)
+	}
+
+	return updatedTask, nil
+}
+
+// AttachLabel puts one of the user's labels on the task.
+func (s *TaskService) AttachLabel(
+	ctx context.Context,
+	userID, taskID, labelID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeLabels(ctx, userID, taskID, labelID, expectedVersion, s.TaskRepository.AttachLabel)
+}
+
+// DetachLabel takes a label off the task.
+func (s *TaskService) DetachLabel(
+	ctx context.Context,
+	userID, taskID, labelID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	return s.changeLabels(ctx, userID, taskID, labelID, expectedVersion, s.TaskRepository.DetachLabel)
+}
+
+// AddBlocker makes the task wait for another task of the same project.
+// Dependencies that would close a cycle are rejected.
+func (s *TaskService) AddBlocker(
+	ctx context.Context,
+	userID, taskID, blockerID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	if blockerID == taskID {
+		return domain.Task{}, domain.ErrDependencyCycle
+	}
+
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	blocker, err := s.TaskRepository.Get(ctx, blockerID)
+	if err != nil {
+		return domain.Task{}, ErrBlockerNotFound
+	}
+	if err := s.authorize(ctx, userID, blocker, domain.PermissionViewTasks); err != nil {
+		return domain.Task{}, ErrBlockerNotFound
+	}
+	if blocker.ProjectID != task.ProjectID {
+		return domain.Task{}, domain.ErrProjectMismatch
+	}
+
+	// The new edge closes a cycle when the blocker already waits for the task.
+	upstream, err := s.TaskRepository.TransitiveBlockers(ctx, blockerID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	for _, id := range upstream {
+		if id == taskID {
+			return domain.Task{}, domain.ErrDependencyCycle
+		}
+	}
+
+	updatedTask, err := s.TaskRepository.AddBlocker(ctx, task, blockerID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+
+	return updatedTask, nil
+}
+
+// RemoveBlocker drops a dependency of the task.
+func (s *TaskService) RemoveBlocker(
+	ctx context.Context,
+	userID, taskID, blockerID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	updatedTask, err := s.TaskRepository.RemoveBlocker(ctx, task, blockerID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	s.cacheTask(ctx, updatedTask)
+
+	return updatedTask, nil
+}
+
+func (s *TaskService) changeLabels(
+	ctx context.Context,
+	userID, taskID, labelID uuid.UUID,
+	expectedVersion *int64,
+	change func(ctx context.Context, task domain.Task, labelID uuid.UUID) (domain.Task, error),
+) (domain.Task, error) {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if _, err := s.LabelRepository.Get(ctx, labelID, userID); err != nil {
+		return domain.Task{}, ErrLabelNotFound
+	}
+
+	updatedTask, err := change(ctx, task, labelID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
 	s.cacheTask(ctx, updatedTask)
 
 	return updatedTask, nil
@@ -166,15 +665,37 @@ func (s *TaskService) UpdateTask(
 func (s *TaskService) DeleteTask(
 	ctx context.Context,
 	userID, taskID uuid.UUID,
+	expectedVersion *int64,
 ) error {
-	if err := s.TaskRepository.Delete(ctx, taskID, userID); err != nil {
+	task, err := s.getForWrite(ctx, userID, taskID, expectedVersion)
+	if err != nil {
 		return err
 	}
 
-	s.deleteCachedTask(ctx, userID, taskID)
+	// Subtasks are removed together with the task.
+	descendants, err := s.TaskRepository.Descendants(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	// Tasks blocked by the deleted ones lose those blockers.
+	dependents, err := s.TaskRepository.Delete(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	s.deleteCachedTask(ctx, taskID)
+	for id := range descendants {
+		s.deleteCachedTask(ctx, id)
+	}
+	for id, version := range dependents {
+		s.invalidateCachedTask(ctx, id, version)
+	}
+	s.touchParent(ctx, task.ParentID)
 
 	return nil
 }
+
 func (s *TaskService) ListTasks(
 	ctx context.Context,
 	userID uuid.UUID,
@@ -183,16 +704,126 @@ func (s *TaskService) ListTasks(
 	return s.TaskRepository.List(ctx, userID, filter)
 }
 
-func (s *TaskService) taskCacheKey(userID, taskID uuid.UUID) string {
-	return fmt.Sprintf("task:%s:%s", userID.String(), taskID.String())
+// getForWrite loads the task straight from storage, checks that the caller
+// may edit it and checks the caller's precondition against the stored
+// version.
+func (s *TaskService) getForWrite(
+	ctx context.Context,
+	userID, taskID uuid.UUID,
+	expectedVersion *int64,
+) (domain.Task, error) {
+	task, err := s.TaskRepository.Get(ctx, taskID)
+	if err != nil {
+		return domain.Task{}, ErrTaskNotFound
+	}
+
+	if err := s.authorize(ctx, userID, task, domain.PermissionEditTasks); err != nil {
+		return domain.Task{}, err
+	}
+
+	if expectedVersion != nil && *expectedVersion != task.Version {
+		return domain.Task{}, ErrPreconditionFailed
+	}
+
+	return task, nil
 }
 
-func (s *TaskService) getCachedTask(ctx context.Context, userID, taskID uuid.UUID) (domain.Task, bool) {
+// placeUnder checks that the task, together with height levels of subtasks
+// below it, may become a subtask of parentID.
+func (s *TaskService) placeUnder(ctx context.Context, userID uuid.UUID, task *domain.Task, parentID uuid.UUID, height int) error {
+	parent, err := s.TaskRepository.Get(ctx, parentID)
+	if err != nil {
+		return ErrParentNotFound
+	}
+	if err := s.authorize(ctx, userID, parent, domain.PermissionViewTasks); err != nil {
+		return ErrParentNotFound
+	}
+
+	ancestors, err := s.TaskRepository.Ancestors(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	return task.MoveUnder(parent, ancestors, height)
+}
+
+// touchParent bumps the version of a task whose subtask progress has changed
+// and refreshes its cached copy.
+func (s *TaskService) touchParent(ctx context.Context, parentID *uuid.UUID) {
+	if parentID == nil {
+		return
+	}
+
+	parent, err := s.TaskRepository.Touch(ctx, *parentID)
+	if err != nil {
+		return
+	}
+
+	s.cacheTask(ctx, parent)
+}
+
+// statusChanged runs the side effects of a status change: it refreshes the
+// tasks whose representation is derived from the status of the given ones,
+// their parents and the tasks they block, and continues a recurring series.
+func (s *TaskService) statusChanged(ctx context.Context, task domain.Task, subtaskIDs ...uuid.UUID) {
+	s.touchParent(ctx, task.ParentID)
+	s.touchDependents(ctx, append(subtaskIDs, task.ID))
+	s.scheduleNextOccurrence(ctx, task)
+}
+
+// publishReopened takes back the completion counted for a task that has left
+// a done status from the user who completed it, since that is whose
+// task_completed counted it. Tasks completed before the completer was recorded
+// fall back to userID, the user who reopened it. The handlers never see the
+// earlier status, so the callers pass the task from before their change.
+func (s *TaskService) publishReopened(ctx context.Context, userID uuid.UUID, previous, task domain.Task) {
+	if !previous.IsDone() || task.IsDone() {
+		return
+	}
+
+	completedBy := userID
+	if previous.CompletedBy != nil {
+		completedBy = *previous.CompletedBy
+	}
+
+	_ = s.Analytics.PublishTaskEvent(ctx, TaskEvent{
+		Type:       TaskEventReopened,
+		UserID:     completedBy,
+		TaskID:     task.ID,
+		AssigneeID: task.AssigneeID,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// touchDependents bumps the versions of the tasks blocked by blockerIDs and
+// drops their cached copies.
+func (s *TaskService) touchDependents(ctx context.Context, blockerIDs []uuid.UUID) {
+	touched, err := s.TaskRepository.TouchDependents(ctx, blockerIDs)
+	if err != nil {
+		return
+	}
+
+	for id, version := range touched {
+		s.invalidateCachedTask(ctx, id, version)
+	}
+}
+
+// taskCacheKey is shared by every project member; access is checked on each
+// read.
+func (s *TaskService) taskCacheKey(taskID uuid.UUID) string {
+	return fmt.Sprintf("task:%s", taskID.String())
+}
+
+func (s *TaskService) taskVersionCacheKey(taskID uuid.UUID) string {
+	return s.taskCacheKey(taskID) + ":version"
+}
+
+func (s *TaskService) getCachedTask(ctx context.Context, taskID uuid.UUID) (domain.Task, bool) {
 	if s.TaskCache == nil {
 		return domain.Task{}, false
 	}
 
-	payload, err := s.TaskCache.Get(ctx, s.taskCacheKey(userID, taskID))
+	payload, err := s.TaskCache.Get(ctx, s.taskCacheKey(taskID))
 	if err != nil {
 		return domain.Task{}, false
 	}
@@ -202,26 +833,71 @@ func (s *TaskService) getCachedTask(ctx context.Context, userID, taskID uuid.UUI
 		return domain.Task{}, false
 	}
 
+	// Payloads cached before a field was introduced miss its value entirely.
+	if !task.Priority.IsValid() {
+		return domain.Task{}, false
+	}
+
+	// A payload older than the last written version was put back by a reader
+	// that raced with a write and must not be served.
+	if latest, err := s.TaskCache.Get(ctx, s.taskVersionCacheKey(taskID)); err == nil {
+		version, err := strconv.ParseInt(latest, 10, 64)
+		if err != nil || task.Version < version {
+			return domain.Task{}, false
+		}
+	}
+
 	return task, true
 }
 
+// cacheTask stores a task produced by a write and records its version as the
+// newest one, so stale payloads cached by concurrent readers are ignored.
 func (s *TaskService) cacheTask(ctx context.Context, task domain.Task) {
 	if s.TaskCache == nil {
 		return
 	}
 
+	s.fillTaskCache(ctx, task)
+
+	_ = s.TaskCache.Set(
+		ctx,
+		s.taskVersionCacheKey(task.ID),
+		strconv.FormatInt(task.Version, 10),
+		taskVersionCacheTTL,
+	)
+}
+
+// fillTaskCache stores a task read from storage without touching the version
+// marker: the read may already be older than a concurrent write.
+func (s *TaskService) fillTaskCache(ctx context.Context, task domain.Task) {
+	if s.TaskCache == nil {
+		return
+	}
+
 	payload, err := json.Marshal(task)
 	if err != nil {
 		return
 	}
 
-	_ = s.TaskCache.Set(ctx, s.taskCacheKey(task.UserID, task.ID), string(payload), taskCacheTTL)
+	_ = s.TaskCache.Set(ctx, s.taskCacheKey(task.ID), string(payload), taskCacheTTL)
+}
+
+func (s *TaskService) deleteCachedTask(ctx context.Context, taskID uuid.UUID) {
+	s.invalidateCachedTask(ctx, taskID, deletedTaskVersion)
 }
 
-func (s *TaskService) deleteCachedTask(ctx context.Context, userID, taskID uuid.UUID) {
+// invalidateCachedTask drops the cached payload of a task written outside of
+// TaskService and records the version storage has assigned to it.
+func (s *TaskService) invalidateCachedTask(ctx context.Context, taskID uuid.UUID, version int64) {
 	if s.TaskCache == nil {
 		return
 	}
 
-	_ = s.TaskCache.Delete(ctx, s.taskCacheKey(userID, taskID))
+	_ = s.TaskCache.Delete(ctx, s.taskCacheKey(taskID))
+	_ = s.TaskCache.Set(
+		ctx,
+		s.taskVersionCacheKey(taskID),
+		strconv.FormatInt(version, 10),
+		taskVersionCacheTTL,
+	)
 }
@@ -19,7 +19,7 @@ func TestTaskServiceCreateTask(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 
@@ -29,30 +29,80 @@ func TestTaskServiceCreateTask(t *testing.T) {
 				task.Title == "Title" &&
 				task.Description == "Description" &&
 				task.Status == domain.StatusPending &&
+				task.Priority == domain.PriorityMedium &&
 				task.ID != uuid.Nil &&
 				!task.CreatedAt.IsZero()
 		})).
 		Return(domain.Task{ID: uuid.New(), UserID: userID, Title: "Title"}, nil).
 		Once()
 
-	task, err := svc.CreateTask(ctx, userID, "  Title  ", "  Description  ")
+	task, err := svc.CreateTask(ctx, userID, CreateTaskInput{
+		Title:       "  Title  ",
+		Description: "  Description  ",
+	})
 
 	require.NoError(t, err)
 	require.Equal(t, userID, task.UserID)
 	repo.AssertExpectations(t)
 }
 
+func TestTaskServiceCreateTaskSetsPriorityAndDueDate(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	priority := domain.PriorityUrgent
+	dueAt := mockTime().Add(24 * time.Hour)
+
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.Priority == domain.PriorityUrgent &&
+				task.DueAt != nil &&
+				task.DueAt.Equal(dueAt)
+		})).
+		Return(domain.Task{ID: uuid.New(), UserID: userID, Priority: priority, DueAt: &dueAt}, nil).
+		Once()
+
+	task, err := svc.CreateTask(ctx, userID, CreateTaskInput{
+		Title:    "Title",
+		Priority: &priority,
+		DueAt:    &dueAt,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.PriorityUrgent, task.Priority)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceCreateTaskRejectsInvalidPriority(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	priority := domain.Priority("critical")
+
+	_, err := svc.CreateTask(context.Background(), uuid.New(), CreateTaskInput{
+		Title:    "Title",
+		Priority: &priority,
+	})
+
+	require.ErrorIs(t, err, domain.ErrInvalidPriority)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestTaskServiceGetTaskReturnsNotFound(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(domain.Task{}, errors.New("db error")).
 		Once()
 
@@ -66,7 +116,7 @@ func TestTaskServiceGetTaskReturnsTask(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -79,7 +129,7 @@ func TestTaskServiceGetTaskReturnsTask(t *testing.T) {
 	}
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(expected, nil).
 		Once()
 
@@ -95,7 +145,7 @@ func TestTaskServiceGetTaskReturnsCachedTask(t *testing.T) {
 
 	repo := mocks.NewTaskRepository(t)
 	cache := mocks.NewTaskCache(t)
-	svc := NewTaskService(repo, cache)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -104,21 +154,26 @@ func TestTaskServiceGetTaskReturnsCachedTask(t *testing.T) {
 		UserID:    userID,
 		Title:     "Cached",
 		Status:    domain.StatusPending,
+		Priority:  domain.PriorityMedium,
 		CreatedAt: mockTime(),
 	}
 	payload, err := json.Marshal(cachedTask)
 	require.NoError(t, err)
 
 	cache.EXPECT().
-		Get(ctx, svc.taskCacheKey(userID, taskID)).
+		Get(ctx, svc.taskCacheKey(taskID)).
 		Return(string(payload), nil).
 		Once()
+	cache.EXPECT().
+		Get(ctx, svc.taskVersionCacheKey(taskID)).
+		Return("", errors.New("cache miss")).
+		Once()
 
 	task, err := svc.GetTask(ctx, userID, taskID)
 
 	require.NoError(t, err)
 	require.Equal(t, cachedTask, task)
-	repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
 }
 
 func TestTaskServiceGetTaskCachesRepositoryResult(t *testing.T) {
@@ -126,7 +181,7 @@ func TestTaskServiceGetTaskCachesRepositoryResult(t *testing.T) {
 
 	repo := mocks.NewTaskRepository(t)
 	cache := mocks.NewTaskCache(t)
-	svc := NewTaskService(repo, cache)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -139,15 +194,15 @@ func TestTaskServiceGetTaskCachesRepositoryResult(t *testing.T) {
 	}
 
 	cache.EXPECT().
-		Get(ctx, svc.taskCacheKey(userID, taskID)).
+		Get(ctx, svc.taskCacheKey(taskID)).
 		Return("", errors.New("cache miss")).
 		Once()
 	repo.EXPECT().
-		Get(ctx, taskID, userID).
+		Get(ctx, taskID).
 		Return(expected, nil).
 		Once()
 	cache.EXPECT().
-		Set(ctx, svc.taskCacheKey(userID, taskID), mock.Anything, taskCacheTTL).
+		Set(ctx, svc.taskCacheKey(taskID), mock.Anything, taskCacheTTL).
 		Return(nil).
 		Once()
 
@@ -161,7 +216,7 @@ func TestTaskServiceChangeStatusUpdatesTask(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -175,19 +230,24 @@ func TestTaskServiceChangeStatusUpdatesTask(t *testing.T) {
 	}
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(task, nil).
 		Once()
 	repo.
 		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
 			return updated.ID == taskID &&
 				updated.Status == domain.StatusDone &&
-				updated.CompletedAt != nil
+				updated.CompletedAt != nil &&
+				updated.CompletedBy != nil && *updated.CompletedBy == userID
 		})).
 		Return(task, nil).
 		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{taskID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
 
-	err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone)
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, nil)
 
 	require.NoError(t, err)
 	repo.AssertExpectations(t)
@@ -197,17 +257,17 @@ func TestTaskServiceChangeStatusReturnsNotFound(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(domain.Task{}, errors.New("db error")).
 		Once()
 
-	err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone)
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, nil)
 
 	require.ErrorIs(t, err, ErrTaskNotFound)
 	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
@@ -218,7 +278,7 @@ func TestTaskServiceChangeStatusRejectsInvalidTransition(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -228,6 +288,7 @@ func TestTaskServiceChangeStatusRejectsInvalidTransition(t *testing.T) {
 		UserID:    userID,
 		Title:     "Task",
 		Status:    domain.StatusDone,
+		Category:  domain.CategoryDone,
 		CreatedAt: mockTime(),
 		CompletedAt: func() *time.Time {
 			tm := mockTime()
@@ -236,11 +297,11 @@ func TestTaskServiceChangeStatusRejectsInvalidTransition(t *testing.T) {
 	}
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(task, nil).
 		Once()
 
-	err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusCancelled)
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusCancelled, nil)
 
 	require.ErrorIs(t, err, domain.ErrInvalidTransition)
 	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
@@ -251,7 +312,7 @@ func TestTaskServiceUpdateTaskRejectsEmptyTitle(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -266,7 +327,7 @@ func TestTaskServiceUpdateTaskRejectsEmptyTitle(t *testing.T) {
 	}
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(task, nil).
 		Once()
 
@@ -282,7 +343,7 @@ func TestTaskServiceUpdateTaskUpdatesFields(t *testing.T) {
 
 	repo := mocks.NewTaskRepository(t)
 	cache := mocks.NewTaskCache(t)
-	svc := NewTaskService(repo, cache)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -299,7 +360,7 @@ func TestTaskServiceUpdateTaskUpdatesFields(t *testing.T) {
 	}
 
 	repo.
-		On("Get", ctx, taskID, userID).
+		On("Get", ctx, taskID).
 		Return(task, nil).
 		Once()
 	repo.
@@ -318,7 +379,11 @@ func TestTaskServiceUpdateTaskUpdatesFields(t *testing.T) {
 		}, nil).
 		Once()
 	cache.EXPECT().
-		Set(ctx, svc.taskCacheKey(userID, taskID), mock.Anything, taskCacheTTL).
+		Set(ctx, svc.taskCacheKey(taskID), mock.Anything, taskCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(taskID), "0", taskVersionCacheTTL).
 		Return(nil).
 		Once()
 
@@ -335,21 +400,53 @@ func TestTaskServiceDeleteTaskDelegatesToRepository(t *testing.T) {
 
 	repo := mocks.NewTaskRepository(t)
 	cache := mocks.NewTaskCache(t)
-	svc := NewTaskService(repo, cache)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	taskID := uuid.New()
 
+	subtaskID := uuid.New()
+
 	repo.
-		On("Delete", ctx, taskID, userID).
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+	repo.
+		On("Descendants", ctx, taskID).
+		Return(map[uuid.UUID]int{subtaskID: 1}, nil).
+		Once()
+	dependentID := uuid.New()
+
+	repo.
+		On("Delete", ctx, mock.MatchedBy(func(task domain.Task) bool { return task.ID == taskID })).
+		Return(map[uuid.UUID]int64{dependentID: 7}, nil).
+		Once()
+	cache.EXPECT().
+		Delete(ctx, svc.taskCacheKey(dependentID)).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(dependentID), "7", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Delete(ctx, svc.taskCacheKey(taskID)).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Delete(ctx, svc.taskCacheKey(subtaskID)).
 		Return(nil).
 		Once()
 	cache.EXPECT().
-		Delete(ctx, svc.taskCacheKey(userID, taskID)).
+		Set(ctx, svc.taskVersionCacheKey(subtaskID), mock.Anything, taskVersionCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(taskID), mock.Anything, taskVersionCacheTTL).
 		Return(nil).
 		Once()
 
-	err := svc.DeleteTask(ctx, userID, taskID)
+	err := svc.DeleteTask(ctx, userID, taskID, nil)
 
 	require.NoError(t, err)
 	repo.AssertExpectations(t)
@@ -359,7 +456,7 @@ func TestTaskServiceListTasksDelegatesToRepository(t *testing.T) {
 	t.Parallel()
 
 	repo := mocks.NewTaskRepository(t)
-	svc := NewTaskService(repo, nil)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 	filter := domain.TaskFilter{Limit: 10}
@@ -384,3 +481,970 @@ func TestTaskServiceListTasksDelegatesToRepository(t *testing.T) {
 	require.Equal(t, expected, tasks)
 	repo.AssertExpectations(t)
 }
+
+func TestTaskServicePatchTaskAppliesAllFields(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	title := "Renamed"
+	status := domain.StatusInProgress
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			CreatedAt: mockTime(),
+		}, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.Title == "Renamed" &&
+				updated.Status == domain.StatusInProgress
+		})).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Renamed",
+			Status:    domain.StatusInProgress,
+			CreatedAt: mockTime(),
+		}, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{taskID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+
+	updated, err := svc.PatchTask(ctx, userID, taskID, TaskPatch{Title: &title, Status: &status}, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusInProgress, updated.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServicePatchTaskRejectsPartiallyInvalidPatch(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	title := "Renamed"
+	status := domain.Status("archived")
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			CreatedAt: mockTime(),
+		}, nil).
+		Once()
+
+	_, err := svc.PatchTask(ctx, userID, taskID, TaskPatch{Title: &title, Status: &status}, nil)
+
+	require.ErrorIs(t, err, domain.ErrInvalidStatus)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceGetTaskSkipsStaleCachedTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	stale := domain.Task{
+		ID:        taskID,
+		UserID:    userID,
+		Title:     "Stale",
+		Status:    domain.StatusPending,
+		Priority:  domain.PriorityMedium,
+		CreatedAt: mockTime(),
+		Version:   1,
+	}
+	fresh := stale
+	fresh.Title = "Fresh"
+	fresh.Version = 2
+	payload, err := json.Marshal(stale)
+	require.NoError(t, err)
+
+	cache.EXPECT().
+		Get(ctx, svc.taskCacheKey(taskID)).
+		Return(string(payload), nil).
+		Once()
+	cache.EXPECT().
+		Get(ctx, svc.taskVersionCacheKey(taskID)).
+		Return("2", nil).
+		Once()
+	repo.EXPECT().
+		Get(ctx, taskID).
+		Return(fresh, nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskCacheKey(taskID), mock.Anything, taskCacheTTL).
+		Return(nil).
+		Once()
+
+	task, err := svc.GetTask(ctx, userID, taskID)
+
+	require.NoError(t, err)
+	require.Equal(t, fresh, task)
+}
+
+func TestTaskServiceChangeStatusRejectsVersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	expectedVersion := int64(1)
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			CreatedAt: mockTime(),
+			Version:   2,
+		}, nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, &expectedVersion)
+
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceDeleteTaskRejectsVersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	expectedVersion := int64(3)
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			CreatedAt: mockTime(),
+			Version:   4,
+		}, nil).
+		Once()
+
+	err := svc.DeleteTask(ctx, userID, taskID, &expectedVersion)
+
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceDeleteTaskDeletesOnlyTheLoadedVersion(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	expectedVersion := int64(4)
+	task := domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending, Version: 4}
+
+	repo.On("Get", ctx, taskID).Return(task, nil).Once()
+	repo.On("Descendants", ctx, taskID).Return(map[uuid.UUID]int{}, nil).Once()
+	// Somebody else bumps the version between the check and the delete.
+	repo.On("Delete", ctx, task).Return(nil, domain.ErrVersionConflict).Once()
+
+	err := svc.DeleteTask(ctx, userID, taskID, &expectedVersion)
+
+	require.ErrorIs(t, err, domain.ErrVersionConflict)
+	cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestTaskServicePatchTaskClearsDueDate(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	dueAt := mockTime()
+	priority := domain.PriorityHigh
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			Priority:  domain.PriorityLow,
+			DueAt:     &dueAt,
+			CreatedAt: mockTime(),
+		}, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.DueAt == nil && updated.Priority == domain.PriorityHigh
+		})).
+		Return(domain.Task{ID: taskID, UserID: userID, Priority: domain.PriorityHigh}, nil).
+		Once()
+
+	_, err := svc.PatchTask(ctx, userID, taskID, TaskPatch{Priority: &priority, ClearDueAt: true}, nil)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceAttachLabelRejectsForeignLabel(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	labels := mocks.NewLabelRepository(t)
+	svc := NewTaskService(repo, nil, labels, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	labelID := uuid.New()
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending}, nil).
+		Once()
+	labels.
+		On("Get", ctx, labelID, userID).
+		Return(domain.Label{}, errors.New("label not found in storage")).
+		Once()
+
+	_, err := svc.AttachLabel(ctx, userID, taskID, labelID, nil)
+
+	require.ErrorIs(t, err, ErrLabelNotFound)
+	repo.AssertNotCalled(t, "AttachLabel", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceAttachLabelCachesUpdatedTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	labels := mocks.NewLabelRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, labels, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	label := domain.Label{ID: uuid.New(), UserID: userID, Name: "backend", Color: domain.DefaultLabelColor}
+	task := domain.Task{ID: taskID, UserID: userID, Title: "Task", Status: domain.StatusPending, Version: 1}
+	labelled := task
+	labelled.Labels = []domain.Label{label}
+	labelled.Version = 2
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(task, nil).
+		Once()
+	labels.
+		On("Get", ctx, label.ID, userID).
+		Return(label, nil).
+		Once()
+	repo.
+		On("AttachLabel", ctx, task, label.ID).
+		Return(labelled, nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskCacheKey(taskID), mock.Anything, taskCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(taskID), "2", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+
+	updated, err := svc.AttachLabel(ctx, userID, taskID, label.ID, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, []domain.Label{label}, updated.Labels)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceCreateTaskUnderParentTouchesParent(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	parent := domain.Task{ID: uuid.New(), UserID: userID, Title: "Parent", Status: domain.StatusPending}
+
+	repo.
+		On("Get", ctx, parent.ID).
+		Return(parent, nil).
+		Once()
+	repo.
+		On("Ancestors", ctx, parent.ID).
+		Return([]uuid.UUID{}, nil).
+		Once()
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.ParentID != nil && *task.ParentID == parent.ID
+		})).
+		Return(domain.Task{ID: uuid.New(), UserID: userID, Title: "Child", ParentID: &parent.ID}, nil).
+		Once()
+	repo.
+		On("Touch", ctx, parent.ID).
+		Return(parent, nil).
+		Once()
+
+	task, err := svc.CreateTask(ctx, userID, CreateTaskInput{Title: "Child", ParentID: &parent.ID})
+
+	require.NoError(t, err)
+	require.Equal(t, &parent.ID, task.ParentID)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceCreateTaskRejectsUnknownParent(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	parentID := uuid.New()
+
+	repo.
+		On("Get", ctx, parentID).
+		Return(domain.Task{}, errors.New("task not found in storage")).
+		Once()
+
+	_, err := svc.CreateTask(ctx, userID, CreateTaskInput{Title: "Child", ParentID: &parentID})
+
+	require.ErrorIs(t, err, ErrParentNotFound)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceChangeStatusRejectsDoneWithOpenSubtasks(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusInProgress,
+			CreatedAt: mockTime(),
+			Subtasks:  domain.SubtaskProgress{Total: 3, Done: 2},
+		}, nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusDone, nil)
+
+	require.ErrorIs(t, err, domain.ErrOpenSubtasks)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceCompleteWithSubtasksCompletesOpenSubtasks(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	subtaskID := uuid.New()
+
+	task := domain.Task{
+		ID:        taskID,
+		UserID:    userID,
+		Title:     "Task",
+		Status:    domain.StatusInProgress,
+		CreatedAt: mockTime(),
+		Subtasks:  domain.SubtaskProgress{Total: 1},
+		Version:   1,
+	}
+	completed := task
+	completed.Status = domain.StatusDone
+	completed.Category = domain.CategoryDone
+	completed.Subtasks.Done = 1
+	completed.Version = 2
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("CompleteWithSubtasks", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.ID == taskID && updated.Status == domain.StatusDone && updated.CompletedAt != nil
+		}), mock.Anything).
+		Return(completed, map[uuid.UUID]int64{subtaskID: 4}, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{subtaskID, taskID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+	cache.EXPECT().
+		Delete(ctx, svc.taskCacheKey(subtaskID)).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(subtaskID), "4", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskCacheKey(taskID), mock.Anything, taskCacheTTL).
+		Return(nil).
+		Once()
+	cache.EXPECT().
+		Set(ctx, svc.taskVersionCacheKey(taskID), "2", taskVersionCacheTTL).
+		Return(nil).
+		Once()
+
+	updated, subtasks, err := svc.CompleteWithSubtasks(ctx, userID, taskID, domain.StatusDone, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusDone, updated.Status)
+	require.Equal(t, []uuid.UUID{subtaskID}, subtasks)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceCompleteWithSubtasksLeavesCacheOnConflict(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	cache := mocks.NewTaskCache(t)
+	svc := NewTaskService(repo, cache, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusInProgress, Version: 1}
+
+	repo.On("Get", ctx, task.ID).Return(task, nil).Once()
+	// The subtasks are completed together with the task, so a conflict on the
+	// task leaves them open as well.
+	repo.
+		On("CompleteWithSubtasks", ctx, mock.Anything, mock.Anything).
+		Return(domain.Task{}, nil, domain.ErrVersionConflict).
+		Once()
+
+	_, _, err := svc.CompleteWithSubtasks(ctx, userID, task.ID, domain.StatusDone, nil)
+
+	require.ErrorIs(t, err, domain.ErrVersionConflict)
+	cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	cache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTaskServiceMoveTaskRejectsCycle(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusPending}
+	grandchild := domain.Task{ID: uuid.New(), UserID: userID, Title: "Grandchild", Status: domain.StatusPending}
+	childID := uuid.New()
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Descendants", ctx, task.ID).
+		Return(map[uuid.UUID]int{childID: 1, grandchild.ID: 2}, nil).
+		Once()
+	repo.
+		On("Get", ctx, grandchild.ID).
+		Return(grandchild, nil).
+		Once()
+	repo.
+		On("Ancestors", ctx, grandchild.ID).
+		Return([]uuid.UUID{childID, task.ID}, nil).
+		Once()
+
+	_, err := svc.MoveTask(ctx, userID, task.ID, &grandchild.ID, nil)
+
+	require.ErrorIs(t, err, domain.ErrTaskCycle)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceMoveTaskRejectsTooDeepHierarchy(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusPending}
+	parent := domain.Task{ID: uuid.New(), UserID: userID, Title: "Parent", Status: domain.StatusPending}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Descendants", ctx, task.ID).
+		Return(map[uuid.UUID]int{uuid.New(): 1}, nil).
+		Once()
+	repo.
+		On("Get", ctx, parent.ID).
+		Return(parent, nil).
+		Once()
+	repo.
+		On("Ancestors", ctx, parent.ID).
+		Return([]uuid.UUID{uuid.New(), uuid.New(), uuid.New()}, nil).
+		Once()
+
+	_, err := svc.MoveTask(ctx, userID, task.ID, &parent.ID, nil)
+
+	require.ErrorIs(t, err, domain.ErrTaskTooDeep)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceMoveTaskToRootTouchesPreviousParent(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	parentID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusPending, ParentID: &parentID}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.ID == task.ID && updated.ParentID == nil
+		})).
+		Return(domain.Task{ID: task.ID, UserID: userID, Title: "Task"}, nil).
+		Once()
+	repo.
+		On("Touch", ctx, parentID).
+		Return(domain.Task{ID: parentID, UserID: userID}, nil).
+		Once()
+
+	moved, err := svc.MoveTask(ctx, userID, task.ID, nil, nil)
+
+	require.NoError(t, err)
+	require.Nil(t, moved.ParentID)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceChangeStatusRejectsBlockedTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    domain.StatusPending,
+			CreatedAt: mockTime(),
+			BlockedBy: []domain.Blocker{
+				{ID: uuid.New(), Status: domain.StatusDone, Category: domain.CategoryDone},
+				{ID: uuid.New(), Status: domain.StatusInProgress, Category: domain.CategoryInProgress},
+			},
+		}, nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusInProgress, nil)
+
+	require.ErrorIs(t, err, domain.ErrBlocked)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceChangeStatusAllowsCancelingBlockedTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+	task := domain.Task{
+		ID:        taskID,
+		UserID:    userID,
+		Title:     "Task",
+		Status:    domain.StatusPending,
+		CreatedAt: mockTime(),
+		BlockedBy: []domain.Blocker{{ID: uuid.New(), Status: domain.StatusPending, Category: domain.CategoryTodo}},
+	}
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.Status == domain.StatusCancelled
+		})).
+		Return(task, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{taskID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, taskID, domain.StatusCancelled, nil)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceAddBlockerRejectsCycle(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusPending}
+	blocker := domain.Task{ID: uuid.New(), UserID: userID, Title: "Blocker", Status: domain.StatusPending}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Get", ctx, blocker.ID).
+		Return(blocker, nil).
+		Once()
+	repo.
+		On("TransitiveBlockers", ctx, blocker.ID).
+		Return([]uuid.UUID{uuid.New(), task.ID}, nil).
+		Once()
+
+	_, err := svc.AddBlocker(ctx, userID, task.ID, blocker.ID, nil)
+
+	require.ErrorIs(t, err, domain.ErrDependencyCycle)
+	repo.AssertNotCalled(t, "AddBlocker", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceAddBlockerRejectsSelfDependency(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	taskID := uuid.New()
+
+	_, err := svc.AddBlocker(context.Background(), uuid.New(), taskID, taskID, nil)
+
+	require.ErrorIs(t, err, domain.ErrDependencyCycle)
+}
+
+func TestTaskServiceAddBlockerStoresDependency(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusPending}
+	blocker := domain.Task{ID: uuid.New(), UserID: userID, Title: "Blocker", Status: domain.StatusPending}
+	blocked := task
+	blocked.BlockedBy = []domain.Blocker{{ID: blocker.ID, Status: blocker.Status, Category: blocker.Category}}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Get", ctx, blocker.ID).
+		Return(blocker, nil).
+		Once()
+	repo.
+		On("TransitiveBlockers", ctx, blocker.ID).
+		Return([]uuid.UUID{}, nil).
+		Once()
+	repo.
+		On("AddBlocker", ctx, task, blocker.ID).
+		Return(blocked, nil).
+		Once()
+
+	updated, err := svc.AddBlocker(ctx, userID, task.ID, blocker.ID, nil)
+
+	require.NoError(t, err)
+	require.True(t, updated.IsBlocked())
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceReopenTask(t *testing.T) {
+	t.Parallel()
+
+	completedAt := mockTime()
+
+	tests := []struct {
+		name     string
+		task     domain.Task
+		to       domain.Status
+		status   domain.Status
+		category domain.StatusCategory
+		err      error
+	}{
+		{
+			name:     "to initial status",
+			task:     domain.Task{Status: domain.StatusDone, Category: domain.CategoryDone, CompletedAt: &completedAt},
+			status:   domain.StatusPending,
+			category: domain.CategoryTodo,
+		},
+		{
+			name:     "to in progress",
+			task:     domain.Task{Status: domain.StatusDone, Category: domain.CategoryDone, CompletedAt: &completedAt},
+			to:       domain.StatusInProgress,
+			status:   domain.StatusInProgress,
+			category: domain.CategoryInProgress,
+		},
+		{
+			name: "to closed status",
+			task: domain.Task{Status: domain.StatusDone, Category: domain.CategoryDone, CompletedAt: &completedAt},
+			to:   domain.StatusCancelled,
+			err:  domain.ErrInvalidTransition,
+		},
+		{
+			name: "open task",
+			task: domain.Task{Status: domain.StatusInProgress, Category: domain.CategoryInProgress},
+			err:  domain.ErrNotDone,
+		},
+		{
+			name: "canceled task",
+			task: domain.Task{Status: domain.StatusCancelled, Category: domain.CategoryCanceled},
+			err:  domain.ErrNotDone,
+		},
+		{
+			name: "blocked task to in progress",
+			task: domain.Task{
+				Status:      domain.StatusDone,
+				Category:    domain.CategoryDone,
+				CompletedAt: &completedAt,
+				BlockedBy:   []domain.Blocker{{ID: uuid.New(), Status: domain.StatusPending, Category: domain.CategoryTodo}},
+			},
+			to:  domain.StatusInProgress,
+			err: domain.ErrBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			analytics := &recordingPublisher{}
+			svc := NewTaskService(repo, nil, nil, nil, nil, nil, analytics, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+
+			task := tt.task
+			task.ID = uuid.New()
+			task.UserID = userID
+			task.Title = "Task"
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+
+			if tt.err == nil {
+				reopened := task
+				reopened.Status = tt.status
+				reopened.Category = tt.category
+				reopened.CompletedAt = nil
+
+				repo.
+					On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+						return updated.Status == tt.status &&
+							updated.Category == tt.category &&
+							updated.CompletedAt == nil
+					})).
+					Return(reopened, nil).
+					Once()
+				repo.
+					On("TouchDependents", ctx, []uuid.UUID{task.ID}).
+					Return(map[uuid.UUID]int64{}, nil).
+					Once()
+			}
+
+			_, err := svc.ReopenTask(ctx, userID, task.ID, tt.to, nil)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				require.Empty(t, analytics.events)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, analytics.events, 1)
+			require.Equal(t, TaskEventReopened, analytics.events[0].Type)
+			require.Equal(t, task.ID, analytics.events[0].TaskID)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskServiceReopenTaskTakesCompletionBackFromCompleter(t *testing.T) {
+	t.Parallel()
+
+	completedAt := mockTime()
+	completer := uuid.New()
+
+	tests := []struct {
+		name        string
+		completedBy *uuid.UUID
+		want        func(reopener uuid.UUID) uuid.UUID
+	}{
+		{
+			name:        "completed by another user",
+			completedBy: &completer,
+			want:        func(uuid.UUID) uuid.UUID { return completer },
+		},
+		{
+			name: "completer not recorded",
+			want: func(reopener uuid.UUID) uuid.UUID { return reopener },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			analytics := &recordingPublisher{}
+			svc := NewTaskService(repo, nil, nil, nil, nil, nil, analytics, nil)
+			ctx := context.Background()
+			reopener := uuid.New()
+
+			task := domain.Task{
+				ID:          uuid.New(),
+				UserID:      reopener,
+				AssigneeID:  &completer,
+				Title:       "Task",
+				Status:      domain.StatusDone,
+				Category:    domain.CategoryDone,
+				CompletedAt: &completedAt,
+				CompletedBy: tt.completedBy,
+			}
+			reopened := task
+			reopened.Status = domain.StatusPending
+			reopened.Category = domain.CategoryTodo
+			reopened.CompletedAt = nil
+			reopened.CompletedBy = nil
+
+			repo.On("Get", ctx, task.ID).Return(task, nil).Once()
+			repo.
+				On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+					return updated.CompletedAt == nil && updated.CompletedBy == nil
+				})).
+				Return(reopened, nil).
+				Once()
+			repo.On("TouchDependents", ctx, []uuid.UUID{task.ID}).Return(map[uuid.UUID]int64{}, nil).Once()
+
+			_, err := svc.ReopenTask(ctx, reopener, task.ID, "", nil)
+			require.NoError(t, err)
+
+			require.Len(t, analytics.events, 1)
+			require.Equal(t, TaskEventReopened, analytics.events[0].Type)
+			require.Equal(t, tt.want(reopener), analytics.events[0].UserID)
+			require.Equal(t, &completer, analytics.events[0].AssigneeID)
+		})
+	}
+}
+
+func TestTaskServiceRestoreTask(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	analytics := &recordingPublisher{}
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, analytics, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusCancelled, Category: domain.CategoryCanceled}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+			return updated.Status == domain.StatusPending &&
+				updated.Category == domain.CategoryTodo &&
+				updated.CompletedAt == nil
+		})).
+		Return(task, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{task.ID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+
+	_, err := svc.RestoreTask(ctx, userID, task.ID, nil)
+
+	require.NoError(t, err)
+	require.Empty(t, analytics.events, "restoring does not take back a completion")
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceRestoreTaskRejectsTaskThatIsNotCanceled(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	completedAt := mockTime()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: domain.StatusDone, Category: domain.CategoryDone, CompletedAt: &completedAt}
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+
+	_, err := svc.RestoreTask(ctx, userID, task.ID, nil)
+
+	require.ErrorIs(t, err, domain.ErrNotCanceled)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
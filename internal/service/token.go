@@ -1,94 +1,341 @@
 package service
 
 import (
-	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"taskflow/internal/domain"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrTokenExpired = errors.New("token expired")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotYetValid     = errors.New("token not yet valid")
+	ErrTokenIssuedInFuture  = errors.New("token issued in the future")
+	ErrInvalidTokenIssuer   = errors.New("invalid token issuer")
+	ErrInvalidTokenAudience = errors.New("invalid token audience")
 )
 
+// TokenValidation pins tokens to one deployment. Tokens are issued with the
+// issuer and audience and rejected unless they carry the same ones; empty
+// values are neither issued nor checked. Tokens issued for a purpose get an
+// audience of their own, see purposeAudience. Leeway absorbs clock skew
+// between the issuing and the verifying instance in exp, nbf and iat checks.
+type TokenValidation struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
 type TokenService struct {
-	secret []byte
-	ttl    time.Duration
+	keyring    *Keyring
+	ttl        time.Duration
+	validation TokenValidation
 }
 
+// tokenHeader names the key a token was signed with. The algorithm must be the
+// key's own, so a token cannot pick how it is verified.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// tokenPayload carries the subject of a token. Access tokens have no purpose
+// and list their scopes space-separated, as in OAuth 2.0; tokens issued for
+// other flows, such as invitations, name their purpose so that one kind cannot
+// be used in place of another.
 type tokenPayload struct {
-	Sub string `json:"sub"`
-	Exp int64  `json:"exp"`
+	Jti     string `json:"jti"`
+	Iss     string `json:"iss,omitempty"`
+	Aud     string `json:"aud,omitempty"`
+	Sub     string `json:"sub"`
+	Iat     int64  `json:"iat"`
+	Nbf     int64  `json:"nbf,omitempty"`
+	Exp     int64  `json:"exp"`
+	Purpose string `json:"purpose,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+	// IatMs is iat in milliseconds. The denylist needs the finer time to tell
+	// a token issued right after a logout everywhere from one issued before.
+	IatMs int64 `json:"iat_ms,omitempty"`
+}
+
+// TokenClaims are the verified claims of a token.
+type TokenClaims struct {
+	// ID identifies the token so that it can be revoked before it expires.
+	ID        string
+	Subject   uuid.UUID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Scopes are what an access token grants.
+	Scopes []domain.Scope
 }
 
+// NewTokenService signs and verifies with a single HS256 key built from the
+// secret and checks neither issuer nor audience.
 func NewTokenService(secret string, ttl time.Duration) *TokenService {
+	key := SigningKey{id: DefaultKeyID, algorithm: AlgorithmHS256, secret: []byte(secret)}
+
+	return &TokenService{
+		keyring: &Keyring{active: &key, keys: map[string]*SigningKey{key.id: &key}},
+		ttl:     ttl,
+	}
+}
+
+func NewKeyringTokenService(keyring *Keyring, ttl time.Duration, validation TokenValidation) *TokenService {
 	return &TokenService{
-		secret: []byte(secret),
-		ttl:    ttl,
+		keyring:    keyring,
+		ttl:        ttl,
+		validation: validation,
 	}
 }
 
+// VerificationKeys returns the public keys tokens may be verified with.
+func (s *TokenService) VerificationKeys() []VerificationKey {
+	return s.keyring.VerificationKeys()
+}
+
+// Issue signs an access token for an interactive login, which is granted
+// every scope.
 func (s *TokenService) Issue(userID uuid.UUID) (string, error) {
-	header, err := s.encode(map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
+	return s.issue(userID, "", domain.AllScopes, s.ttl)
+}
+
+func (s *TokenService) Parse(token string) (uuid.UUID, error) {
+	claims, err := s.parse(token, "")
+	return claims.Subject, err
+}
+
+// ParseClaims verifies an access token like Parse and returns all of its
+// claims.
+func (s *TokenService) ParseClaims(token string) (TokenClaims, error) {
+	return s.parse(token, "")
+}
+
+// IssueFor signs a token for the subject that is valid only for the purpose
+// and only within the ttl.
+func (s *TokenService) IssueFor(purpose string, subject uuid.UUID, ttl time.Duration) (string, error) {
+	return s.issue(subject, purpose, nil, ttl)
+}
+
+// ParseFor returns the subject of a token issued for the purpose.
+func (s *TokenService) ParseFor(purpose, token string) (uuid.UUID, error) {
+	claims, err := s.parse(token, purpose)
+	return claims.Subject, err
+}
+
+// ParseClaimsFor verifies a token like ParseFor and returns all of its claims.
+func (s *TokenService) ParseClaimsFor(purpose, token string) (TokenClaims, error) {
+	return s.parse(token, purpose)
+}
+
+func (s *TokenService) issue(subject uuid.UUID, purpose string, scopes []domain.Scope, ttl time.Duration) (string, error) {
+	key := s.keyring.active
+	typ, audience := "JWT", s.validation.Audience
+	if purpose != "" {
+		typ, audience = purpose+"+jwt", s.purposeAudience(purpose)
+	}
+
+	header, err := s.encode(tokenHeader{
+		Alg: key.algorithm,
+		Typ: typ,
+		Kid: key.id,
 	})
 	if err != nil {
 		return "", err
 	}
 
+	now := time.Now()
 	payload, err := s.encode(tokenPayload{
-		Sub: userID.String(),
-		Exp: time.Now().Add(s.ttl).Unix(),
+		Jti:     uuid.NewString(),
+		Iss:     s.validation.Issuer,
+		Aud:     audience,
+		Sub:     subject.String(),
+		Iat:     now.Unix(),
+		IatMs:   now.UnixMilli(),
+		Nbf:     now.Unix(),
+		Exp:     now.Add(ttl).Unix(),
+		Purpose: purpose,
+		Scope:   joinScopes(scopes),
 	})
 	if err != nil {
 		return "", err
 	}
 
 	signingInput := header + "." + payload
-	signature := s.sign(signingInput)
+	signature, err := key.sign(signingInput)
+	if err != nil {
+		return "", err
+	}
 
 	return signingInput + "." + signature, nil
 }
 
-func (s *TokenService) Parse(token string) (uuid.UUID, error) {
+func (s *TokenService) parse(token, purpose string) (TokenClaims, error) {
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
-		return uuid.Nil, ErrInvalidToken
+		return TokenClaims{}, ErrInvalidToken
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return TokenClaims{}, ErrInvalidToken
+	}
+
+	var header tokenHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return TokenClaims{}, ErrInvalidToken
+	}
+
+	key, ok := s.keyring.key(header.Kid)
+	if !ok || header.Alg != key.algorithm {
+		return TokenClaims{}, ErrInvalidToken
 	}
 
-	signingInput := parts[0] + "." + parts[1]
-	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(signingInput))) {
-		return uuid.Nil, ErrInvalidToken
+	if !key.verify(parts[0]+"."+parts[1], parts[2]) {
+		return TokenClaims{}, ErrInvalidToken
 	}
 
 	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return uuid.Nil, ErrInvalidToken
+		return TokenClaims{}, ErrInvalidToken
 	}
 
 	var payload tokenPayload
 	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
-		return uuid.Nil, ErrInvalidToken
+		return TokenClaims{}, ErrInvalidToken
 	}
 
-	if time.Now().Unix() >= payload.Exp {
-		return uuid.Nil, ErrTokenExpired
+	if payload.Purpose != purpose {
+		return TokenClaims{}, ErrInvalidToken
 	}
 
-	userID, err := uuid.Parse(payload.Sub)
+	if err := s.validate(payload, time.Now()); err != nil {
+		return TokenClaims{}, err
+	}
+
+	subject, err := uuid.Parse(payload.Sub)
 	if err != nil {
-		return uuid.Nil, ErrInvalidToken
+		return TokenClaims{}, ErrInvalidToken
+	}
+
+	return TokenClaims{
+		ID:        payload.Jti,
+		Subject:   subject,
+		IssuedAt:  payload.issuedAt(),
+		ExpiresAt: time.Unix(payload.Exp, 0),
+		Scopes:    splitScopes(payload.Scope),
+	}, nil
+}
+
+// issuedAt prefers the millisecond issue time; a token carrying one that does
+// not match iat gets whole seconds.
+func (p tokenPayload) issuedAt() time.Time {
+	if p.IatMs/1000 == p.Iat {
+		return time.UnixMilli(p.IatMs)
+	}
+
+	return time.Unix(p.Iat, 0)
+}
+
+// validAudience accepts the audience a token is issued with.
+func (s *TokenService) validAudience(payload tokenPayload) bool {
+	if payload.Purpose != "" {
+		return payload.Aud == s.purposeAudience(payload.Purpose)
 	}
 
-	return userID, nil
+	return s.validation.Audience == "" || payload.Aud == s.validation.Audience
+}
+
+func joinScopes(scopes []domain.Scope) string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+
+	return strings.Join(names, " ")
+}
+
+// splitScopes reads the scope claim.
+func splitScopes(claim string) []domain.Scope {
+	names := strings.Fields(claim)
+	scopes := make([]domain.Scope, 0, len(names))
+	for _, name := range names {
+		scopes = append(scopes, domain.Scope(name))
+	}
+
+	return scopes
+}
+
+// purposeAudience is the audience of tokens issued for the purpose. The keys
+// tokens are signed with are published, so other services may verify our
+// access tokens; a distinct audience keeps them from taking a mailed link or
+// a pending MFA login for one.
+func (s *TokenService) purposeAudience(purpose string) string {
+	if s.validation.Audience == "" {
+		return "taskflow:" + purpose
+	}
+
+	return s.validation.Audience + ":" + purpose
+}
+
+// validate checks the registered claims of a verified token. Times are whole
+// seconds, so the leeway is rounded down to seconds too. Every token is issued
+// with iat, so one without it is rejected.
+func (s *TokenService) validate(payload tokenPayload, now time.Time) error {
+	if s.validation.Issuer != "" && payload.Iss != s.validation.Issuer {
+		return ErrInvalidTokenIssuer
+	}
+	if !s.validAudience(payload) {
+		return ErrInvalidTokenAudience
+	}
+
+	leeway := int64(s.validation.Leeway / time.Second)
+	unix := now.Unix()
+	switch {
+	case payload.Iat == 0:
+		return ErrInvalidToken
+	case unix >= payload.Exp+leeway:
+		return ErrTokenExpired
+	case payload.Nbf != 0 && unix+leeway < payload.Nbf:
+		return ErrTokenNotYetValid
+	case unix+leeway < payload.Iat:
+		return ErrTokenIssuedInFuture
+	}
+
+	return nil
+}
+
+// acceptedUntil is when validate stops accepting a token that expires at
+// expiresAt. Denylist entries must last as long, or a revoked token would work
+// again for the leeway.
+func (s *TokenService) acceptedUntil(expiresAt time.Time) time.Time {
+	return expiresAt.Add(s.validation.Leeway)
+}
+
+// NewOpaqueToken returns a random token that carries no claims, such as a
+// refresh token. It is meaningful only through the hash stored with it.
+func (s *TokenService) NewOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaqueToken returns the form an opaque token is stored and looked up in.
+func (s *TokenService) HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func (s *TokenService) encode(value any) (string, error) {
@@ -99,9 +346,3 @@ func (s *TokenService) encode(value any) (string, error) {
 
 	return base64.RawURLEncoding.EncodeToString(data), nil
 }
-
-func (s *TokenService) sign(value string) string {
-	mac := hmac.New(sha256.New, s.secret)
-	mac.Write([]byte(value))
-	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
-}
@@ -1,10 +1,24 @@
 package service
 
 import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"taskflow/internal/domain"
+
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -62,3 +76,444 @@ func TestTokenServiceParseRejectsTamperedToken(t *testing.T) {
 
 	require.ErrorIs(t, err, ErrInvalidToken)
 }
+
+func TestTokenServiceSeparatesTokenPurposes(t *testing.T) {
+	t.Parallel()
+
+	svc := NewTokenService("test-secret", time.Minute)
+	subject := uuid.New()
+	token, err := svc.IssueFor("invitation", subject, time.Hour)
+	require.NoError(t, err)
+
+	parsed, err := svc.ParseFor("invitation", token)
+	require.NoError(t, err)
+	require.Equal(t, subject, parsed)
+
+	_, err = svc.Parse(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	access, err := svc.Issue(subject)
+	require.NoError(t, err)
+
+	_, err = svc.ParseFor("invitation", access)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenServiceSignsWithActiveKeyAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for _, key := range []SigningKey{NewEd25519Key("ed", edKey), NewRSAKey("rsa", rsaKey)} {
+		keyring, err := NewKeyring(key.ID(), key)
+		require.NoError(t, err)
+		svc := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+		userID := uuid.New()
+
+		token, err := svc.Issue(userID)
+		require.NoError(t, err)
+
+		header := decodeTokenHeader(t, token)
+		require.Equal(t, key.Algorithm(), header.Alg)
+		require.Equal(t, key.ID(), header.Kid)
+
+		parsed, err := svc.Parse(token)
+		require.NoError(t, err)
+		require.Equal(t, userID, parsed)
+	}
+}
+
+func TestTokenServiceVerifiesRotatedKeysUntilRetired(t *testing.T) {
+	t.Parallel()
+
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	before, err := NewKeyring("old", NewEd25519Key("old", oldKey))
+	require.NoError(t, err)
+	token, err := NewKeyringTokenService(before, time.Hour, TokenValidation{}).Issue(uuid.New())
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring("new", NewEd25519Key("old", oldKey), NewEd25519Key("new", newKey))
+	require.NoError(t, err)
+	_, err = NewKeyringTokenService(rotated, time.Hour, TokenValidation{}).Parse(token)
+	require.NoError(t, err)
+
+	retired, err := NewKeyring("new", NewEd25519Key("new", newKey))
+	require.NoError(t, err)
+	_, err = NewKeyringTokenService(retired, time.Hour, TokenValidation{}).Parse(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenServiceRejectsAlgorithmOtherThanKeys(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyring, err := NewKeyring("rsa", NewRSAKey("rsa", rsaKey))
+	require.NoError(t, err)
+	svc := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+
+	token, err := svc.Issue(uuid.New())
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	// An HS256 token keyed with the public key must not pass as the RSA key's.
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	forger := SigningKey{id: "rsa", algorithm: AlgorithmHS256, secret: publicKey}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT","kid":"rsa"}`))
+	signature, err := forger.sign(header + "." + parts[1])
+	require.NoError(t, err)
+
+	_, err = svc.Parse(header + "." + parts[1] + "." + signature)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	unknown := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT","kid":"other"}`))
+	_, err = svc.Parse(unknown + "." + parts[1] + "." + parts[2])
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenServiceRejectsTokensWithoutKid(t *testing.T) {
+	t.Parallel()
+
+	svc := NewTokenService("secret", time.Hour)
+
+	token, err := svc.Issue(uuid.New())
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	signature, err := svc.keyring.active.sign(header + "." + parts[1])
+	require.NoError(t, err)
+
+	_, err = svc.Parse(header + "." + parts[1] + "." + signature)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// TestTokenServiceRejectsBaselineTokens parses a token as issued before key
+// ids and registered claims: no kid, only sub and exp.
+func TestTokenServiceRejectsBaselineTokens(t *testing.T) {
+	t.Parallel()
+
+	svc := NewTokenService("secret", time.Hour)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil,
+		`{"sub":%q,"exp":%d}`, uuid.NewString(), time.Now().Add(time.Hour).Unix()))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(header + "." + payload))
+	token := header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	_, err := svc.Parse(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestKeyringVerificationKeysOmitSecrets(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hmacKey, err := NewHMACKey(DefaultKeyID, "secret")
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(DefaultKeyID, hmacKey, NewEd25519Key("ed", edKey))
+	require.NoError(t, err)
+
+	keys := keyring.VerificationKeys()
+	require.Len(t, keys, 1)
+	require.Equal(t, "ed", keys[0].ID)
+	require.Equal(t, AlgorithmEdDSA, keys[0].Algorithm)
+}
+
+func TestNewKeyringRequiresActiveKeyThatSigns(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	require.NoError(t, err)
+
+	verifyOnly, err := ParsePEMKey("ed", AlgorithmEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	require.NoError(t, err)
+
+	_, err = NewKeyring("ed", verifyOnly)
+	require.ErrorIs(t, err, ErrInvalidKeyring)
+	_, err = NewKeyring("missing", NewEd25519Key("ed", edKey))
+	require.ErrorIs(t, err, ErrInvalidKeyring)
+	_, err = ParsePEMKey("ed", AlgorithmRS256, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	require.ErrorIs(t, err, ErrInvalidKeyring)
+}
+
+func decodeTokenHeader(t *testing.T, token string) tokenHeader {
+	t.Helper()
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	require.NoError(t, err)
+
+	var header tokenHeader
+	require.NoError(t, json.Unmarshal(data, &header))
+	return header
+}
+
+func TestLoadKeyringReadsKeysNextToFile(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privateKey, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "ed.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey}),
+		0o600,
+	))
+	path := filepath.Join(dir, "keyring.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"active": "ed",
+		"keys": [
+			{"kid": "ed", "alg": "EdDSA", "pem_file": "ed.pem"},
+			{"kid": "default", "alg": "HS256", "secret": "old-secret"}
+		]
+	}`), 0o600))
+
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+
+	oldToken, err := NewTokenService("old-secret", time.Hour).Issue(uuid.New())
+	require.NoError(t, err)
+	svc := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+	_, err = svc.Parse(oldToken)
+	require.NoError(t, err)
+
+	token, err := svc.Issue(uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, "ed", decodeTokenHeader(t, token).Kid)
+}
+
+func TestTokenServiceRejectsOtherIssuerAndAudience(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	staging := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Issuer: "staging", Audience: "api"})
+	production := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Issuer: "production", Audience: "api"})
+	admin := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Issuer: "staging", Audience: "admin"})
+
+	token, err := staging.Issue(uuid.New())
+	require.NoError(t, err)
+
+	_, err = staging.Parse(token)
+	require.NoError(t, err)
+	_, err = production.Parse(token)
+	require.ErrorIs(t, err, ErrInvalidTokenIssuer)
+	_, err = admin.Parse(token)
+	require.ErrorIs(t, err, ErrInvalidTokenAudience)
+}
+
+func TestTokenServiceGivesPurposeTokensTheirOwnAudience(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	svc := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Issuer: "taskflow", Audience: "api"})
+	subject := uuid.New()
+
+	token, err := svc.IssueFor("mfa", subject, time.Minute)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	var header tokenHeader
+	decodeTestTokenPart(t, parts[0], &header)
+	var payload tokenPayload
+	decodeTestTokenPart(t, parts[1], &payload)
+	require.Equal(t, "mfa+jwt", header.Typ)
+	require.Equal(t, "api:mfa", payload.Aud)
+
+	parsed, err := svc.ParseFor("mfa", token)
+	require.NoError(t, err)
+	require.Equal(t, subject, parsed)
+
+	now := time.Now().Unix()
+	accessAudience := signTestPayload(t, keyring, tokenPayload{
+		Iss: "taskflow", Aud: "api", Sub: subject.String(), Iat: now, Exp: now + 60, Purpose: "mfa",
+	})
+	_, err = svc.ParseFor("mfa", accessAudience)
+	require.ErrorIs(t, err, ErrInvalidTokenAudience, "purpose tokens must not carry the access token audience")
+
+	other := signTestPayload(t, keyring, tokenPayload{
+		Iss: "taskflow", Aud: "api:invitation", Sub: subject.String(), Iat: now, Exp: now + 60, Purpose: "mfa",
+	})
+	_, err = svc.ParseFor("mfa", other)
+	require.ErrorIs(t, err, ErrInvalidTokenAudience)
+}
+
+func TestTokenServiceValidatesTimesWithLeeway(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	strict := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+	lenient := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Leeway: time.Minute})
+	// The claims are checked against a fixed time, so that a slow run cannot
+	// move a token across a boundary.
+	now := time.Unix(1_800_000_000, 0)
+	unix := now.Unix()
+
+	tests := []struct {
+		name    string
+		payload tokenPayload
+		err     error
+		// lenientErr is what the service with a leeway returns.
+		lenientErr error
+	}{
+		{
+			name:    "expired",
+			payload: tokenPayload{Iat: unix - 3600, Exp: unix - 10},
+			err:     ErrTokenExpired,
+		},
+		{
+			name:       "expired beyond the leeway",
+			payload:    tokenPayload{Iat: unix - 3600, Exp: unix - 60},
+			err:        ErrTokenExpired,
+			lenientErr: ErrTokenExpired,
+		},
+		{
+			name:    "not yet valid",
+			payload: tokenPayload{Iat: unix, Nbf: unix + 10, Exp: unix + 3600},
+			err:     ErrTokenNotYetValid,
+		},
+		{
+			name:       "not yet valid beyond the leeway",
+			payload:    tokenPayload{Iat: unix, Nbf: unix + 61, Exp: unix + 3600},
+			err:        ErrTokenNotYetValid,
+			lenientErr: ErrTokenNotYetValid,
+		},
+		{
+			name:    "issued in the future",
+			payload: tokenPayload{Iat: unix + 10, Exp: unix + 3600},
+			err:     ErrTokenIssuedInFuture,
+		},
+		{
+			name:       "issued beyond the leeway",
+			payload:    tokenPayload{Iat: unix + 61, Exp: unix + 3600},
+			err:        ErrTokenIssuedInFuture,
+			lenientErr: ErrTokenIssuedInFuture,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.payload.Sub = uuid.NewString()
+
+			require.ErrorIs(t, strict.validate(tt.payload, now), tt.err)
+			if tt.lenientErr != nil {
+				require.ErrorIs(t, lenient.validate(tt.payload, now), tt.lenientErr)
+			} else {
+				require.NoError(t, lenient.validate(tt.payload, now))
+			}
+		})
+	}
+}
+
+func TestTokenServiceParseAppliesLeeway(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	strict := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+	lenient := NewKeyringTokenService(keyring, time.Hour, TokenValidation{Leeway: time.Hour})
+	// The token expired a minute ago; the leeway of an hour keeps it valid
+	// however slowly the test runs.
+	now := time.Now()
+	token := signTestPayload(t, keyring, tokenPayload{
+		Sub: uuid.NewString(),
+		Iat: now.Add(-time.Hour).Unix(),
+		Exp: now.Add(-time.Minute).Unix(),
+	})
+
+	_, err := strict.Parse(token)
+	require.ErrorIs(t, err, ErrTokenExpired)
+	_, err = lenient.Parse(token)
+	require.NoError(t, err)
+}
+
+func TestTokenServiceRejectsTokenWithoutIssuedAt(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	token := signTestPayload(t, keyring, tokenPayload{Sub: uuid.NewString(), Exp: time.Now().Add(time.Hour).Unix()})
+
+	_, err := NewKeyringTokenService(keyring, time.Hour, TokenValidation{}).Parse(token)
+
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenServiceGrantsAccessTokensEveryScope(t *testing.T) {
+	t.Parallel()
+
+	svc := NewTokenService("test-secret", time.Minute)
+	token, err := svc.Issue(uuid.New())
+	require.NoError(t, err)
+
+	claims, err := svc.ParseClaims(token)
+
+	require.NoError(t, err)
+	require.Equal(t, domain.AllScopes, claims.Scopes)
+}
+
+func TestTokenServiceReadsScopeClaim(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t)
+	now := time.Now()
+	payload := tokenPayload{Sub: uuid.NewString(), Iat: now.Unix(), Exp: now.Add(time.Hour).Unix()}
+	svc := NewKeyringTokenService(keyring, time.Hour, TokenValidation{})
+
+	payload.Scope = "tasks:read analytics:read"
+	claims, err := svc.ParseClaims(signTestPayload(t, keyring, payload))
+	require.NoError(t, err)
+	require.Equal(t, []domain.Scope{domain.ScopeTasksRead, domain.ScopeAnalyticsRead}, claims.Scopes)
+
+	payload.Scope = ""
+	claims, err = svc.ParseClaims(signTestPayload(t, keyring, payload))
+	require.NoError(t, err)
+	require.Empty(t, claims.Scopes)
+}
+
+func newTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+
+	key, err := NewHMACKey(DefaultKeyID, "test-secret")
+	require.NoError(t, err)
+	keyring, err := NewKeyring(DefaultKeyID, key)
+	require.NoError(t, err)
+	return keyring
+}
+
+func signTestPayload(t *testing.T, keyring *Keyring, payload tokenPayload) string {
+	t.Helper()
+
+	header, err := json.Marshal(tokenHeader{Alg: keyring.active.algorithm, Typ: "JWT", Kid: keyring.active.id})
+	require.NoError(t, err)
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	signature, err := keyring.active.sign(signingInput)
+	require.NoError(t, err)
+	return signingInput + "." + signature
+}
+
+func decodeTestTokenPart(t *testing.T, part string, value any) {
+	t.Helper()
+
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, value))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"taskflow/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +19,7 @@ type UserRepository interface {
 	Get(ctx context.Context, id uuid.UUID) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	Ensure(ctx context.Context, user domain.User) error
+	VerifyEmail(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
 }
 
 type UserService struct {
@@ -31,21 +33,36 @@ func NewUserService(repository UserRepository) *UserService {
 }
 
 func (s *UserService) CreateUser(ctx context.Context, email, password string) (domain.User, error) {
-	if password == "" {
-		return domain.User{}, domain.ErrEmptyPassword
+	return s.createUser(ctx, email, password, nil)
+}
+
+// createUser stores a new user whose email counts as verified from verifiedAt
+// on, or is unverified when verifiedAt is nil.
+func (s *UserService) createUser(ctx context.Context, email, password string, verifiedAt *time.Time) (domain.User, error) {
+	user, err := newUser(email, password, verifiedAt)
+	if err != nil {
+		return domain.User{}, err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return s.UserRepository.Create(ctx, user)
+}
+
+// newUser builds a user like createUser for callers that store it themselves.
+func newUser(email, password string, verifiedAt *time.Time) (domain.User, error) {
+	hash, err := hashPassword(password)
 	if err != nil {
 		return domain.User{}, err
 	}
 
-	user, err := domain.NewUser(email, string(hash))
+	user, err := domain.NewUser(email, hash)
 	if err != nil {
 		return domain.User{}, err
 	}
+	if verifiedAt != nil {
+		user.VerifyEmail(*verifiedAt)
+	}
 
-	return s.UserRepository.Create(ctx, user)
+	return user, nil
 }
 
 func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (domain.User, error) {
@@ -66,6 +83,19 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (domain.
 	return user, nil
 }
 
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", domain.ErrEmptyPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
 func (s *UserService) EnsureDevUser(ctx context.Context) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte("dev-password"), bcrypt.DefaultCost)
 	if err != nil {
@@ -76,6 +106,7 @@ func (s *UserService) EnsureDevUser(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	user.VerifyEmail(time.Now())
 
 	return s.UserRepository.Ensure(ctx, user)
 }
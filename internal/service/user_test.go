@@ -26,6 +26,35 @@ func TestUserServiceCreateUserRejectsEmptyPassword(t *testing.T) {
 	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
+func TestUserServiceCreateUserRejectsInvalidEmail(t *testing.T) {
+	t.Parallel()
+
+	for _, email := range []string{
+		"",
+		"user",
+		"user@",
+		"@example.com",
+		"user@localhost",
+		"user@example.",
+		"two@@example.com",
+		"User <user@example.com>",
+		"user@example.com, other@example.com",
+		"us er@example.com",
+	} {
+		t.Run(email, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewUserRepository(t)
+			svc := NewUserService(repo)
+
+			_, err := svc.CreateUser(context.Background(), email, "secret")
+
+			require.ErrorIs(t, err, domain.ErrInvalidUserEmail)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestUserServiceCreateUserHashesPassword(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"taskflow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type WorkflowRepository interface {
+	// Get returns the user's workflow, or the default one when the user has
+	// not defined any.
+	Get(ctx context.Context, userID uuid.UUID) (domain.Workflow, error)
+	// Save and Delete replace the user's workflow with the given or the
+	// default one. In the same transaction they check, with
+	// Workflow.CheckReplaces, that the statuses the user's tasks are in are
+	// kept, and hold off task writes until they are done.
+	Save(ctx context.Context, workflow domain.Workflow) (domain.Workflow, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type WorkflowService struct {
+	WorkflowRepository WorkflowRepository
+}
+
+func NewWorkflowService(repository WorkflowRepository) *WorkflowService {
+	return &WorkflowService{
+		WorkflowRepository: repository,
+	}
+}
+
+func (s *WorkflowService) GetWorkflow(ctx context.Context, userID uuid.UUID) (domain.Workflow, error) {
+	return s.WorkflowRepository.Get(ctx, userID)
+}
+
+// ReplaceWorkflow stores a workflow of the user's own. Statuses that tasks are
+// in have to be kept with their category.
+func (s *WorkflowService) ReplaceWorkflow(
+	ctx context.Context,
+	userID uuid.UUID,
+	statuses []domain.WorkflowStatus,
+	transitions []domain.Transition,
+) (domain.Workflow, error) {
+	workflow, err := domain.NewWorkflow(userID, statuses, transitions)
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	return s.WorkflowRepository.Save(ctx, workflow)
+}
+
+// ResetWorkflow drops the user's workflow so the default one applies again.
+// As with ReplaceWorkflow, the statuses tasks are in have to be kept.
+func (s *WorkflowService) ResetWorkflow(ctx context.Context, userID uuid.UUID) (domain.Workflow, error) {
+	if err := s.WorkflowRepository.Delete(ctx, userID); err != nil {
+		return domain.Workflow{}, err
+	}
+
+	return domain.DefaultWorkflow(), nil
+}
+
+// workflow returns the workflow the user's tasks move through.
+func (s *TaskService) workflow(ctx context.Context, userID uuid.UUID) (domain.Workflow, error) {
+	if s.WorkflowRepository == nil {
+		return domain.DefaultWorkflow(), nil
+	}
+
+	return s.WorkflowRepository.Get(ctx, userID)
+}
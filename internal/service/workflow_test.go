@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskflow/internal/domain"
+	"taskflow/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// reviewWorkflow adds a review column between in progress and done and lets
+// done tasks be reopened.
+func reviewWorkflow(t *testing.T, userID uuid.UUID) domain.Workflow {
+	t.Helper()
+
+	workflow, err := domain.NewWorkflow(userID,
+		[]domain.WorkflowStatus{
+			{Key: "backlog", Name: "Backlog", Category: domain.CategoryTodo},
+			{Key: "in_progress", Name: "In progress", Category: domain.CategoryInProgress},
+			{Key: "review", Name: "In review", Category: domain.CategoryInProgress},
+			{Key: "done", Name: "Done", Category: domain.CategoryDone},
+		},
+		[]domain.Transition{
+			{From: "backlog", To: "in_progress"},
+			{From: "in_progress", To: "review"},
+			{From: "review", To: "in_progress"},
+			{From: "review", To: "done"},
+			{From: "done", To: "backlog"},
+		},
+	)
+	require.NoError(t, err)
+
+	return workflow
+}
+
+func TestTaskServiceCreateTaskStartsInInitialWorkflowStatus(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	workflows := mocks.NewWorkflowRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, workflows, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	workflows.
+		On("Get", ctx, userID).
+		Return(reviewWorkflow(t, userID), nil).
+		Once()
+	repo.
+		On("Create", ctx, mock.MatchedBy(func(task domain.Task) bool {
+			return task.Status == "backlog" && task.Category == domain.CategoryTodo
+		})).
+		Return(domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: "backlog", Category: domain.CategoryTodo}, nil).
+		Once()
+
+	_, err := svc.CreateTask(ctx, userID, CreateTaskInput{Title: "Task"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskServiceChangeStatusFollowsCustomWorkflow(t *testing.T) {
+	t.Parallel()
+
+	completedAt := mockTime()
+
+	tests := []struct {
+		name      string
+		task      domain.Task
+		to        domain.Status
+		category  domain.StatusCategory
+		completed bool
+		err       error
+	}{
+		{
+			name:     "into review",
+			task:     domain.Task{Status: "in_progress", Category: domain.CategoryInProgress},
+			to:       "review",
+			category: domain.CategoryInProgress,
+		},
+		{
+			name:      "review to done",
+			task:      domain.Task{Status: "review", Category: domain.CategoryInProgress},
+			to:        "done",
+			category:  domain.CategoryDone,
+			completed: true,
+		},
+		{
+			name:     "reopen",
+			task:     domain.Task{Status: "done", Category: domain.CategoryDone, CompletedAt: &completedAt},
+			to:       "backlog",
+			category: domain.CategoryTodo,
+		},
+		{
+			name: "skipping review",
+			task: domain.Task{Status: "in_progress", Category: domain.CategoryInProgress},
+			to:   "done",
+			err:  domain.ErrInvalidTransition,
+		},
+		{
+			name: "unknown status",
+			task: domain.Task{Status: "in_progress", Category: domain.CategoryInProgress},
+			to:   "pending",
+			err:  domain.ErrInvalidStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewTaskRepository(t)
+			workflows := mocks.NewWorkflowRepository(t)
+			svc := NewTaskService(repo, nil, nil, nil, workflows, nil, nil, nil)
+			ctx := context.Background()
+			userID := uuid.New()
+
+			task := tt.task
+			task.ID = uuid.New()
+			task.UserID = userID
+			task.Title = "Task"
+
+			repo.
+				On("Get", ctx, task.ID).
+				Return(task, nil).
+				Once()
+			workflows.
+				On("Get", ctx, userID).
+				Return(reviewWorkflow(t, userID), nil).
+				Once()
+
+			if tt.err == nil {
+				repo.
+					On("Update", ctx, mock.MatchedBy(func(updated domain.Task) bool {
+						return updated.Status == tt.to &&
+							updated.Category == tt.category &&
+							(updated.CompletedAt != nil) == tt.completed
+					})).
+					Return(task, nil).
+					Once()
+				repo.
+					On("TouchDependents", ctx, []uuid.UUID{task.ID}).
+					Return(map[uuid.UUID]int64{}, nil).
+					Once()
+			}
+
+			_, err := svc.ChangeStatus(ctx, userID, task.ID, tt.to, nil)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskServiceChangeStatusRejectsCustomInProgressStatusWhileBlocked(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	workflows := mocks.NewWorkflowRepository(t)
+	svc := NewTaskService(repo, nil, nil, nil, workflows, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	repo.
+		On("Get", ctx, taskID).
+		Return(domain.Task{
+			ID:        taskID,
+			UserID:    userID,
+			Title:     "Task",
+			Status:    "in_progress",
+			Category:  domain.CategoryInProgress,
+			BlockedBy: []domain.Blocker{{ID: uuid.New(), Status: "review", Category: domain.CategoryInProgress}},
+		}, nil).
+		Once()
+	workflows.
+		On("Get", ctx, userID).
+		Return(reviewWorkflow(t, userID), nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, taskID, "review", nil)
+
+	require.ErrorIs(t, err, domain.ErrBlocked)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestWorkflowServiceReplaceWorkflowSavesValidWorkflow(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewWorkflowRepository(t)
+	svc := NewWorkflowService(repo)
+	ctx := context.Background()
+	userID := uuid.New()
+	want := reviewWorkflow(t, userID)
+
+	repo.
+		On("Save", ctx, want).
+		Return(want, nil).
+		Once()
+
+	workflow, err := svc.ReplaceWorkflow(ctx, userID, want.Statuses, want.Transitions)
+
+	require.NoError(t, err)
+	require.Equal(t, want, workflow)
+	repo.AssertExpectations(t)
+}
+
+func TestWorkflowServiceReplaceWorkflowKeepsStatusesInUse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		inUse map[domain.Status]domain.StatusCategory
+	}{
+		{name: "removed status", inUse: map[domain.Status]domain.StatusCategory{"pending": domain.CategoryTodo}},
+		{name: "changed category", inUse: map[domain.Status]domain.StatusCategory{"review": domain.CategoryDone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewWorkflowRepository(t)
+			svc := NewWorkflowService(repo)
+			ctx := context.Background()
+			userID := uuid.New()
+			workflow := reviewWorkflow(t, userID)
+
+			// The repository checks the statuses in use in the transaction
+			// that saves the workflow.
+			repo.
+				On("Save", ctx, workflow).
+				Return(domain.Workflow{}, workflow.CheckReplaces(tt.inUse)).
+				Once()
+
+			_, err := svc.ReplaceWorkflow(ctx, userID, workflow.Statuses, workflow.Transitions)
+
+			require.ErrorIs(t, err, domain.ErrStatusInUse)
+		})
+	}
+}
+
+func TestWorkflowServiceReplaceWorkflowRejectsInvalidWorkflow(t *testing.T) {
+	t.Parallel()
+
+	todo := domain.WorkflowStatus{Key: "todo", Name: "To do", Category: domain.CategoryTodo}
+	done := domain.WorkflowStatus{Key: "done", Name: "Done", Category: domain.CategoryDone}
+
+	tests := []struct {
+		name        string
+		statuses    []domain.WorkflowStatus
+		transitions []domain.Transition
+	}{
+		{name: "no statuses"},
+		{name: "no done status", statuses: []domain.WorkflowStatus{todo}},
+		{name: "first status not todo", statuses: []domain.WorkflowStatus{done, todo}},
+		{name: "duplicate key", statuses: []domain.WorkflowStatus{todo, done, todo}},
+		{name: "invalid key", statuses: []domain.WorkflowStatus{todo, {Key: "In Review", Name: "Review", Category: domain.CategoryInProgress}, done}},
+		{name: "unknown category", statuses: []domain.WorkflowStatus{todo, {Key: "review", Name: "Review", Category: "waiting"}, done}},
+		{name: "missing name", statuses: []domain.WorkflowStatus{todo, {Key: "review", Category: domain.CategoryInProgress}, done}},
+		{
+			name:        "transition to unknown status",
+			statuses:    []domain.WorkflowStatus{todo, done},
+			transitions: []domain.Transition{{From: "todo", To: "review"}},
+		},
+		{
+			name:        "self transition",
+			statuses:    []domain.WorkflowStatus{todo, done},
+			transitions: []domain.Transition{{From: "todo", To: "todo"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := mocks.NewWorkflowRepository(t)
+			svc := NewWorkflowService(repo)
+
+			_, err := svc.ReplaceWorkflow(context.Background(), uuid.New(), tt.statuses, tt.transitions)
+
+			require.ErrorIs(t, err, domain.ErrInvalidWorkflow)
+			repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWorkflowServiceResetWorkflowRequiresDefaultStatuses(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewWorkflowRepository(t)
+	svc := NewWorkflowService(repo)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	repo.
+		On("Delete", ctx, userID).
+		Return(domain.DefaultWorkflow().CheckReplaces(map[domain.Status]domain.StatusCategory{
+			"review": domain.CategoryInProgress,
+		})).
+		Once()
+
+	_, err := svc.ResetWorkflow(ctx, userID)
+
+	require.ErrorIs(t, err, domain.ErrStatusInUse)
+}
+
+func TestTaskServiceChangeStatusOutOfDonePublishesReopened(t *testing.T) {
+	t.Parallel()
+
+	repo := mocks.NewTaskRepository(t)
+	workflows := mocks.NewWorkflowRepository(t)
+	analytics := &recordingPublisher{}
+	svc := NewTaskService(repo, nil, nil, nil, workflows, nil, analytics, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	completedAt := mockTime()
+	task := domain.Task{ID: uuid.New(), UserID: userID, Title: "Task", Status: "done", Category: domain.CategoryDone, CompletedAt: &completedAt}
+	reopened := task
+	reopened.Status = "backlog"
+	reopened.Category = domain.CategoryTodo
+	reopened.CompletedAt = nil
+
+	repo.
+		On("Get", ctx, task.ID).
+		Return(task, nil).
+		Once()
+	workflows.
+		On("Get", ctx, userID).
+		Return(reviewWorkflow(t, userID), nil).
+		Once()
+	repo.
+		On("Update", ctx, mock.Anything).
+		Return(reopened, nil).
+		Once()
+	repo.
+		On("TouchDependents", ctx, []uuid.UUID{task.ID}).
+		Return(map[uuid.UUID]int64{}, nil).
+		Once()
+
+	_, err := svc.ChangeStatus(ctx, userID, task.ID, "backlog", nil)
+
+	require.NoError(t, err)
+	require.Len(t, analytics.events, 1)
+	require.Equal(t, TaskEventReopened, analytics.events[0].Type)
+}
@@ -0,0 +1,16 @@
+DO $$
+BEGIN
+    CREATE TYPE task_priority AS ENUM (
+        'low',
+        'medium',
+        'high',
+        'urgent'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority task_priority NOT NULL DEFAULT 'medium';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id_due_at ON tasks(user_id, due_at);
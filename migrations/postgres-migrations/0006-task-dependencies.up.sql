@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS task_dependencies(
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocker_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker_id ON task_dependencies(blocker_id);
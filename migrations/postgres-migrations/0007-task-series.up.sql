@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS task_series(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rrule TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    priority task_priority NOT NULL DEFAULT 'medium',
+    starts_at TIMESTAMPTZ NOT NULL,
+    last_due_at TIMESTAMPTZ NOT NULL,
+    stopped_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES task_series(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS task_checklist_items(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT false,
+    position INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_task_checklist_items_task_id_position ON task_checklist_items(task_id, position);
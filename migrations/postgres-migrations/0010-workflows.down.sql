@@ -0,0 +1,30 @@
+DROP TABLE IF EXISTS workflow_transitions;
+DROP TABLE IF EXISTS workflow_statuses;
+DROP TABLE IF EXISTS workflows;
+DROP INDEX IF EXISTS idx_tasks_user_id_category;
+
+DO $$
+BEGIN
+    CREATE TYPE task_status AS ENUM (
+        'pending',
+        'in_progress',
+        'done',
+        'canceled'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Custom statuses collapse into the built-in status of their category.
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE task_status USING (
+    CASE category
+        WHEN 'todo' THEN 'pending'
+        ELSE category::text
+    END
+)::task_status;
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 'pending';
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS category;
+DROP TYPE IF EXISTS status_category;
@@ -0,0 +1,79 @@
+DO $$
+BEGIN
+    CREATE TYPE status_category AS ENUM (
+        'todo',
+        'in_progress',
+        'done',
+        'canceled'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Statuses become workflow keys; what they mean moves to the category column.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS category status_category;
+UPDATE tasks
+SET category = CASE status::text
+    WHEN 'pending' THEN 'todo'
+    WHEN 'in_progress' THEN 'in_progress'
+    WHEN 'done' THEN 'done'
+    ELSE 'canceled'
+END::status_category
+WHERE category IS NULL;
+ALTER TABLE tasks ALTER COLUMN category SET DEFAULT 'todo';
+ALTER TABLE tasks ALTER COLUMN category SET NOT NULL;
+
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE TEXT USING status::text;
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE IF EXISTS task_status;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id_category ON tasks(user_id, category);
+
+CREATE TABLE IF NOT EXISTS workflows(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- NULL marks the default workflow.
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflows_default ON workflows((user_id IS NULL)) WHERE user_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS workflow_statuses(
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    name TEXT NOT NULL,
+    category status_category NOT NULL,
+    position INT NOT NULL,
+    PRIMARY KEY (workflow_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_transitions(
+    workflow_id UUID NOT NULL,
+    from_key TEXT NOT NULL,
+    to_key TEXT NOT NULL,
+    PRIMARY KEY (workflow_id, from_key, to_key),
+    FOREIGN KEY (workflow_id, from_key) REFERENCES workflow_statuses(workflow_id, key) ON DELETE CASCADE,
+    FOREIGN KEY (workflow_id, to_key) REFERENCES workflow_statuses(workflow_id, key) ON DELETE CASCADE
+);
+
+INSERT INTO workflows (id, user_id)
+VALUES ('00000000-0000-0000-0000-000000000001', NULL)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO workflow_statuses (workflow_id, key, name, category, position)
+VALUES
+    ('00000000-0000-0000-0000-000000000001', 'pending', 'Pending', 'todo', 0),
+    ('00000000-0000-0000-0000-000000000001', 'in_progress', 'In progress', 'in_progress', 1),
+    ('00000000-0000-0000-0000-000000000001', 'done', 'Done', 'done', 2),
+    ('00000000-0000-0000-0000-000000000001', 'canceled', 'Canceled', 'canceled', 3)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO workflow_transitions (workflow_id, from_key, to_key)
+VALUES
+    ('00000000-0000-0000-0000-000000000001', 'pending', 'in_progress'),
+    ('00000000-0000-0000-0000-000000000001', 'pending', 'done'),
+    ('00000000-0000-0000-0000-000000000001', 'pending', 'canceled'),
+    ('00000000-0000-0000-0000-000000000001', 'in_progress', 'done'),
+    ('00000000-0000-0000-0000-000000000001', 'in_progress', 'canceled')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS projects(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    personal BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_personal ON projects(owner_id) WHERE personal;
+
+CREATE TABLE IF NOT EXISTS project_members(
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Every existing user gets a personal project holding the tasks they own.
+INSERT INTO projects (owner_id, name, personal)
+SELECT id, 'Personal', true FROM users
+ON CONFLICT (owner_id) WHERE personal DO NOTHING;
+
+INSERT INTO project_members (project_id, user_id, joined_at)
+SELECT id, owner_id, created_at FROM projects WHERE personal
+ON CONFLICT DO NOTHING;
+
+-- user_id stays on tasks as the creator.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id) ON DELETE CASCADE;
+UPDATE tasks t
+SET project_id = p.id
+FROM projects p
+WHERE t.project_id IS NULL AND p.owner_id = t.user_id AND p.personal;
+ALTER TABLE tasks ALTER COLUMN project_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id_created_at ON tasks(project_id, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE project_members DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS project_role;
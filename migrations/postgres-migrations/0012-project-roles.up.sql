@@ -0,0 +1,20 @@
+DO $$
+BEGIN
+    CREATE TYPE project_role AS ENUM (
+        'owner',
+        'admin',
+        'editor',
+        'viewer'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Members added before roles existed could edit every task of the project.
+ALTER TABLE project_members ADD COLUMN IF NOT EXISTS role project_role NOT NULL DEFAULT 'editor';
+
+UPDATE project_members m
+SET role = 'owner'
+FROM projects p
+WHERE p.id = m.project_id AND p.owner_id = m.user_id AND m.role <> 'owner';
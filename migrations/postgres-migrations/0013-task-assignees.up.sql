@@ -0,0 +1,12 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id);
+
+-- Breakdown of task_analytics by the assignee of the tasks involved.
+CREATE TABLE IF NOT EXISTS task_assignee_analytics(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assignee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tasks_assigned BIGINT NOT NULL DEFAULT 0,
+    tasks_completed BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, assignee_id)
+);
@@ -0,0 +1,26 @@
+DO $$
+BEGIN
+    CREATE TYPE invitation_status AS ENUM (
+        'pending',
+        'accepted',
+        'declined'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
+
+-- Invitation tokens are signed and carry the invitation id; the row keeps the
+-- answer so that every token is used once.
+CREATE TABLE IF NOT EXISTS project_invitations(
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role project_role NOT NULL,
+    status invitation_status NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    answered_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_project_invitations_project_id ON project_invitations(project_id);
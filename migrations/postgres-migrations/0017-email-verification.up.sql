@@ -0,0 +1,13 @@
+-- Accounts that existed before email verification are trusted as they are.
+-- The backfill runs only together with adding the column, so that later runs
+-- of this migration do not verify newer accounts.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+        UPDATE users SET email_verified_at = created_at;
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+-- The user who moved a task to done, so that analytics can take the completion
+-- back from them when someone else reopens it. Unknown for tasks completed
+-- before it was recorded.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_by UUID REFERENCES users(id) ON DELETE SET NULL;